@@ -0,0 +1,72 @@
+package analysis
+
+import "strconv"
+
+// callSpan is the part of a span needed to follow failures between services
+type callSpan struct {
+	id, parent string
+	service    string
+	start      int64 // Unix nanoseconds
+	failed     bool
+}
+
+// recordCalls adds one trace's spans to the result: each service's earliest
+// failed span, and each failed call, where a failed span's parent belongs to
+// another service and failed too
+func (r *TraceResult) recordCalls(spans []callSpan) {
+	byID := make(map[string]callSpan, len(spans))
+	for _, s := range spans {
+		if s.id != "" {
+			byID[s.id] = s
+		}
+	}
+
+	seen := make(map[[2]string]bool)
+	for _, s := range spans {
+		if !s.failed || s.service == "" {
+			continue
+		}
+		if first, ok := r.FirstFailure[s.service]; s.start > 0 && (!ok || s.start < first) {
+			if r.FirstFailure == nil {
+				r.FirstFailure = make(map[string]int64)
+			}
+			r.FirstFailure[s.service] = s.start
+		}
+
+		parent, ok := byID[s.parent]
+		if !ok || !parent.failed || parent.service == "" || parent.service == s.service {
+			continue
+		}
+		call := [2]string{parent.service, s.service}
+		if seen[call] {
+			continue
+		}
+		seen[call] = true
+		if r.FailedCalls == nil {
+			r.FailedCalls = make(map[string]map[string]int)
+		}
+		if r.FailedCalls[call[0]] == nil {
+			r.FailedCalls[call[0]] = make(map[string]int)
+		}
+		r.FailedCalls[call[0]][call[1]]++
+	}
+}
+
+// tempoCallSpans reads the spans of a Tempo search result. Search results
+// only carry parentSpanID when Tempo is asked to return it.
+func tempoCallSpans(trace map[string]any) []callSpan {
+	var spans []callSpan
+	for _, span := range traceSpans(trace) {
+		id, _ := span["spanID"].(string)
+		parent, _ := span["parentSpanID"].(string)
+		start, _ := strconv.ParseInt(scalarString(span["startTimeUnixNano"]), 10, 64)
+		spans = append(spans, callSpan{
+			id:      id,
+			parent:  parent,
+			service: spanAttribute(span, "service.name"),
+			start:   start,
+			failed:  spanAttribute(span, "status") == "error",
+		})
+	}
+	return spans
+}
@@ -0,0 +1,65 @@
+package analysis
+
+import "sort"
+
+// Exemplar is a sample Prometheus linked to the trace that produced it
+type Exemplar struct {
+	TraceID string
+	Time    float64 // Unix seconds
+	Value   float64
+}
+
+// exemplarTraceLabels are the label names instrumentation libraries use for
+// the trace ID, in order of preference
+var exemplarTraceLabels = []string{"trace_id", "traceID", "traceId"}
+
+// AnalyzeExemplars extracts the trace-linked exemplars from a
+// /api/v1/query_exemplars response, oldest first. Exemplars without a trace
+// ID can't be deep-linked and are skipped.
+func AnalyzeExemplars(raw string) ([]Exemplar, error) {
+	parsed, err := parseResponse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if status, _ := parsed["status"].(string); status == "error" {
+		errorType, _ := parsed["errorType"].(string)
+		message, _ := parsed["error"].(string)
+		return nil, &APIError{Type: errorType, Message: message}
+	}
+	data, ok := parsed["data"].([]any)
+	if !ok {
+		if parsed["data"] == nil {
+			return nil, nil
+		}
+		return nil, invalid("malformed data")
+	}
+
+	var exemplars []Exemplar
+	for _, d := range data {
+		series, _ := d.(map[string]any)
+		list, _ := series["exemplars"].([]any)
+		for _, e := range list {
+			exemplar, ok := e.(map[string]any)
+			if !ok {
+				continue
+			}
+			labels, _ := exemplar["labels"].(map[string]any)
+			traceID := ""
+			for _, name := range exemplarTraceLabels {
+				if traceID, _ = labels[name].(string); traceID != "" {
+					break
+				}
+			}
+			if traceID == "" {
+				continue
+			}
+			exemplars = append(exemplars, Exemplar{
+				TraceID: traceID,
+				Time:    scalarFloat(exemplar["timestamp"]),
+				Value:   scalarFloat(exemplar["value"]),
+			})
+		}
+	}
+	sort.SliceStable(exemplars, func(i, j int) bool { return exemplars[i].Time < exemplars[j].Time })
+	return exemplars, nil
+}
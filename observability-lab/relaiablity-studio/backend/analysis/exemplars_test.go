@@ -0,0 +1,34 @@
+package analysis
+
+import "testing"
+
+func TestAnalyzeExemplars(t *testing.T) {
+	raw := `{"status":"success","data":[
+		{"seriesLabels":{"__name__":"http_request_duration_seconds_bucket","service":"checkout","le":"2.5"},
+		 "exemplars":[
+			{"labels":{"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"},"value":"2.1","timestamp":1705284660.5},
+			{"labels":{"span_id":"00f067aa0ba902b7"},"value":"1.9","timestamp":1705284650}]},
+		{"seriesLabels":{"__name__":"http_request_duration_seconds_bucket","service":"checkout","le":"0.5"},
+		 "exemplars":[{"labels":{"traceID":"a3ce929d0e0e4736"},"value":"0.31","timestamp":1705284600}]}]}`
+
+	exemplars, err := AnalyzeExemplars(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(exemplars) != 2 {
+		t.Fatalf("Expected 2 exemplars with trace IDs, got %+v", exemplars)
+	}
+	if exemplars[0].TraceID != "a3ce929d0e0e4736" || exemplars[0].Time != 1705284600 {
+		t.Errorf("Expected the traceID label read and exemplars sorted oldest first, got %+v", exemplars[0])
+	}
+	if got := exemplars[1]; got.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || got.Value != 2.1 || got.Time != 1705284660.5 {
+		t.Errorf("Expected trace 4bf92f3577b34da6a3ce929d0e0e4736 at 1705284660.5 with value 2.1, got %+v", got)
+	}
+
+	if exemplars, err = AnalyzeExemplars(`{"status":"success","data":[]}`); err != nil || len(exemplars) != 0 {
+		t.Errorf("Expected no exemplars and no error, got %+v, %v", exemplars, err)
+	}
+	if _, err := AnalyzeExemplars(`{"status":"error","errorType":"bad_data","error":"parse error"}`); err == nil {
+		t.Error("Expected an error for a rejected query")
+	}
+}
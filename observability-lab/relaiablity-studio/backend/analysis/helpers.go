@@ -1,6 +1,11 @@
 package analysis
 
-import "strconv"
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
 
 func parseFloat(s string) float64 {
 	f, err := strconv.ParseFloat(s, 64)
@@ -10,6 +15,37 @@ func parseFloat(s string) float64 {
 	return f
 }
 
+// Upstream versions differ in whether timestamps and sample values are JSON
+// strings or numbers (e.g. Loki entries as [string,string] or [number,string]),
+// so scalars are read in either form.
+
+// scalarString renders a string or number as a string. Numbers decoded as
+// json.Number keep every digit, e.g. of nanosecond timestamps.
+func scalarString(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	return ""
+}
+
+// scalarFloat reads a number or a numeric string
+func scalarFloat(v any) float64 {
+	switch x := v.(type) {
+	case float64:
+		return x
+	case json.Number:
+		return parseFloat(x.String())
+	case string:
+		return parseFloat(x)
+	}
+	return 0
+}
+
 func parseInt(s string) int {
 	i, err := strconv.Atoi(s)
 	if err != nil {
@@ -17,3 +53,38 @@ func parseInt(s string) int {
 	}
 	return i
 }
+
+// normalizeTime renders a nanosecond epoch string (as Loki reports) or an
+// RFC3339 timestamp as RFC3339 in UTC, keeping sub-second precision.
+// Anything else is returned unchanged.
+func normalizeTime(s string) string {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	if ns, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(0, ns).UTC().Format(time.RFC3339Nano)
+	}
+	return s
+}
+
+// DefaultMaxMessageLength caps stored log, trace and k8s messages
+const DefaultMaxMessageLength = 2000
+
+var maxMessageLength = DefaultMaxMessageLength
+
+// SetMaxMessageLength changes the message cap. n <= 0 restores the default.
+func SetMaxMessageLength(n int) {
+	if n <= 0 {
+		n = DefaultMaxMessageLength
+	}
+	maxMessageLength = n
+}
+
+// truncateMessage shortens msg to maxMessageLength runes with an ellipsis.
+// Only the length is capped; multi-line messages keep their line breaks.
+func truncateMessage(msg string) string {
+	if utf8.RuneCountInString(msg) <= maxMessageLength {
+		return msg
+	}
+	return string([]rune(msg)[:maxMessageLength]) + "…"
+}
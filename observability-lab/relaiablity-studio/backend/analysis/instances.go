@@ -0,0 +1,81 @@
+package analysis
+
+import "sort"
+
+// OutlierFactor is how many times the median instance error rate an instance
+// must reach to be an outlier
+const OutlierFactor = 3
+
+// OutlierMinErrorRate is the error rate (percent) below which no instance is
+// an outlier, however it compares to the rest
+const OutlierMinErrorRate = 1.0
+
+// AnalyzeInstanceErrors reads an error rate query broken down by label (e.g.
+// "instance" or "pod") and returns the outlier instances with their error
+// rates. Range results use each series' latest sample.
+func AnalyzeInstanceErrors(raw, label string) (map[string]float64, error) {
+	parsed, err := parseResponse(raw)
+	if err != nil {
+		return nil, err
+	}
+	_, results, err := resultData(parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[string]float64)
+	for _, r := range results {
+		series, ok := r.(map[string]any)
+		if !ok {
+			return nil, invalid("malformed series")
+		}
+		metric, _ := series["metric"].(map[string]any)
+		instance, _ := metric[label].(string)
+		if instance == "" {
+			continue
+		}
+		if sample, ok := series["value"].([]any); ok && len(sample) >= 2 {
+			rates[instance] = scalarFloat(sample[1])
+		} else if m := analyzeMatrix(series); !m.NoData {
+			rates[instance] = m.Latency
+		}
+	}
+	return Outliers(rates), nil
+}
+
+// Outliers returns the instances whose error rate is above
+// OutlierMinErrorRate and at least OutlierFactor times the median of the
+// other instances. A single instance has no peers to stand out from.
+func Outliers(rates map[string]float64) map[string]float64 {
+	if len(rates) < 2 {
+		return nil
+	}
+	var outliers map[string]float64
+	for instance, rate := range rates {
+		if rate <= OutlierMinErrorRate {
+			continue
+		}
+		others := make([]float64, 0, len(rates)-1)
+		for other, r := range rates {
+			if other != instance {
+				others = append(others, r)
+			}
+		}
+		if rate >= OutlierFactor*median(others) {
+			if outliers == nil {
+				outliers = make(map[string]float64)
+			}
+			outliers[instance] = rate
+		}
+	}
+	return outliers
+}
+
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
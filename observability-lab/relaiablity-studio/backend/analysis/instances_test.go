@@ -0,0 +1,47 @@
+package analysis
+
+import "testing"
+
+func TestAnalyzeInstanceErrorsOutlier(t *testing.T) {
+	raw := `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"instance":"10.0.0.1:8080"},"value":[1705302840,"0.4"]},
+		{"metric":{"instance":"10.0.0.2:8080"},"value":[1705302840,"38.5"]},
+		{"metric":{"instance":"10.0.0.3:8080"},"value":[1705302840,"0.6"]},
+		{"metric":{"instance":"10.0.0.4:8080"},"value":[1705302840,"0.5"]}
+	]}}`
+
+	outliers, err := AnalyzeInstanceErrors(raw, "instance")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(outliers) != 1 || outliers["10.0.0.2:8080"] != 38.5 {
+		t.Errorf("Expected only 10.0.0.2:8080 as an outlier, got %v", outliers)
+	}
+}
+
+func TestOutliers(t *testing.T) {
+	tests := []struct {
+		name  string
+		rates map[string]float64
+		want  []string
+	}{
+		{"single instance", map[string]float64{"a": 50}, nil},
+		{"evenly failing", map[string]float64{"a": 20, "b": 22, "c": 19}, nil},
+		{"below minimum", map[string]float64{"a": 0.9, "b": 0, "c": 0}, nil},
+		{"one of two", map[string]float64{"a": 12, "b": 0.2}, []string{"a"}},
+		{"two of five", map[string]float64{"a": 30, "b": 25, "c": 1, "d": 1, "e": 1}, []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		got := Outliers(tt.rates)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: Expected outliers %v, got %v", tt.name, tt.want, got)
+			continue
+		}
+		for _, instance := range tt.want {
+			if _, ok := got[instance]; !ok {
+				t.Errorf("%s: Expected %s as an outlier, got %v", tt.name, instance, got)
+			}
+		}
+	}
+}
@@ -1,42 +1,320 @@
 package analysis
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RolloutLookback is how far back a rollout still counts as "recent"
+const RolloutLookback = time.Hour
+
+// DefaultNotReadyThreshold is how long a running pod may fail its readiness
+// probes before it counts as degraded
+const DefaultNotReadyThreshold = 2 * time.Minute
+
+var notReadyThreshold = DefaultNotReadyThreshold
+
+// SetNotReadyThreshold sets how long a pod must be Running but not Ready to
+// count as degraded. Zero or less restores DefaultNotReadyThreshold.
+func SetNotReadyThreshold(d time.Duration) {
+	if d <= 0 {
+		d = DefaultNotReadyThreshold
+	}
+	notReadyThreshold = d
+}
+
+// Kinds of K8sEvent
+const (
+	K8sPodEvent      = "pod"
+	K8sNotReadyEvent = "not_ready"
+	K8sOOMKillEvent  = "oom_kill"
+	K8sRolloutEvent  = "rollout"
+)
 
 type K8sEvent struct {
 	Time    string
 	Message string
+	Kind    string // K8sPodEvent, K8sNotReadyEvent, K8sOOMKillEvent or K8sRolloutEvent
+	Cluster string // Empty unless multiple clusters are configured
+
+	Namespace string
+	Pod       string // Empty for rollouts
+	PodIP     string // Empty for rollouts and unscheduled pods
+}
+
+// Rollout is a recent Deployment change for the service
+type Rollout struct {
+	Deployment      string
+	Revision        string
+	StartedAt       time.Time
+	Generation      int
+	Replicas        int
+	UpdatedReplicas int
+	Cluster         string
+	Namespace       string
 }
 
 type K8sResult struct {
 	BadPods int
-	Events  []K8sEvent
+	// DegradedPods are Running but have not been Ready for the not-ready
+	// threshold, so they aren't serving traffic
+	DegradedPods int
+	Events       []K8sEvent
+	Rollouts     []Rollout
+}
+
+// AnalyzeK8s counts failed and degraded pods and finds recent rollouts of the service's
+// Deployments in a `kubectl get pods,deployments,replicasets -o json` list
+func AnalyzeK8s(service string, raw string) (K8sResult, error) {
+	return AnalyzeK8sCluster("", service, raw, time.Time{})
 }
 
-func AnalyzeK8s(raw string) K8sResult {
-	var parsed map[string]any
-	_ = json.Unmarshal([]byte(raw), &parsed)
+// AnalyzeK8sCluster is AnalyzeK8s for one of several clusters, tagging every
+// event and rollout with the cluster name. Rollouts are recent relative to
+// asOf, e.g. the end of a past window; zero means now.
+func AnalyzeK8sCluster(cluster, service, raw string, asOf time.Time) (K8sResult, error) {
+	parsed, err := parseResponse(raw)
+	if err != nil {
+		return K8sResult{}, err
+	}
 
-	items := parsed["items"].([]any)
+	items, ok := parsed["items"].([]any)
+	if !ok {
+		return K8sResult{}, invalid("missing items")
+	}
 
-	bad := 0
+	now := time.Now()
+	bad, degraded := 0, 0
 	var events []K8sEvent
+	var deployments, replicaSets []map[string]any
 
 	for _, i := range items {
-		pod := i.(map[string]any)
-		status := pod["status"].(map[string]any)
-		phase := status["phase"].(string)
+		item, _ := i.(map[string]any)
+		switch item["kind"] {
+		case "Deployment":
+			deployments = append(deployments, item)
+			continue
+		case "ReplicaSet":
+			replicaSets = append(replicaSets, item)
+			continue
+		}
 
-		if phase == "Failed" {
+		status, ok := item["status"].(map[string]any)
+		if !ok {
+			return K8sResult{}, invalid("pod without status")
+		}
+		phase, _ := status["phase"].(string)
+		meta, _ := item["metadata"].(map[string]any)
+		namespace := namespaceOf(meta)
+		pod, _ := meta["name"].(string)
+		podIP, _ := status["podIP"].(string)
+
+		// Phases other than these are mapped by SetStatusFallbacks
+		outcome := StatusOK
+		switch phase {
+		case "Failed":
+			outcome = StatusFailed
+		case "", "Pending", "Running", "Succeeded":
+		default:
+			outcome = fallbackStatus(phase)
+		}
+
+		message, kind := "Pod failed", K8sPodEvent
+		if phase != "Failed" {
+			message = fmt.Sprintf("Pod in phase %s", phase)
+		}
+		switch outcome {
+		case StatusFailed:
 			bad++
+		case StatusDegraded:
+			degraded++
+			kind = K8sNotReadyEvent
+		}
+		if outcome != StatusOK {
+			startTime, _ := status["startTime"].(string)
+			events = append(events, K8sEvent{
+				Time:      startTime,
+				Message:   truncateMessage(message),
+				Kind:      kind,
+				Cluster:   cluster,
+				Namespace: namespace,
+				Pod:       pod,
+				PodIP:     podIP,
+			})
+		}
+		for _, oom := range oomKills(status) {
 			events = append(events, K8sEvent{
-				Time:    status["startTime"].(string),
-				Message: "Pod failed",
+				Time:      oom.finishedAt,
+				Message:   truncateMessage(fmt.Sprintf("Container %s OOMKilled", oom.container)),
+				Kind:      K8sOOMKillEvent,
+				Cluster:   cluster,
+				Namespace: namespace,
+				Pod:       pod,
+				PodIP:     podIP,
+			})
+		}
+		if since, ok := notReadySince(status); phase == "Running" && ok && now.Sub(since) >= notReadyThreshold {
+			degraded++
+			events = append(events, K8sEvent{
+				Time:      since.UTC().Format(time.RFC3339),
+				Message:   "Pod running but not ready",
+				Kind:      K8sNotReadyEvent,
+				Cluster:   cluster,
+				Namespace: namespace,
+				Pod:       pod,
+				PodIP:     podIP,
 			})
 		}
 	}
 
+	if asOf.IsZero() {
+		asOf = now
+	}
+	rollouts := findRollouts(service, deployments, replicaSets, asOf)
+	for i, r := range rollouts {
+		rollouts[i].Cluster = cluster
+		events = append(events, K8sEvent{
+			Time: r.StartedAt.UTC().Format(time.RFC3339),
+			Message: truncateMessage(fmt.Sprintf("Rollout started: deployment %s revision %s (%d/%d replicas updated)",
+				r.Deployment, r.Revision, r.UpdatedReplicas, r.Replicas)),
+			Kind:      K8sRolloutEvent,
+			Cluster:   cluster,
+			Namespace: r.Namespace,
+		})
+	}
+
 	return K8sResult{
-		BadPods: bad,
-		Events:  events,
+		BadPods:      bad,
+		DegradedPods: degraded,
+		Events:       events,
+		Rollouts:     rollouts,
+	}, nil
+}
+
+type oomKill struct {
+	container  string
+	finishedAt string
+}
+
+// oomKills finds containers whose current or last run was OOMKilled
+func oomKills(status map[string]any) []oomKill {
+	var kills []oomKill
+	containers, _ := status["containerStatuses"].([]any)
+	for _, c := range containers {
+		container, _ := c.(map[string]any)
+		name, _ := container["name"].(string)
+		for _, key := range []string{"state", "lastState"} {
+			state, _ := container[key].(map[string]any)
+			terminated, _ := state["terminated"].(map[string]any)
+			if terminated["reason"] == "OOMKilled" {
+				finished, _ := terminated["finishedAt"].(string)
+				kills = append(kills, oomKill{container: name, finishedAt: finished})
+			}
+		}
+	}
+	return kills
+}
+
+// notReadySince reports when a pod's Ready condition last became False
+func notReadySince(status map[string]any) (time.Time, bool) {
+	conditions, _ := status["conditions"].([]any)
+	for _, c := range conditions {
+		condition, _ := c.(map[string]any)
+		if condition["type"] != "Ready" || condition["status"] != "False" {
+			continue
+		}
+		changed, _ := condition["lastTransitionTime"].(string)
+		since, err := time.Parse(time.RFC3339, changed)
+		return since, err == nil
 	}
+	return time.Time{}, false
+}
+
+// MergeK8s combines the results from several clusters, summing failed pods
+func MergeK8s(results ...K8sResult) K8sResult {
+	var merged K8sResult
+	for _, r := range results {
+		merged.BadPods += r.BadPods
+		merged.DegradedPods += r.DegradedPods
+		merged.Events = append(merged.Events, r.Events...)
+		merged.Rollouts = append(merged.Rollouts, r.Rollouts...)
+	}
+	return merged
+}
+
+// findRollouts matches the service's Deployments (by name or app label) to the
+// ReplicaSet for their current revision, whose creation marks the rollout start.
+// Rollouts more than RolloutLookback before asOf are left out.
+func findRollouts(service string, deployments, replicaSets []map[string]any, asOf time.Time) []Rollout {
+	var rollouts []Rollout
+	for _, d := range deployments {
+		meta, _ := d["metadata"].(map[string]any)
+		name, _ := meta["name"].(string)
+		if name != service && label(meta, "app") != service {
+			continue
+		}
+		revision := annotation(meta, "deployment.kubernetes.io/revision")
+
+		var started time.Time
+		for _, rs := range replicaSets {
+			rsMeta, _ := rs["metadata"].(map[string]any)
+			if ownerDeployment(rsMeta) != name || annotation(rsMeta, "deployment.kubernetes.io/revision") != revision {
+				continue
+			}
+			created, _ := rsMeta["creationTimestamp"].(string)
+			started, _ = time.Parse(time.RFC3339, created)
+		}
+		if started.IsZero() || asOf.Sub(started) > RolloutLookback {
+			continue
+		}
+
+		spec, _ := d["spec"].(map[string]any)
+		status, _ := d["status"].(map[string]any)
+		rollouts = append(rollouts, Rollout{
+			Deployment:      name,
+			Namespace:       namespaceOf(meta),
+			Revision:        revision,
+			StartedAt:       started,
+			Generation:      number(meta["generation"]),
+			Replicas:        number(spec["replicas"]),
+			UpdatedReplicas: number(status["updatedReplicas"]),
+		})
+	}
+	return rollouts
+}
+
+func namespaceOf(meta map[string]any) string {
+	v, _ := meta["namespace"].(string)
+	return v
+}
+
+func label(meta map[string]any, key string) string {
+	labels, _ := meta["labels"].(map[string]any)
+	v, _ := labels[key].(string)
+	return v
+}
+
+func annotation(meta map[string]any, key string) string {
+	annotations, _ := meta["annotations"].(map[string]any)
+	v, _ := annotations[key].(string)
+	return v
+}
+
+func ownerDeployment(meta map[string]any) string {
+	owners, _ := meta["ownerReferences"].([]any)
+	for _, o := range owners {
+		owner, _ := o.(map[string]any)
+		if owner["kind"] == "Deployment" {
+			name, _ := owner["name"].(string)
+			return name
+		}
+	}
+	return ""
+}
+
+func number(v any) int {
+	n, _ := v.(json.Number)
+	i, _ := n.Int64()
+	return int(i)
 }
@@ -0,0 +1,185 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeK8sRollout(t *testing.T) {
+	started := time.Now().Add(-5 * time.Minute).UTC().Truncate(time.Second)
+	old := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+
+	raw := fmt.Sprintf(`{"kind":"List","items":[
+		{"kind":"Pod","metadata":{"name":"checkout-7d9f8b6c5-x2k4p"},
+		 "status":{"phase":"Failed","startTime":"2024-01-15T02:13:40Z"}},
+		{"kind":"Deployment","metadata":{"name":"checkout","generation":7,
+		  "annotations":{"deployment.kubernetes.io/revision":"7"}},
+		 "spec":{"replicas":3},"status":{"updatedReplicas":2}},
+		{"kind":"ReplicaSet","metadata":{"name":"checkout-6c4b9f7d8","creationTimestamp":%q,
+		  "annotations":{"deployment.kubernetes.io/revision":"6"},
+		  "ownerReferences":[{"kind":"Deployment","name":"checkout"}]}},
+		{"kind":"ReplicaSet","metadata":{"name":"checkout-7d9f8b6c5","creationTimestamp":%q,
+		  "annotations":{"deployment.kubernetes.io/revision":"7"},
+		  "ownerReferences":[{"kind":"Deployment","name":"checkout"}]}},
+		{"kind":"Deployment","metadata":{"name":"payments","generation":3,
+		  "annotations":{"deployment.kubernetes.io/revision":"3"}},
+		 "spec":{"replicas":2},"status":{"updatedReplicas":2}},
+		{"kind":"ReplicaSet","metadata":{"name":"payments-5f6d7c8b9","creationTimestamp":%q,
+		  "annotations":{"deployment.kubernetes.io/revision":"3"},
+		  "ownerReferences":[{"kind":"Deployment","name":"payments"}]}}
+	]}`, old, started.Format(time.RFC3339), started.Format(time.RFC3339))
+
+	res, err := AnalyzeK8s("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if res.BadPods != 1 {
+		t.Errorf("Expected 1 failed pod, got %d", res.BadPods)
+	}
+	if len(res.Rollouts) != 1 {
+		t.Fatalf("Expected only checkout's rollout, got %+v", res.Rollouts)
+	}
+	r := res.Rollouts[0]
+	if r.Deployment != "checkout" || r.Revision != "7" || !r.StartedAt.Equal(started) || r.UpdatedReplicas != 2 || r.Replicas != 3 {
+		t.Errorf("Unexpected rollout %+v", r)
+	}
+
+	var rollout *K8sEvent
+	for i, e := range res.Events {
+		if e.Kind == K8sRolloutEvent {
+			rollout = &res.Events[i]
+		}
+	}
+	if rollout == nil {
+		t.Fatalf("Expected a rollout event in %+v", res.Events)
+	}
+	if rollout.Time != started.Format(time.RFC3339) || !strings.HasPrefix(rollout.Message, "Rollout started: deployment checkout revision 7") {
+		t.Errorf("Unexpected rollout event %+v", *rollout)
+	}
+}
+
+func TestAnalyzeK8sIgnoresOldRollout(t *testing.T) {
+	old := time.Now().Add(-2 * RolloutLookback).UTC().Format(time.RFC3339)
+	raw := fmt.Sprintf(`{"items":[
+		{"kind":"Deployment","metadata":{"name":"checkout","annotations":{"deployment.kubernetes.io/revision":"2"}}},
+		{"kind":"ReplicaSet","metadata":{"creationTimestamp":%q,
+		  "annotations":{"deployment.kubernetes.io/revision":"2"},
+		  "ownerReferences":[{"kind":"Deployment","name":"checkout"}]}}
+	]}`, old)
+
+	if res, err := AnalyzeK8s("checkout", raw); err != nil || len(res.Rollouts) != 0 || len(res.Events) != 0 {
+		t.Errorf("Expected rollout older than %s to be ignored, got %+v", RolloutLookback, res)
+	}
+
+	// Re-analyzing a past window keeps the rollouts recent at its end
+	windowEnd := time.Now().Add(-2*RolloutLookback + time.Minute)
+	if res, err := AnalyzeK8sCluster("", "checkout", raw, windowEnd); err != nil || len(res.Rollouts) != 1 {
+		t.Errorf("Expected the rollout recent as of the window end, got %+v (%v)", res, err)
+	}
+}
+
+func TestAnalyzeK8sNotReady(t *testing.T) {
+	stale := time.Now().Add(-10 * time.Minute).UTC().Format(time.RFC3339)
+	fresh := time.Now().Add(-30 * time.Second).UTC().Format(time.RFC3339)
+
+	raw := fmt.Sprintf(`{"kind":"List","items":[
+		{"kind":"Pod","metadata":{"name":"checkout-1"},
+		 "status":{"phase":"Running","podIP":"10.0.0.7","conditions":[
+		  {"type":"PodScheduled","status":"True"},
+		  {"type":"Ready","status":"False","lastTransitionTime":%q}]}},
+		{"kind":"Pod","metadata":{"name":"checkout-2"},
+		 "status":{"phase":"Running","conditions":[
+		  {"type":"Ready","status":"False","lastTransitionTime":%q}]}},
+		{"kind":"Pod","metadata":{"name":"checkout-3"},
+		 "status":{"phase":"Running","conditions":[
+		  {"type":"Ready","status":"True","lastTransitionTime":%q}]}}
+	]}`, stale, fresh, stale)
+
+	res, err := AnalyzeK8s("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Only the pod past the threshold is degraded; none have failed
+	if res.DegradedPods != 1 || res.BadPods != 0 {
+		t.Errorf("Expected 1 degraded and 0 failed pods, got %d and %d", res.DegradedPods, res.BadPods)
+	}
+	if len(res.Events) != 1 {
+		t.Fatalf("Expected 1 event, got %+v", res.Events)
+	}
+	if e := res.Events[0]; e.Kind != K8sNotReadyEvent || e.Time != stale || e.PodIP != "10.0.0.7" {
+		t.Errorf("Expected a not-ready event for 10.0.0.7 at %s, got %+v", stale, e)
+	}
+}
+
+func TestAnalyzeK8sUnknownPhase(t *testing.T) {
+	raw := `{"kind":"List","items":[
+		{"kind":"Pod","metadata":{"name":"checkout-1","namespace":"shop"},
+		 "status":{"phase":"Unknown","startTime":"2024-01-15T02:10:00Z"}},
+		{"kind":"Pod","metadata":{"name":"checkout-2","namespace":"shop"},"status":{"phase":"Running"}}
+	]}`
+
+	testCases := []struct {
+		name      string
+		fallbacks map[string]string
+		degraded  int
+		bad       int
+		kind      string
+	}{
+		{"default", nil, 1, 0, K8sNotReadyEvent},
+		{"mapped to failed", map[string]string{"unknown": StatusFailed}, 0, 1, K8sPodEvent},
+		{"wildcard", map[string]string{FallbackWildcard: StatusFailed}, 0, 1, K8sPodEvent},
+		{"mapped to ok", map[string]string{"Unknown": StatusOK}, 0, 0, ""},
+	}
+	defer SetStatusFallbacks(nil)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetStatusFallbacks(tc.fallbacks)
+			res, err := AnalyzeK8s("checkout", raw)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if res.DegradedPods != tc.degraded || res.BadPods != tc.bad {
+				t.Errorf("Expected %d degraded and %d failed pods, got %d and %d", tc.degraded, tc.bad, res.DegradedPods, res.BadPods)
+			}
+			if tc.kind == "" {
+				if len(res.Events) != 0 {
+					t.Errorf("Expected no events, got %+v", res.Events)
+				}
+				return
+			}
+			if len(res.Events) != 1 || res.Events[0].Kind != tc.kind || res.Events[0].Message != "Pod in phase Unknown" || res.Events[0].Pod != "checkout-1" {
+				t.Errorf("Expected a %s event for checkout-1, got %+v", tc.kind, res.Events)
+			}
+		})
+	}
+}
+
+func TestAnalyzeK8sTruncatesLongMessages(t *testing.T) {
+	SetMaxMessageLength(40)
+	defer SetMaxMessageLength(0)
+
+	raw := `{"kind":"List","items":[
+		{"kind":"Pod","metadata":{"name":"checkout-1"},"status":{"phase":"Running","containerStatuses":[
+			{"name":"` + strings.Repeat("sidecar-", 10) + `","lastState":{"terminated":{"reason":"OOMKilled","finishedAt":"2024-01-15T02:13:40Z"}}},
+			{"name":"app","lastState":{"terminated":{"reason":"OOMKilled","finishedAt":"2024-01-15T02:13:41Z"}}}
+		]}}
+	]}`
+	res, err := AnalyzeK8s("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(res.Events) != 2 {
+		t.Fatalf("Expected 2 OOMKill events, got %+v", res.Events)
+	}
+	if long := []rune(res.Events[0].Message); len(long) != 41 || long[40] != '…' {
+		t.Errorf("Expected 40 runes plus an ellipsis, got %q", res.Events[0].Message)
+	}
+	if res.Events[1].Message != "Container app OOMKilled" {
+		t.Errorf("Expected short message untouched, got %q", res.Events[1].Message)
+	}
+}
@@ -1,51 +1,453 @@
 package analysis
 
 import (
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 type LogEvent struct {
-	Time    string
+	Time    string // RFC3339, UTC
 	Message string
+	Labels  map[string]string // Correlation fields of a JSON log line, e.g. trace_id
+
+	// Metadata is the structured metadata Loki attached to the entry, when
+	// surfaced (see SetStructuredMetadata)
+	Metadata map[string]string
+
+	// Count is how many lines had this message when duplicates are collapsed
+	// (see SetLogDedup); 0 otherwise
+	Count int
 }
 
 type LogResult struct {
 	RootCause  string
 	ErrorCount int
 	Events     []LogEvent
+	Counts     []MetricPoint // Matching lines per step, for LogQL metric queries
+	NoData     bool          // No log lines matched the query
+	TotalLines int           // Lines returned, even when Events is sampled
+	Sampled    bool          // Events holds a sample of the lines
 }
 
-func AnalyzeLogs(service string, raw string) LogResult {
-	var parsed map[string]any
-	_ = json.Unmarshal([]byte(raw), &parsed)
+// Defaults for log sampling
+const (
+	DefaultSampleThreshold = 1000
+	DefaultSampleEvery     = 10
+)
+
+var (
+	sampleThreshold = DefaultSampleThreshold
+	sampleEvery     = DefaultSampleEvery
 
-	streams := parsed["data"].(map[string]any)["result"].([]any)
+	// sampleKeep marks lines that are always kept in a sample, along with
+	// every line matching a root-cause keyword
+	sampleKeep = []string{"panic", "fatal", "exception"}
+)
+
+// DefaultRootCauseKeywords mark a log line as an error, case-insensitively
+var DefaultRootCauseKeywords = []string{"error", "exception", "panic", "fatal"}
 
-	var events []LogEvent
-	errorCount := 0
-	rootCause := ""
+var rootCauseKeywords = DefaultRootCauseKeywords
 
+// SetRootCauseKeywords sets the words that make a log line count as an error
+// and a root cause candidate, e.g. to add "failed" or localized words. Matching
+// ignores case. An empty list restores the defaults.
+func SetRootCauseKeywords(keywords []string) {
+	var lower []string
+	for _, k := range keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			lower = append(lower, k)
+		}
+	}
+	if len(lower) == 0 {
+		lower = DefaultRootCauseKeywords
+	}
+	rootCauseKeywords = lower
+}
+
+// IsErrorLine reports whether a log line contains a root-cause keyword
+func IsErrorLine(line string) bool {
+	return containsAny(strings.ToLower(line), rootCauseKeywords)
+}
+
+// LogLineFilter returns a LogQL line filter keeping the lines IsErrorLine
+// would count, so Loki only returns error candidates
+func LogLineFilter() string {
+	quoted := make([]string, len(rootCauseKeywords))
+	for i, k := range rootCauseKeywords {
+		quoted[i] = regexp.QuoteMeta(k)
+	}
+	return "|~ " + strconv.Quote("(?i)("+strings.Join(quoted, "|")+")")
+}
+
+// DefaultCorrelationFields are the JSON log fields copied into LogEvent.Labels
+var DefaultCorrelationFields = []string{"trace_id", "span_id"}
+
+var correlationFields = DefaultCorrelationFields
+
+// SetCorrelationFields sets the JSON log fields copied into LogEvent.Labels,
+// e.g. to add a custom request ID. An empty list restores the defaults.
+func SetCorrelationFields(fields []string) {
+	if len(fields) == 0 {
+		fields = DefaultCorrelationFields
+	}
+	correlationFields = fields
+}
+
+var structuredMetadata = true
+
+// SetStructuredMetadata sets whether AnalyzeLogs copies the structured
+// metadata of Loki entries into LogEvent.Metadata. Correlation fields found in
+// the metadata are added to LogEvent.Labels either way.
+func SetStructuredMetadata(enabled bool) {
+	structuredMetadata = enabled
+}
+
+var dedupLogs bool
+
+// SetLogDedup sets whether AnalyzeLogs collapses lines with the same message
+// anywhere in the response into the first of them, counting the occurrences.
+// Off, every line is kept (subject to sampling).
+func SetLogDedup(enabled bool) {
+	dedupLogs = enabled
+}
+
+// SetLogSampling makes AnalyzeLogs and AnalyzeLogStream keep only every Nth
+// line once a response has more than threshold lines, along with every line
+// matching a root-cause keyword (see SetRootCauseKeywords) or looking like a
+// crash. TotalLines and ErrorCount are still taken over every line. Zero
+// values restore the defaults.
+func SetLogSampling(threshold, every int) {
+	if threshold <= 0 {
+		threshold = DefaultSampleThreshold
+	}
+	if every <= 0 {
+		every = DefaultSampleEvery
+	}
+	sampleThreshold, sampleEvery = threshold, every
+}
+
+// AnalyzeLogs parses a Loki response. Log queries return "streams" of lines;
+// metric queries (count_over_time and friends) return "matrix" or "vector"
+// counts, which carry no lines to pick a root cause from. Entry timestamps may
+// be strings or numbers, and the legacy API's {"streams":[{"entries":[...]}]}
+// shape is also accepted. Entries may carry structured metadata as a third
+// element, [ts, line, {...}].
+func AnalyzeLogs(service string, raw string) (LogResult, error) {
+	parsed, err := lokiResponse(raw)
+	if err != nil {
+		return LogResult{}, err
+	}
+	data, streams, err := resultData(parsed)
+	if err != nil {
+		legacy, ok := parsed["streams"].([]any)
+		if !ok {
+			return LogResult{}, err
+		}
+		data, streams = map[string]any{"resultType": "streams"}, legacy
+	}
+
+	switch resultType, _ := data["resultType"].(string); resultType {
+	case "matrix":
+		return analyzeLogCounts(streams, "values"), nil
+	case "vector":
+		return analyzeLogCounts(streams, "value"), nil
+	}
+
+	var lines [][]any
 	for _, s := range streams {
-		values := s.(map[string]any)["values"].([]any)
+		stream, _ := s.(map[string]any)
+		values, ok := stream["values"].([]any)
+		if !ok {
+			values, ok = legacyEntries(stream)
+		}
+		if !ok {
+			return LogResult{}, invalid("stream without values")
+		}
 		for _, v := range values {
-			ts := v.([]any)[0].(string)
-			msg := v.([]any)[1].(string)
+			entry, _ := v.([]any)
+			if len(entry) < 2 {
+				return LogResult{}, invalid("malformed log entry")
+			}
+			lines = append(lines, entry)
+		}
+	}
+	acc := newLogAccumulator(len(lines) > sampleThreshold)
+	for _, entry := range lines {
+		acc.add(entry)
+	}
+	return acc.result(), nil
+}
 
-			events = append(events, LogEvent{Time: ts, Message: msg})
+// logAccumulator builds a LogResult one [ts, line, metadata?] entry at a time
+type logAccumulator struct {
+	// sampled is whether only a sample of the events is kept. AnalyzeLogs
+	// knows upfront; a stream starts unsampled and thins out the events kept
+	// so far once it passes the sample threshold (see thin).
+	sampled   bool
+	streaming bool
 
-			if strings.Contains(strings.ToLower(msg), "error") {
-				errorCount++
-				if rootCause == "" {
-					rootCause = msg
-				}
+	lines      int
+	events     []LogEvent
+	sampleable []bool // Whether each event would survive sampling, while streaming
+	errorCount int
+	rootCause  string
+
+	// With dedup, occurrences counts every line by message hash, first
+	// indexes the event kept for it, and firstSeen is the time of its first
+	// occurrence, which the kept event reports even when that occurrence
+	// was sampled out
+	occurrences map[[sha256.Size]byte]int
+	first       map[[sha256.Size]byte]int
+	firstSeen   map[[sha256.Size]byte]string
+}
+
+func newLogAccumulator(sampled bool) *logAccumulator {
+	return &logAccumulator{
+		sampled:     sampled,
+		occurrences: make(map[[sha256.Size]byte]int),
+		first:       make(map[[sha256.Size]byte]int),
+		firstSeen:   make(map[[sha256.Size]byte]string),
+	}
+}
+
+func (a *logAccumulator) add(entry []any) {
+	i := a.lines
+	a.lines++
+	if a.streaming && !a.sampled && i >= sampleThreshold {
+		a.thin()
+	}
+
+	ts := normalizeTime(scalarString(entry[0]))
+	line, _ := entry[1].(string)
+	lower := strings.ToLower(line)
+
+	isError := containsAny(lower, rootCauseKeywords)
+	firstError := isError && a.rootCause == ""
+	keep := i%sampleEvery == 0 || isError || containsAny(lower, sampleKeep)
+	msg := ""
+	duplicate := false
+	var key [sha256.Size]byte
+	if dedupLogs {
+		key = sha256.Sum256([]byte(line))
+		a.occurrences[key]++
+		if a.occurrences[key] == 1 {
+			a.firstSeen[key] = ts
+		}
+		_, duplicate = a.first[key]
+		if !duplicate {
+			a.first[key] = len(a.events)
+		}
+	}
+	if duplicate {
+		msg = truncateMessage(line)
+	} else if !a.sampled || keep {
+		msg = truncateMessage(line)
+		event := LogEvent{Time: ts, Message: msg, Labels: correlationLabels(line)}
+		if dedupLogs {
+			event.Time = a.firstSeen[key]
+		}
+		if len(entry) > 2 {
+			metadata := entryMetadata(entry[2])
+			event.Labels = addCorrelationLabels(event.Labels, metadata)
+			if structuredMetadata && len(metadata) > 0 {
+				event.Metadata = metadata
 			}
 		}
+		a.events = append(a.events, event)
+		if a.streaming && !a.sampled {
+			a.sampleable = append(a.sampleable, keep)
+		}
+	} else if dedupLogs {
+		// Sampled out, so a later occurrence may be kept instead
+		delete(a.first, key)
+	}
+
+	if isError {
+		a.errorCount++
+		if firstError {
+			a.rootCause = msg
+		}
+	}
+}
+
+// thin switches a stream to sampling, dropping the events kept so far that
+// AnalyzeLogs would have sampled out
+func (a *logAccumulator) thin() {
+	index := make([]int, len(a.events))
+	kept := a.events[:0]
+	for i, event := range a.events {
+		index[i] = -1
+		if a.sampleable[i] {
+			index[i] = len(kept)
+			kept = append(kept, event)
+		}
+	}
+	clear(a.events[len(kept):])
+	a.events = kept
+	for key, i := range a.first {
+		if index[i] < 0 {
+			delete(a.first, key)
+		} else {
+			a.first[key] = index[i]
+		}
 	}
+	a.sampled, a.sampleable = true, nil
+}
 
+func (a *logAccumulator) result() LogResult {
+	for key, i := range a.first {
+		a.events[i].Count = a.occurrences[key]
+	}
 	return LogResult{
-		RootCause:  rootCause,
-		ErrorCount: errorCount,
-		Events:     events,
+		RootCause:  a.rootCause,
+		ErrorCount: a.errorCount,
+		Events:     a.events,
+		NoData:     a.lines == 0,
+		TotalLines: a.lines,
+		Sampled:    a.sampled,
+	}
+}
+
+// correlationLabels extracts the configured correlation fields from a JSON
+// log line. Plain-text lines and lines without any of the fields give nil.
+func correlationLabels(line string) map[string]string {
+	if !strings.HasPrefix(strings.TrimSpace(line), "{") {
+		return nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return nil
+	}
+
+	var labels map[string]string
+	for _, key := range correlationFields {
+		var value string
+		switch v := fields[key].(type) {
+		case string:
+			value = v
+		case float64, bool:
+			value = fmt.Sprint(v)
+		}
+		if value == "" {
+			continue
+		}
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+// entryMetadata reads the structured metadata of a Loki entry. Loki sends it
+// as a flat object of strings, or with the categorize-labels encoding flag as
+// {"structuredMetadata":{...},"parsed":{...}}, where parsed holds labels
+// extracted at query time; both parts are returned.
+func entryMetadata(v any) map[string]string {
+	fields, _ := v.(map[string]any)
+	if len(fields) == 0 {
+		return nil
+	}
+	metadata := make(map[string]string)
+	categorized := false
+	for _, key := range []string{"structuredMetadata", "parsed"} {
+		if part, ok := fields[key].(map[string]any); ok {
+			categorized = true
+			for k, v := range part {
+				if s, ok := v.(string); ok {
+					metadata[k] = s
+				}
+			}
+		}
+	}
+	if !categorized {
+		for k, v := range fields {
+			if s, ok := v.(string); ok {
+				metadata[k] = s
+			}
+		}
+	}
+	return metadata
+}
+
+// addCorrelationLabels adds the configured correlation fields found in an
+// entry's metadata to labels, keeping those already taken from the line
+func addCorrelationLabels(labels, metadata map[string]string) map[string]string {
+	for _, key := range correlationFields {
+		value := metadata[key]
+		if value == "" || labels[key] != "" {
+			continue
+		}
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+// legacyEntries converts the legacy API's [{"ts":...,"line":...}] entries to
+// [ts, line] values
+func legacyEntries(stream map[string]any) ([]any, bool) {
+	entries, ok := stream["entries"].([]any)
+	if !ok {
+		return nil, false
+	}
+	values := make([]any, 0, len(entries))
+	for _, e := range entries {
+		entry, ok := e.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, []any{entry["ts"], entry["line"]})
+	}
+	return values, true
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeLogCounts sums a metric query's samples across series. key is
+// "values" for matrix results and "value" for a vector's single sample.
+// ErrorCount is the total at the latest timestamp.
+func analyzeLogCounts(series []any, key string) LogResult {
+	totals := make(map[float64]float64)
+	for _, s := range series {
+		m, _ := s.(map[string]any)
+		samples, _ := m[key].([]any)
+		if key == "value" {
+			samples = []any{m[key]}
+		}
+		for _, sample := range samples {
+			pair, ok := sample.([]any)
+			if !ok || len(pair) < 2 {
+				continue
+			}
+			totals[scalarFloat(pair[0])] += scalarFloat(pair[1])
+		}
+	}
+
+	counts := make([]MetricPoint, 0, len(totals))
+	for ts, v := range totals {
+		counts = append(counts, MetricPoint{Time: ts, Value: v})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Time < counts[j].Time })
+
+	res := LogResult{Counts: counts, NoData: len(counts) == 0}
+	if len(counts) > 0 {
+		res.ErrorCount = int(counts[len(counts)-1].Value)
 	}
+	return res
 }
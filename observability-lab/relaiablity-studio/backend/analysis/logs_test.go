@@ -0,0 +1,377 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeLogsStreams(t *testing.T) {
+	raw := `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"app":"checkout"},"values":[
+			["1705284838000000000","level=error msg=\"DB connection timeout\""],
+			["1705284839000000000","level=info msg=\"request served\""]
+		]}
+	]}}`
+
+	res, err := AnalyzeLogs("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if res.ErrorCount != 1 || len(res.Events) != 2 {
+		t.Errorf("Expected 1 error in 2 lines, got %d errors in %d lines", res.ErrorCount, len(res.Events))
+	}
+	if res.RootCause != `level=error msg="DB connection timeout"` {
+		t.Errorf("Expected first error line as root cause, got %q", res.RootCause)
+	}
+	if res.Events[0].Time != "2024-01-15T02:13:58Z" {
+		t.Errorf("Expected Loki's nanosecond timestamp as RFC3339, got %q", res.Events[0].Time)
+	}
+	if len(res.Counts) != 0 {
+		t.Errorf("Expected no counts for a log query, got %v", res.Counts)
+	}
+}
+
+func TestAnalyzeLogsCorrelationLabels(t *testing.T) {
+	SetCorrelationFields([]string{"trace_id", "span_id", "request_id"})
+	defer SetCorrelationFields(nil)
+
+	raw := `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"app":"checkout"},"values":[
+			["1705284838000000000","{\"level\":\"error\",\"msg\":\"DB connection timeout\",\"trace_id\":\"5b8efff798038103\",\"span_id\":\"eee19b7e\",\"request_id\":42,\"user\":\"alice\"}"],
+			["1705284839000000000","level=info msg=\"request served\" trace_id=abc"]
+		]}
+	]}}`
+
+	res, err := AnalyzeLogs("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{"trace_id": "5b8efff798038103", "span_id": "eee19b7e", "request_id": "42"}
+	labels := res.Events[0].Labels
+	if len(labels) != len(expected) {
+		t.Errorf("Expected labels %v, got %v", expected, labels)
+	}
+	for k, v := range expected {
+		if labels[k] != v {
+			t.Errorf("Expected %s=%q, got %q", k, v, labels[k])
+		}
+	}
+	if res.Events[1].Labels != nil {
+		t.Errorf("Expected no labels for a plain-text line, got %v", res.Events[1].Labels)
+	}
+}
+
+func TestAnalyzeLogsStructuredMetadata(t *testing.T) {
+	defer SetStructuredMetadata(true)
+
+	raw := `{"status":"success","data":{"resultType":"streams","encodingFlags":["categorize-labels"],"result":[
+		{"stream":{"app":"checkout"},"values":[
+			["1705284838000000000","error: DB connection timeout",{"structuredMetadata":{"trace_id":"5b8efff798038103","pod":"checkout-7d9f"},"parsed":{"level":"error"}}],
+			["1705284839000000000","request served",{"trace_id":"abc","pod":"checkout-1a2b"}],
+			["1705284840000000000","request served"]
+		]}
+	]}}`
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		metadata []map[string]string
+	}{
+		{"surfaced", true, []map[string]string{
+			{"trace_id": "5b8efff798038103", "pod": "checkout-7d9f", "level": "error"},
+			{"trace_id": "abc", "pod": "checkout-1a2b"},
+			nil,
+		}},
+		{"disabled", false, []map[string]string{nil, nil, nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetStructuredMetadata(tt.enabled)
+			res, err := AnalyzeLogs("checkout", raw)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(res.Events) != 3 {
+				t.Fatalf("Expected 3 events, got %d", len(res.Events))
+			}
+			if res.RootCause != "error: DB connection timeout" {
+				t.Errorf("Expected root cause from the line, got %q", res.RootCause)
+			}
+			for i, expected := range tt.metadata {
+				got := res.Events[i].Metadata
+				if len(got) != len(expected) {
+					t.Errorf("Expected metadata %v for event %d, got %v", expected, i, got)
+				}
+				for k, v := range expected {
+					if got[k] != v {
+						t.Errorf("Expected %s=%q for event %d, got %q", k, v, i, got[k])
+					}
+				}
+			}
+			// Correlation fields are taken from the metadata either way
+			if got := res.Events[0].Labels["trace_id"]; got != "5b8efff798038103" {
+				t.Errorf("Expected trace_id label from metadata, got %q", got)
+			}
+		})
+	}
+}
+
+func TestAnalyzeLogsMatrix(t *testing.T) {
+	raw := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"pod":"checkout-a"},"values":[[1705284780,"2"],[1705284840,"5"]]},
+		{"metric":{"pod":"checkout-b"},"values":[[1705284780,"1"],[1705284840,"3"]]}
+	]}}`
+
+	res, err := AnalyzeLogs("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(res.Counts) != 2 {
+		t.Fatalf("Expected 2 summed points, got %v", res.Counts)
+	}
+	if res.Counts[0].Time != 1705284780 || res.Counts[0].Value != 3 {
+		t.Errorf("Expected first point {1705284780 3}, got %+v", res.Counts[0])
+	}
+	if res.ErrorCount != 8 {
+		t.Errorf("Expected latest total of 8, got %d", res.ErrorCount)
+	}
+	if len(res.Events) != 0 || res.NoData {
+		t.Errorf("Expected counts only, got events=%d noData=%v", len(res.Events), res.NoData)
+	}
+}
+
+func TestAnalyzeLogsVector(t *testing.T) {
+	raw := `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"pod":"checkout-a"},"value":[1705284840,"4"]},
+		{"metric":{"pod":"checkout-b"},"value":[1705284840,"2"]}
+	]}}`
+
+	if res, err := AnalyzeLogs("checkout", raw); err != nil || res.ErrorCount != 6 {
+		t.Errorf("Expected 6 matching lines, got %d", res.ErrorCount)
+	}
+
+	empty, err := AnalyzeLogs("checkout", `{"status":"success","data":{"resultType":"matrix","result":[]}}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !empty.NoData {
+		t.Errorf("Expected empty matrix flagged as no data, got %+v", empty)
+	}
+}
+
+func TestAnalyzeLogsTruncatesLongMessages(t *testing.T) {
+	SetMaxMessageLength(40)
+	defer SetMaxMessageLength(0)
+
+	trace := `panic: nil map write\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:42 +0x1d`
+	raw := `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"app":"checkout"},"values":[
+			["1705284838000000000","` + trace + `"],
+			["1705284839000000000","error: short"]
+		]}
+	]}}`
+
+	res, err := AnalyzeLogs("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	long := []rune(res.Events[0].Message)
+	if len(long) != 41 || long[40] != '…' {
+		t.Errorf("Expected 40 runes plus an ellipsis, got %q", res.Events[0].Message)
+	}
+	if !strings.HasPrefix(res.Events[0].Message, "panic: nil map write\n") {
+		t.Errorf("Expected the message cut by length only, line breaks kept, got %q", res.Events[0].Message)
+	}
+	if res.Events[1].Message != "error: short" {
+		t.Errorf("Expected short message untouched, got %q", res.Events[1].Message)
+	}
+}
+
+func TestAnalyzeLogsSamplesLargeResponses(t *testing.T) {
+	SetLogSampling(100, 10)
+	defer SetLogSampling(0, 0)
+
+	var values []string
+	for i := 0; i < 1000; i++ {
+		line := fmt.Sprintf(`level=info msg=\"request served %d\"`, i)
+		switch {
+		case i == 555:
+			line = "panic: runtime error: invalid memory address"
+		case i%100 == 7:
+			line = fmt.Sprintf(`level=error msg=\"timeout %d\"`, i)
+		}
+		values = append(values, fmt.Sprintf(`["%d","%s"]`, 1705284838000000000+i, line))
+	}
+	raw := `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"app":"checkout"},"values":[` + strings.Join(values, ",") + `]}
+	]}}`
+
+	res, err := AnalyzeLogs("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if res.TotalLines != 1000 || res.ErrorCount != 11 {
+		t.Errorf("Expected exact totals of 1000 lines and 11 errors, got %d lines and %d errors", res.TotalLines, res.ErrorCount)
+	}
+	if !res.Sampled || len(res.Events) != 111 {
+		t.Errorf("Expected every 10th line plus the 11 errors sampled, got %d events", len(res.Events))
+	}
+	kept := 0
+	for _, e := range res.Events {
+		if IsErrorLine(e.Message) {
+			kept++
+		}
+	}
+	if kept != 11 {
+		t.Errorf("Expected every error line kept in the sample, got %d", kept)
+	}
+	if res.RootCause != `level=error msg="timeout 7"` {
+		t.Errorf("Expected the first error as root cause, got %q", res.RootCause)
+	}
+}
+
+func TestAnalyzeLogsDedup(t *testing.T) {
+	raw := `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"app":"checkout","pod":"checkout-1"},"values":[
+			["1705284838000000000","error: db connection refused"],
+			["1705284839000000000","request served"],
+			["1705284840000000000","error: db connection refused"]
+		]},
+		{"stream":{"app":"checkout","pod":"checkout-2"},"values":[
+			["1705284841000000000","error: payment gateway timeout"],
+			["1705284842000000000","error: db connection refused"],
+			["1705284843000000000","request served"]
+		]}
+	]}}`
+
+	res, err := AnalyzeLogs("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(res.Events) != 6 || res.Events[0].Count != 0 {
+		t.Errorf("Expected every line kept without dedup, got %+v", res.Events)
+	}
+
+	SetLogDedup(true)
+	defer SetLogDedup(false)
+
+	res, err = AnalyzeLogs("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []struct {
+		time    string
+		message string
+		count   int
+	}{
+		{"2024-01-15T02:13:58Z", "error: db connection refused", 3},
+		{"2024-01-15T02:13:59Z", "request served", 2},
+		{"2024-01-15T02:14:01Z", "error: payment gateway timeout", 1},
+	}
+	if len(res.Events) != len(expected) {
+		t.Fatalf("Expected %d collapsed events, got %+v", len(expected), res.Events)
+	}
+	for i, want := range expected {
+		if got := res.Events[i]; got.Time != want.time || got.Message != want.message || got.Count != want.count {
+			t.Errorf("Expected %q first at %s seen %d times, got %+v", want.message, want.time, want.count, got)
+		}
+	}
+	if res.ErrorCount != 4 || res.TotalLines != 6 {
+		t.Errorf("Expected counts over every line, 4 errors in 6 lines, got %d in %d", res.ErrorCount, res.TotalLines)
+	}
+}
+
+func TestAnalyzeLogsDedupSampledFirstOccurrence(t *testing.T) {
+	SetLogDedup(true)
+	defer SetLogDedup(false)
+	SetLogSampling(2, 2)
+	defer SetLogSampling(0, 0)
+
+	// The first "request served" (line 1) is sampled out; the one kept
+	// (line 2) reports the first occurrence's time
+	raw := `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"app":"checkout"},"values":[
+			["1705284838000000000","cache warmed"],
+			["1705284839000000000","request served"],
+			["1705284840000000000","request served"]
+		]}
+	]}}`
+	res, err := AnalyzeLogs("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(res.Events) != 2 {
+		t.Fatalf("Expected 2 events, got %+v", res.Events)
+	}
+	if got := res.Events[1]; got.Message != "request served" || got.Time != "2024-01-15T02:13:59Z" || got.Count != 2 {
+		t.Errorf("Expected request served first at 02:13:59Z seen twice, got %+v", got)
+	}
+}
+
+func TestNormalizeTime(t *testing.T) {
+	testCases := []struct {
+		in, want string
+	}{
+		{"1705284838000000000", "2024-01-15T02:13:58Z"},
+		{"1705284838123456789", "2024-01-15T02:13:58.123456789Z"},
+		{"2024-01-15T03:13:58+01:00", "2024-01-15T02:13:58Z"},
+		{"not-a-time", "not-a-time"},
+	}
+
+	for _, tc := range testCases {
+		if got := normalizeTime(tc.in); got != tc.want {
+			t.Errorf("normalizeTime(%q): expected %q, got %q", tc.in, tc.want, got)
+		}
+	}
+}
+
+func TestAnalyzeLogsRootCauseKeywords(t *testing.T) {
+	raw := `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"app":"checkout"},"values":[
+			["1705284838000000000","level=info msg=\"request served\""],
+			["1705284839000000000","level=warn msg=\"Zahlung FEHLGESCHLAGEN: Zeitüberschreitung\""],
+			["1705284840000000000","level=warn msg=\"payment failed: gateway timeout\""]
+		]}
+	]}}`
+
+	res, err := AnalyzeLogs("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.ErrorCount != 0 || res.RootCause != "" {
+		t.Errorf("Expected no errors with the default keywords, got %d (%q)", res.ErrorCount, res.RootCause)
+	}
+
+	SetRootCauseKeywords([]string{"Fehlgeschlagen", " failed "})
+	defer SetRootCauseKeywords(nil)
+
+	res, err = AnalyzeLogs("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.ErrorCount != 2 {
+		t.Errorf("Expected 2 errors, got %d", res.ErrorCount)
+	}
+	if want := `level=warn msg="Zahlung FEHLGESCHLAGEN: Zeitüberschreitung"`; res.RootCause != want {
+		t.Errorf("Expected root cause %q, got %q", want, res.RootCause)
+	}
+}
+
+func TestLogLineFilter(t *testing.T) {
+	if want := `|~ "(?i)(error|exception|panic|fatal)"`; LogLineFilter() != want {
+		t.Errorf("Expected default filter %s, got %s", want, LogLineFilter())
+	}
+
+	SetRootCauseKeywords([]string{"Failed", "5xx.err"})
+	defer SetRootCauseKeywords(nil)
+	if want := `|~ "(?i)(failed|5xx\\.err)"`; LogLineFilter() != want {
+		t.Errorf("Expected filter %s, got %s", want, LogLineFilter())
+	}
+}
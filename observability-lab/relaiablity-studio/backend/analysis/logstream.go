@@ -0,0 +1,353 @@
+package analysis
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// AnalyzeLogStream is AnalyzeLogs for a body read from r. Rather than
+// unmarshaling the whole response, it walks the JSON tokens and decodes one
+// entry at a time, so memory grows with the events kept rather than the lines
+// read: a stream starts sampling once it passes the sample threshold.
+//
+// r may hold a Loki response, which must give resultType before result as
+// Loki does, or a bulk export of one JSON entry per line such as
+// `logcli --output=jsonl` writes: {"line":...,"timestamp":...}.
+func AnalyzeLogStream(service string, r io.Reader) (LogResult, error) {
+	br := bufio.NewReader(r)
+	first, err := firstByte(br)
+	if err != nil {
+		return LogResult{}, err
+	}
+	if first != '{' {
+		if first == '[' || first == 'n' {
+			return LogResult{}, invalid("not an object")
+		}
+		// Loki reports LogQL parse errors as plain text
+		line, _ := br.ReadString('\n')
+		return LogResult{}, &APIError{Message: strings.TrimSpace(line)}
+	}
+
+	s := &logStream{dec: json.NewDecoder(br), acc: newLogAccumulator(false)}
+	s.dec.UseNumber()
+	s.acc.streaming = true
+	res, err := s.analyze()
+	var apiErr *APIError
+	if err == nil || errors.Is(err, ErrInvalidResponse) || errors.As(err, &apiErr) {
+		return res, err
+	}
+	// A body cut short, malformed JSON or a value of the wrong type
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return LogResult{}, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+}
+
+// firstByte returns the first non-space byte of r without consuming it
+func firstByte(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			return 0, invalid("empty body")
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+		default:
+			return b, r.UnreadByte()
+		}
+	}
+}
+
+// logStream decodes a log body token by token into acc
+type logStream struct {
+	dec *json.Decoder
+	acc *logAccumulator
+
+	// From the top-level object of a Loki response
+	status, errorType, message string
+	sawData                    bool
+	counts                     *LogResult
+}
+
+func (s *logStream) analyze() (LogResult, error) {
+	if err := s.delim('{'); err != nil {
+		return LogResult{}, err
+	}
+	exported := false
+	for first := true; s.dec.More(); first = false {
+		key, err := s.key()
+		if err != nil {
+			return LogResult{}, err
+		}
+		if first && key != "status" && key != "data" && key != "streams" {
+			exported = true
+			if err := s.exportedEntry(key); err != nil {
+				return LogResult{}, err
+			}
+			break
+		}
+		if err := s.responseField(key); err != nil {
+			return LogResult{}, err
+		}
+	}
+	if !exported {
+		if err := s.delim('}'); err != nil {
+			return LogResult{}, err
+		}
+		if s.status != "" && s.status != "success" {
+			return LogResult{}, &APIError{Type: s.errorType, Message: s.message}
+		}
+		if !s.sawData {
+			return LogResult{}, invalid("missing data")
+		}
+		if s.counts != nil {
+			return *s.counts, nil
+		}
+		return s.acc.result(), nil
+	}
+
+	for s.dec.More() {
+		var fields map[string]any
+		if err := s.dec.Decode(&fields); err != nil {
+			return LogResult{}, err
+		}
+		if err := s.addExported(fields); err != nil {
+			return LogResult{}, err
+		}
+	}
+	return s.acc.result(), nil
+}
+
+// responseField reads one field of a Loki response's top-level object
+func (s *logStream) responseField(key string) error {
+	switch key {
+	case "status":
+		return s.dec.Decode(&s.status)
+	case "errorType":
+		return s.dec.Decode(&s.errorType)
+	case "error":
+		return s.dec.Decode(&s.message)
+	case "message":
+		if s.message != "" {
+			return s.skip()
+		}
+		return s.dec.Decode(&s.message)
+	case "data":
+		s.sawData = true
+		return s.data()
+	case "streams":
+		// The legacy API's {"streams":[{"entries":[...]}]}
+		s.sawData = true
+		return s.streams()
+	}
+	return s.skip()
+}
+
+// data reads data.resultType and data.result. Metric queries return counts
+// rather than lines, which are few enough to decode at once.
+func (s *logStream) data() error {
+	if err := s.delim('{'); err != nil {
+		return err
+	}
+	resultType := ""
+	sawResult := false
+	for s.dec.More() {
+		key, err := s.key()
+		if err != nil {
+			return err
+		}
+		switch {
+		case key == "resultType":
+			err = s.dec.Decode(&resultType)
+		case key == "result" && (resultType == "matrix" || resultType == "vector"):
+			sawResult = true
+			var series []any
+			if err = s.dec.Decode(&series); err == nil {
+				valueKey := "values"
+				if resultType == "vector" {
+					valueKey = "value"
+				}
+				counts := analyzeLogCounts(series, valueKey)
+				s.counts = &counts
+			}
+		case key == "result":
+			sawResult = true
+			err = s.streams()
+		default:
+			err = s.skip()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if !sawResult {
+		return invalid("missing data.result")
+	}
+	return s.delim('}')
+}
+
+// streams reads an array of streams, each holding values or legacy entries
+func (s *logStream) streams() error {
+	if err := s.delim('['); err != nil {
+		return err
+	}
+	for s.dec.More() {
+		if err := s.delim('{'); err != nil {
+			return err
+		}
+		hasValues := false
+		for s.dec.More() {
+			key, err := s.key()
+			if err != nil {
+				return err
+			}
+			switch key {
+			case "values":
+				hasValues = true
+				err = s.entries(func() ([]any, error) {
+					var entry []any
+					if err := s.dec.Decode(&entry); err != nil {
+						return nil, err
+					}
+					return entry, nil
+				})
+			case "entries":
+				hasValues = true
+				err = s.entries(func() ([]any, error) {
+					var entry struct {
+						TS   any `json:"ts"`
+						Line any `json:"line"`
+					}
+					if err := s.dec.Decode(&entry); err != nil {
+						return nil, err
+					}
+					return []any{entry.TS, entry.Line}, nil
+				})
+			default:
+				err = s.skip()
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if !hasValues {
+			return invalid("stream without values")
+		}
+		if err := s.delim('}'); err != nil {
+			return err
+		}
+	}
+	return s.delim(']')
+}
+
+// entries feeds each entry of an array to the accumulator as it is decoded
+func (s *logStream) entries(next func() ([]any, error)) error {
+	if err := s.delim('['); err != nil {
+		return err
+	}
+	for s.dec.More() {
+		entry, err := next()
+		if err != nil {
+			return err
+		}
+		if len(entry) < 2 {
+			return invalid("malformed log entry")
+		}
+		s.acc.add(entry)
+	}
+	return s.delim(']')
+}
+
+// exportedEntry reads the rest of the first line of a bulk export, whose
+// first key has already been read
+func (s *logStream) exportedEntry(key string) error {
+	fields := make(map[string]any)
+	for {
+		var value any
+		if err := s.dec.Decode(&value); err != nil {
+			return err
+		}
+		fields[key] = value
+		if !s.dec.More() {
+			break
+		}
+		var err error
+		if key, err = s.key(); err != nil {
+			return err
+		}
+	}
+	if err := s.delim('}'); err != nil {
+		return err
+	}
+	return s.addExported(fields)
+}
+
+// addExported adds one line of a bulk export
+func (s *logStream) addExported(fields map[string]any) error {
+	line, ok := fields["line"].(string)
+	if !ok {
+		return invalid("malformed log entry")
+	}
+	ts, ok := fields["timestamp"]
+	if !ok {
+		ts = fields["ts"]
+	}
+	entry := []any{ts, line}
+	if metadata, ok := fields["structuredMetadata"]; ok {
+		entry = append(entry, metadata)
+	}
+	s.acc.add(entry)
+	return nil
+}
+
+// key reads an object key
+func (s *logStream) key() (string, error) {
+	tok, err := s.dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", invalid(fmt.Sprintf("unexpected %v", tok))
+	}
+	return key, nil
+}
+
+// delim reads the delimiter want
+func (s *logStream) delim(want json.Delim) error {
+	tok, err := s.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return invalid(fmt.Sprintf("expected %v, got %v", want, tok))
+	}
+	return nil
+}
+
+// skip discards the next value, however large, without decoding it
+func (s *logStream) skip() error {
+	depth := 0
+	for {
+		tok, err := s.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
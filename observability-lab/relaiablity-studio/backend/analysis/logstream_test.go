@@ -0,0 +1,189 @@
+package analysis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeLogStreamMatchesAnalyzeLogs(t *testing.T) {
+	SetLogSampling(100, 10)
+	defer SetLogSampling(0, 0)
+
+	var values []string
+	for i := 0; i < 1000; i++ {
+		line := fmt.Sprintf(`level=error msg=\"timeout %d\"`, i)
+		if i == 555 {
+			line = "panic: runtime error: invalid memory address"
+		}
+		values = append(values, fmt.Sprintf(`["%d","%s"]`, 1705284838000000000+i, line))
+	}
+
+	testCases := []struct {
+		name string
+		raw  string
+	}{
+		{"streams", `{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"checkout"},"values":[
+				["1705284838000000000","{\"level\":\"error\",\"msg\":\"DB connection timeout\",\"trace_id\":\"abc\"}"],
+				[1705284839000000000,"level=info msg=\"request served\"",{"span_id":"def","pod":"checkout-1"}]
+			]},
+			{"stream":{"app":"checkout","pod":"checkout-2"},"values":[]}
+		],"stats":{"summary":{"totalLinesProcessed":2}}}}`},
+		{"legacy", `{"streams":[{"labels":"{app=\"checkout\"}","entries":[
+			{"ts":"2024-01-15T02:13:58Z","line":"error: db connection refused"}
+		]}]}`},
+		{"matrix", `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"pod":"a"},"values":[[1705284838,"3"],[1705284898,"5"]]},
+			{"metric":{"pod":"b"},"values":[[1705284898,"2"]]}
+		]}}`},
+		{"no lines", `{"status":"success","data":{"resultType":"streams","result":[]}}`},
+		{"sampled", `{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"checkout"},"values":[` + strings.Join(values, ",") + `]}
+		]}}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			want, err := AnalyzeLogs("checkout", tc.raw)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got, err := AnalyzeLogStream("checkout", strings.NewReader(tc.raw))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected the streamed result to match AnalyzeLogs\n got: %+v\nwant: %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeLogStreamExport(t *testing.T) {
+	raw := `{"labels":"{app=\"checkout\"}","line":"error: db connection refused","timestamp":"2024-01-15T03:13:58+01:00"}
+{"labels":"{app=\"checkout\"}","line":"request served","timestamp":"2024-01-15T02:13:59Z"}
+{"line":"{\"msg\":\"panic: nil map\",\"trace_id\":\"abc\"}","ts":"1705284840000000000"}
+`
+	res, err := AnalyzeLogStream("checkout", strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.TotalLines != 3 || res.ErrorCount != 2 || len(res.Events) != 3 {
+		t.Fatalf("Expected 2 errors in 3 lines, got %+v", res)
+	}
+	if res.RootCause != "error: db connection refused" || res.Events[0].Time != "2024-01-15T02:13:58Z" {
+		t.Errorf("Expected the first line as root cause at 02:13:58Z, got %q at %s", res.RootCause, res.Events[0].Time)
+	}
+	if res.Events[2].Time != "2024-01-15T02:14:00Z" || res.Events[2].Labels["trace_id"] != "abc" {
+		t.Errorf("Expected a ts field and correlation labels read, got %+v", res.Events[2])
+	}
+}
+
+func TestAnalyzeLogStreamErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		raw     string
+		invalid bool
+		message string
+	}{
+		{"empty", "  ", true, ""},
+		{"truncated", `{"status":"success","data":{"resultType":"streams","result":[{"values":[["1705284838000000000","err`, true, ""},
+		{"missing data", `{"status":"success"}`, true, ""},
+		{"malformed entry", `{"status":"success","data":{"resultType":"streams","result":[{"values":[["1705284838000000000"]]}]}}`, true, ""},
+		{"export without line", `{"timestamp":"2024-01-15T02:13:58Z"}`, true, ""},
+		{"plain text", "parse error at line 1, col 8: syntax error: unexpected IDENTIFIER\n", false,
+			"parse error at line 1, col 8: syntax error: unexpected IDENTIFIER"},
+		{"loki error", `{"status":"error","errorType":"bad_data","error":"max entries limit per query exceeded"}`, false,
+			"max entries limit per query exceeded"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := AnalyzeLogStream("checkout", strings.NewReader(tc.raw))
+			if tc.invalid {
+				if !errors.Is(err, ErrInvalidResponse) {
+					t.Errorf("Expected ErrInvalidResponse, got %v", err)
+				}
+				return
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.Message != tc.message {
+				t.Errorf("Expected an APIError %q, got %v", tc.message, err)
+			}
+		})
+	}
+}
+
+// syntheticLogs generates a Loki streams response of n lines as it is read,
+// sampling the heap every megabyte
+type syntheticLogs struct {
+	n, next  int
+	buf      bytes.Buffer
+	produced int
+	sampled  int
+	peakHeap uint64
+}
+
+func (g *syntheticLogs) Read(p []byte) (int, error) {
+	for g.buf.Len() < len(p) && g.next <= g.n {
+		switch {
+		case g.next == 0:
+			g.buf.WriteString(`{"status":"success","data":{"resultType":"streams","result":[{"stream":{"app":"checkout"},"values":[`)
+		case g.next == g.n:
+			g.buf.WriteString(`]}]}}`)
+		default:
+			if g.next > 1 {
+				g.buf.WriteByte(',')
+			}
+			// One error in every 1000 lines, each of which a sample keeps
+			level := "info"
+			if g.next%1000 == 500 {
+				level = "error"
+			}
+			fmt.Fprintf(&g.buf, `["%d","level=%s msg=\"upstream slow\" request_id=%08d path=/api/checkout/cart"]`, 1705284838000000000+g.next, level, g.next)
+		}
+		g.next++
+	}
+	n, err := g.buf.Read(p)
+	g.produced += n
+	if g.produced-g.sampled >= 1<<20 {
+		g.sampled = g.produced
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		g.peakHeap = max(g.peakHeap, m.HeapAlloc)
+	}
+	return n, err
+}
+
+func TestAnalyzeLogStreamBoundedMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Generates a 30MB response")
+	}
+	SetLogSampling(1000, 1000)
+	defer SetLogSampling(0, 0)
+
+	const lines = 300000
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	stream := &syntheticLogs{n: lines + 1}
+	res, err := AnalyzeLogStream("checkout", stream)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.TotalLines != lines || res.ErrorCount != lines/1000 || !res.Sampled || len(res.Events) != 2*lines/1000 {
+		t.Errorf("Expected %d lines sampled to %d events, got %d lines and %d events", lines, 2*lines/1000, res.TotalLines, len(res.Events))
+	}
+
+	growth := int64(stream.peakHeap) - int64(before.HeapAlloc)
+	t.Logf("Decoded %d bytes with a peak heap growth of %d bytes", stream.produced, growth)
+	if limit := int64(stream.produced / 4); growth > limit {
+		t.Errorf("Expected heap growth under %d bytes for a %d byte response, got %d", limit, stream.produced, growth)
+	}
+}
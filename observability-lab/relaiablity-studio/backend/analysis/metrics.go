@@ -1,26 +1,95 @@
 package analysis
 
-import "encoding/json"
+import "fmt"
 
+// MetricPoint is a single sample of a range query
+type MetricPoint struct {
+	Time  float64 // Unix seconds
+	Value float64
+}
+
+// MetricResult is the analyzed metrics for a service. ErrorRate is always a
+// percentage (0-100), never a fraction.
 type MetricResult struct {
-	ErrorRate float64
+	ErrorRate float64 // Percent of failed requests, 0-100
 	Latency   float64
-}
+	Series    []MetricPoint // Populated for matrix (range) results
+	NoData    bool          // The query matched no series at all
 
-func AnalyzeMetrics(raw string) MetricResult {
-	var parsed map[string]any
-	_ = json.Unmarshal([]byte(raw), &parsed)
+	// Outliers maps instances (or pods) with unusually high error rates to
+	// their error rate; see AnalyzeInstanceErrors
+	Outliers map[string]float64
+
+	// Exemplars link latency samples to traces; see AnalyzeExemplars
+	Exemplars []Exemplar
+}
 
-	results := parsed["data"].(map[string]any)["result"].([]any)
+func AnalyzeMetrics(raw string) (MetricResult, error) {
+	parsed, err := parseResponse(raw)
+	if err != nil {
+		return MetricResult{}, err
+	}
+	data, results, err := resultData(parsed)
+	if err != nil {
+		return MetricResult{}, err
+	}
 
 	if len(results) == 0 {
-		return MetricResult{}
+		return MetricResult{NoData: true}, nil
+	}
+	first, ok := results[0].(map[string]any)
+	if !ok {
+		return MetricResult{}, invalid("malformed series")
 	}
 
-	val := results[0].(map[string]any)["value"].([]any)[1].(string)
+	if resultType, _ := data["resultType"].(string); resultType == "matrix" {
+		return analyzeMatrix(first), nil
+	}
 
+	sample, ok := first["value"].([]any)
+	if !ok || len(sample) < 2 {
+		return MetricResult{}, invalid("malformed sample")
+	}
 	return MetricResult{
 		ErrorRate: 0.0, // you can derive this with more queries later
-		Latency:   parseFloat(val),
+		Latency:   scalarFloat(sample[1]),
+	}, nil
+}
+
+// AnalyzeErrorRate is AnalyzeMetrics for an error rate query, reporting the
+// latest sample as ErrorRate rather than Latency
+func AnalyzeErrorRate(raw string) (MetricResult, error) {
+	res, err := AnalyzeMetrics(raw)
+	res.ErrorRate, res.Latency = res.Latency, 0
+	return res, err
+}
+
+// analyzeMatrix turns a range query's [timestamp, "value"] pairs into a series.
+// The latest sample is reported as the current value.
+func analyzeMatrix(result map[string]any) MetricResult {
+	values, _ := result["values"].([]any)
+
+	var series []MetricPoint
+	for _, v := range values {
+		pair, ok := v.([]any)
+		if !ok || len(pair) < 2 {
+			continue
+		}
+		series = append(series, MetricPoint{Time: scalarFloat(pair[0]), Value: scalarFloat(pair[1])})
+	}
+
+	res := MetricResult{Series: series, NoData: len(series) == 0}
+	if len(series) > 0 {
+		res.Latency = series[len(series)-1].Value
+	}
+	return res
+}
+
+// ValidateErrorRate rejects error rates outside 0-100, which usually means a
+// query returned a ratio in the wrong unit or divided by the wrong series
+func ValidateErrorRate(rate float64) error {
+	if rate < 0 || rate > 100 {
+		return fmt.Errorf("error rate %.2f%% is outside 0-100%%", rate)
 	}
+	return nil
 }
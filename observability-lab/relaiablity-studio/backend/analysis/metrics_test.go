@@ -0,0 +1,97 @@
+package analysis
+
+import "testing"
+
+func TestAnalyzeMetricsVector(t *testing.T) {
+	raw := `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"service":"checkout"},"value":[1705302840.123,"0.42"]}
+	]}}`
+
+	res, err := AnalyzeMetrics(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if res.Latency != 0.42 {
+		t.Errorf("Expected value 0.42, got %f", res.Latency)
+	}
+	if len(res.Series) != 0 {
+		t.Errorf("Expected no series for vector result, got %d points", len(res.Series))
+	}
+}
+
+func TestAnalyzeErrorRate(t *testing.T) {
+	raw := `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"service":"checkout"},"value":[1705302840.123,"4.2"]}
+	]}}`
+
+	res, err := AnalyzeErrorRate(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.ErrorRate != 4.2 || res.Latency != 0 {
+		t.Errorf("Expected error rate 4.2 and no latency, got %f and %f", res.ErrorRate, res.Latency)
+	}
+}
+
+func TestAnalyzeMetricsMatrix(t *testing.T) {
+	raw := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"service":"checkout"},"values":[
+			[1705302820,"0.10"],
+			[1705302835,"0.25"],
+			[1705302850,"0.40"]
+		]}
+	]}}`
+
+	res, err := AnalyzeMetrics(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(res.Series) != 3 {
+		t.Fatalf("Expected 3 points, got %d", len(res.Series))
+	}
+	if res.Series[1].Time != 1705302835 || res.Series[1].Value != 0.25 {
+		t.Errorf("Unexpected second point: %+v", res.Series[1])
+	}
+	if res.Latency != 0.40 {
+		t.Errorf("Expected latest value 0.40, got %f", res.Latency)
+	}
+}
+
+func TestAnalyzeMetricsEmpty(t *testing.T) {
+	res, err := AnalyzeMetrics(`{"status":"success","data":{"resultType":"matrix","result":[]}}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.Latency != 0 || len(res.Series) != 0 || !res.NoData {
+		t.Errorf("Expected empty result flagged as no data, got %+v", res)
+	}
+
+	res, err = AnalyzeMetrics(`{"status":"success","data":{"resultType":"vector","result":[]}}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !res.NoData {
+		t.Errorf("Expected empty vector flagged as no data, got %+v", res)
+	}
+}
+
+func TestValidateErrorRate(t *testing.T) {
+	testCases := []struct {
+		rate  float64
+		valid bool
+	}{
+		{0, true},
+		{4.2, true},
+		{100, true},
+		{100.5, false},
+		{-1, false},
+	}
+
+	for _, tc := range testCases {
+		if err := ValidateErrorRate(tc.rate); (err == nil) != tc.valid {
+			t.Errorf("Expected ValidateErrorRate(%v) valid=%v, got err=%v", tc.rate, tc.valid, err)
+		}
+	}
+}
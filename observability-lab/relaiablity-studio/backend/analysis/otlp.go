@@ -0,0 +1,418 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// otlpStatusError is STATUS_CODE_ERROR in OTLP's span status
+const otlpStatusError = 2
+
+// otlpSpan is the part of an OTLP span needed to find failures
+type otlpSpan struct {
+	traceID string
+	spanID  string
+	parent  string
+	service string
+	start   uint64
+	failed  bool
+
+	// kind is the Failure* kind of a failed span
+	kind string
+}
+
+// AnalyzeOTLPJSON analyzes an OTLP/HTTP ExportTraceServiceRequest in its JSON
+// encoding. A trace fails when any of its spans has an error status.
+func AnalyzeOTLPJSON(raw string) (TraceResult, error) {
+	var req struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []otlpKeyValue `json:"attributes"`
+			} `json:"resource"`
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID           string         `json:"traceId"`
+					SpanID            string         `json:"spanId"`
+					ParentSpanID      string         `json:"parentSpanId"`
+					StartTimeUnixNano string         `json:"startTimeUnixNano"`
+					Attributes        []otlpKeyValue `json:"attributes"`
+					Status            struct {
+						Code    any    `json:"code"`
+						Message string `json:"message"`
+					} `json:"status"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	if err := json.Unmarshal([]byte(raw), &req); err != nil {
+		return TraceResult{}, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	if req.ResourceSpans == nil {
+		return TraceResult{}, invalid("missing resourceSpans")
+	}
+
+	var spans []otlpSpan
+	for _, rs := range req.ResourceSpans {
+		service := otlpAttribute(rs.Resource.Attributes, "service.name")
+		for _, ss := range rs.ScopeSpans {
+			for _, s := range ss.Spans {
+				start, _ := strconv.ParseUint(s.StartTimeUnixNano, 10, 64)
+				span := otlpSpan{
+					traceID: s.TraceID,
+					spanID:  s.SpanID,
+					parent:  s.ParentSpanID,
+					service: service,
+					start:   start,
+					failed:  s.Status.Code == float64(otlpStatusError) || s.Status.Code == "STATUS_CODE_ERROR",
+				}
+				if span.failed {
+					code := otlpAttribute(s.Attributes, "http.status_code")
+					if code == "" {
+						code = otlpAttribute(s.Attributes, "http.response.status_code")
+					}
+					span.kind = ClassifyFailure(parseInt(code), s.Status.Message)
+				}
+				spans = append(spans, span)
+			}
+		}
+	}
+	return otlpResult(spans), nil
+}
+
+type otlpKeyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+		IntValue    any    `json:"intValue"`
+	} `json:"value"`
+}
+
+// otlpAttribute reads a string or integer attribute
+func otlpAttribute(attributes []otlpKeyValue, key string) string {
+	for _, a := range attributes {
+		if a.Key == key {
+			if a.Value.StringValue != "" {
+				return a.Value.StringValue
+			}
+			return scalarString(a.Value.IntValue)
+		}
+	}
+	return ""
+}
+
+// AnalyzeOTLPProto analyzes an OTLP/HTTP ExportTraceServiceRequest in its
+// protobuf encoding. Only the fields needed for failures are decoded.
+func AnalyzeOTLPProto(data []byte) (TraceResult, error) {
+	var spans []otlpSpan
+	// ExportTraceServiceRequest: 1 = resource_spans
+	err := protoFields(data, func(num protowire.Number, v []byte) error {
+		if num != 1 {
+			return nil
+		}
+		var service string
+		var resourceSpans []otlpSpan
+		// ResourceSpans: 1 = resource, 2 = scope_spans
+		err := protoFields(v, func(num protowire.Number, v []byte) error {
+			switch num {
+			case 1:
+				// Resource: 1 = attributes
+				return protoFields(v, func(num protowire.Number, v []byte) error {
+					if num != 1 {
+						return nil
+					}
+					key, value, err := protoKeyValue(v)
+					if key == "service.name" {
+						service = value
+					}
+					return err
+				})
+			case 2:
+				// ScopeSpans: 2 = spans
+				return protoFields(v, func(num protowire.Number, v []byte) error {
+					if num != 2 {
+						return nil
+					}
+					span, err := protoSpan(v)
+					resourceSpans = append(resourceSpans, span)
+					return err
+				})
+			}
+			return nil
+		})
+		for i := range resourceSpans {
+			resourceSpans[i].service = service
+		}
+		spans = append(spans, resourceSpans...)
+		return err
+	})
+	if err != nil {
+		return TraceResult{}, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	return otlpResult(spans), nil
+}
+
+// protoSpan decodes Span: 1 = trace_id, 2 = span_id, 4 = parent_span_id,
+// 7 = start_time_unix_nano,
+// 9 = attributes, 15 = status (whose field 2 is the message and 3 the code)
+func protoSpan(data []byte) (otlpSpan, error) {
+	var span otlpSpan
+	var httpStatus, message string
+	err := protoFields(data, func(num protowire.Number, v []byte) error {
+		switch num {
+		case 1:
+			span.traceID = fmt.Sprintf("%x", v)
+		case 2:
+			span.spanID = fmt.Sprintf("%x", v)
+		case 4:
+			span.parent = fmt.Sprintf("%x", v)
+		case 7:
+			span.start, _ = protowire.ConsumeFixed64(v)
+		case 9:
+			key, value, err := protoKeyValue(v)
+			if key == "http.status_code" || (key == "http.response.status_code" && httpStatus == "") {
+				httpStatus = value
+			}
+			return err
+		case 15:
+			return protoFields(v, func(num protowire.Number, v []byte) error {
+				switch num {
+				case 2:
+					message = string(v)
+				case 3:
+					code, _ := protowire.ConsumeVarint(v)
+					span.failed = code == otlpStatusError
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if span.failed {
+		span.kind = ClassifyFailure(parseInt(httpStatus), message)
+	}
+	return span, err
+}
+
+// protoKeyValue decodes KeyValue: 1 = key, 2 = value (AnyValue, whose field 1
+// is string_value and 3 is int_value)
+func protoKeyValue(data []byte) (key, value string, err error) {
+	err = protoFields(data, func(num protowire.Number, v []byte) error {
+		switch num {
+		case 1:
+			key = string(v)
+		case 2:
+			return protoFields(v, func(num protowire.Number, v []byte) error {
+				switch num {
+				case 1:
+					value = string(v)
+				case 3:
+					n, _ := protowire.ConsumeVarint(v)
+					value = strconv.FormatInt(int64(n), 10)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return key, value, err
+}
+
+// protoFields calls fn with each field of a protobuf message. Length-delimited
+// fields get their contents; other fields get their raw encoded value.
+func protoFields(data []byte, fn func(protowire.Number, []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		var value []byte
+		if typ == protowire.BytesType {
+			v, m := protowire.ConsumeBytes(data)
+			if m < 0 {
+				return protowire.ParseError(m)
+			}
+			value, n = v, m
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			value = data[:n]
+		}
+		data = data[n:]
+
+		if err := fn(num, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// otlpResult counts each trace with a failed span once, attributing it to the
+// services of its failed spans, like AnalyzeTraces does for Tempo results.
+// Estimates use the configured sampling rate.
+func otlpResult(spans []otlpSpan) TraceResult {
+	traces := make(map[string]map[string]bool)
+	var failed []otlpSpan
+	for _, s := range spans {
+		addTraceService(traces, s.traceID, s.service)
+		if s.failed {
+			failed = append(failed, s)
+		}
+	}
+	return summarizeOTLP(traces, failed)
+}
+
+// addTraceService records that a trace passed through service
+func addTraceService(traces map[string]map[string]bool, traceID, service string) {
+	if traces[traceID] == nil {
+		traces[traceID] = make(map[string]bool)
+	}
+	if service != "" {
+		traces[traceID][service] = true
+	}
+}
+
+// summarizeOTLP builds the result for the traces seen, given their failed
+// spans. The spans and traces are kept on the result for MergeTraces.
+func summarizeOTLP(traces map[string]map[string]bool, spans []otlpSpan) TraceResult {
+	result := TraceResult{
+		ServiceTotals:            make(map[string]int),
+		ServiceFailures:          make(map[string]int),
+		ServiceEstimatedFailures: make(map[string]float64),
+		FailureKinds:             make(map[string]int),
+	}
+	failed := make(map[string]map[string]bool)
+	byTrace := make(map[string][]callSpan)
+	for _, s := range spans {
+		byTrace[s.traceID] = append(byTrace[s.traceID], callSpan{
+			id: s.spanID, parent: s.parent, service: s.service, start: int64(s.start), failed: s.failed,
+		})
+		services, seen := failed[s.traceID]
+		if !seen {
+			services = make(map[string]bool)
+			failed[s.traceID] = services
+			result.Failures++
+			result.EstimatedFailures += 1 / samplingRate
+			result.Events = append(result.Events, TraceEvent{
+				Time:    normalizeTime(strconv.FormatUint(s.start, 10)),
+				Message: "Trace failure",
+				TraceID: s.traceID,
+			})
+			result.FailureKinds[s.kind]++
+		}
+		if s.service != "" && !services[s.service] {
+			services[s.service] = true
+			result.ServiceFailures[s.service]++
+			result.ServiceEstimatedFailures[s.service] += 1 / samplingRate
+		}
+	}
+	for traceID := range failed {
+		result.recordCalls(byTrace[traceID])
+	}
+	result.Total = len(traces)
+	for _, services := range traces {
+		for service := range services {
+			result.ServiceTotals[service]++
+		}
+	}
+	result.otlpTraces, result.otlpFailed = traces, spans
+	return result
+}
+
+// MergeTraces combines trace results, e.g. successive OTLP exports. OTLP
+// results are merged by trace, so a trace whose spans arrived in several
+// exports counts once; others are added up.
+func MergeTraces(results ...TraceResult) TraceResult {
+	var traces map[string]map[string]bool
+	var spans []otlpSpan
+	seen := make(map[[2]string]bool)
+	var others []TraceResult
+	for _, r := range results {
+		if r.otlpTraces == nil {
+			others = append(others, r)
+			continue
+		}
+		if traces == nil {
+			traces = make(map[string]map[string]bool)
+		}
+		for traceID, services := range r.otlpTraces {
+			addTraceService(traces, traceID, "")
+			for service := range services {
+				addTraceService(traces, traceID, service)
+			}
+		}
+		for _, s := range r.otlpFailed {
+			// A retried export repeats its spans
+			key := [2]string{s.traceID, s.spanID}
+			if s.spanID != "" && seen[key] {
+				continue
+			}
+			seen[key] = true
+			spans = append(spans, s)
+		}
+	}
+	if traces != nil {
+		otlp := summarizeOTLP(traces, spans)
+		if len(others) == 0 {
+			return otlp
+		}
+		others = append(others, otlp)
+	}
+
+	merged := TraceResult{
+		ServiceTotals:            make(map[string]int),
+		ServiceFailures:          make(map[string]int),
+		ServiceEstimatedFailures: make(map[string]float64),
+		FailureKinds:             make(map[string]int),
+	}
+	for _, r := range others {
+		merged.Failures += r.Failures
+		for service, n := range r.Degraded {
+			if merged.Degraded == nil {
+				merged.Degraded = make(map[string]int)
+			}
+			merged.Degraded[service] += n
+		}
+		merged.Total += r.Total
+		merged.EstimatedFailures += r.EstimatedFailures
+		merged.Events = append(merged.Events, r.Events...)
+		for service, n := range r.ServiceTotals {
+			merged.ServiceTotals[service] += n
+		}
+		for service, n := range r.ServiceFailures {
+			merged.ServiceFailures[service] += n
+		}
+		for service, n := range r.ServiceEstimatedFailures {
+			merged.ServiceEstimatedFailures[service] += n
+		}
+		for kind, n := range r.FailureKinds {
+			merged.FailureKinds[kind] += n
+		}
+		for service, start := range r.FirstFailure {
+			if first, ok := merged.FirstFailure[service]; !ok || start < first {
+				if merged.FirstFailure == nil {
+					merged.FirstFailure = make(map[string]int64)
+				}
+				merged.FirstFailure[service] = start
+			}
+		}
+		for caller, callees := range r.FailedCalls {
+			for callee, n := range callees {
+				if merged.FailedCalls == nil {
+					merged.FailedCalls = make(map[string]map[string]int)
+				}
+				if merged.FailedCalls[caller] == nil {
+					merged.FailedCalls[caller] = make(map[string]int)
+				}
+				merged.FailedCalls[caller][callee] += n
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// message concatenates encoded fields into a message body
+func message(fields ...[]byte) []byte {
+	var b []byte
+	for _, f := range fields {
+		b = append(b, f...)
+	}
+	return b
+}
+
+func bytesField(num protowire.Number, v []byte) []byte {
+	b := protowire.AppendTag(nil, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func TestAnalyzeOTLPProto(t *testing.T) {
+	attr := bytesField(1, message(
+		bytesField(1, []byte("service.name")),
+		bytesField(2, bytesField(1, []byte("checkout"))),
+	))
+	span := func(traceID byte, code uint64) []byte {
+		start := protowire.AppendTag(nil, 7, protowire.Fixed64Type)
+		start = protowire.AppendFixed64(start, 1705284840000000000)
+		status := protowire.AppendTag(nil, 3, protowire.VarintType)
+		status = protowire.AppendVarint(status, code)
+		return bytesField(2, message(
+			bytesField(1, []byte{traceID}),
+			bytesField(5, []byte("POST /pay")),
+			start,
+			bytesField(15, status),
+		))
+	}
+	export := bytesField(1, message(
+		bytesField(1, attr),
+		bytesField(2, message(span(1, 2), span(1, 2), span(2, 1))),
+	))
+
+	res, err := AnalyzeOTLPProto(export)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.Failures != 1 || res.ServiceFailures["checkout"] != 1 {
+		t.Errorf("Expected one failed checkout trace, got %+v", res)
+	}
+	if len(res.Events) != 1 || res.Events[0].Time != "2024-01-15T02:14:00Z" {
+		t.Errorf("Expected the failure at the span start, got %+v", res.Events)
+	}
+
+	if _, err := AnalyzeOTLPProto(export[:len(export)-3]); err == nil {
+		t.Error("Expected an error for a truncated export")
+	}
+}
+
+func TestMergeTracesSplitAcrossExports(t *testing.T) {
+	export := func(service, spans string) string {
+		return `{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"` + service + `"}}]},
+			"scopeSpans":[{"spans":[` + spans + `]}]}]}`
+	}
+	first, err := AnalyzeOTLPJSON(export("checkout", `
+		{"traceId":"01","spanId":"a1","startTimeUnixNano":"1705284840000000000","status":{"code":2}},
+		{"traceId":"02","spanId":"b1","startTimeUnixNano":"1705284841000000000","status":{}}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The rest of trace 01, from another service's exporter
+	second, err := AnalyzeOTLPJSON(export("payments", `
+		{"traceId":"01","spanId":"a2","parentSpanId":"a1","startTimeUnixNano":"1705284840500000000","status":{"code":2}}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The first export is retried
+	merged := MergeTraces(first, second, first)
+	if merged.Failures != 1 || merged.Total != 2 || len(merged.Events) != 1 {
+		t.Errorf("Expected 1 failed trace of 2, got %d of %d with %d events", merged.Failures, merged.Total, len(merged.Events))
+	}
+	if merged.ServiceFailures["checkout"] != 1 || merged.ServiceFailures["payments"] != 1 {
+		t.Errorf("Expected the trace attributed once to each service, got %v", merged.ServiceFailures)
+	}
+	if merged.FailureRate("checkout") != 50 || merged.FailureRate("payments") != 100 {
+		t.Errorf("Expected each service graded over its own traces, got checkout %v%% and payments %v%%",
+			merged.FailureRate("checkout"), merged.FailureRate("payments"))
+	}
+	if merged.FailedCalls["checkout"]["payments"] != 1 {
+		t.Errorf("Expected the failed call across exports linked, got %v", merged.FailedCalls)
+	}
+	if merged.Events[0].Time != "2024-01-15T02:14:00Z" {
+		t.Errorf("Expected the failure time in RFC3339, got %s", merged.Events[0].Time)
+	}
+}
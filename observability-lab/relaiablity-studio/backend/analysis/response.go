@@ -0,0 +1,96 @@
+package analysis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidResponse is returned when an upstream body is truncated, isn't
+// JSON, or lacks the fields an analyzer needs
+var ErrInvalidResponse = errors.New("truncated or invalid response")
+
+// parseResponse decodes raw as a JSON object. Proxies occasionally cut large
+// bodies short, which must surface as an error rather than a nil map. Numbers
+// are kept as json.Number, as nanosecond timestamps don't fit a float64.
+func parseResponse(raw string) (map[string]any, error) {
+	var parsed map[string]any
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	if parsed == nil {
+		return nil, fmt.Errorf("%w: empty body", ErrInvalidResponse)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("%w: data after the JSON object", ErrInvalidResponse)
+	}
+	return parsed, nil
+}
+
+// invalid reports a response that parsed but doesn't have the expected shape
+func invalid(what string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidResponse, what)
+}
+
+// APIError is a query Prometheus or Loki rejected, reported in the body as
+// {"status":"error","errorType":"bad_data","error":"..."}. The response is
+// well formed, so it doesn't match ErrInvalidResponse.
+type APIError struct {
+	Type    string // errorType, e.g. bad_data, timeout, execution
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Type == "" {
+		return "query failed: " + e.Message
+	}
+	return fmt.Sprintf("query failed (%s): %s", e.Type, e.Message)
+}
+
+// resultData returns data and data.result from a Prometheus/Loki response,
+// or an *APIError when the response reports a failed query
+func resultData(parsed map[string]any) (map[string]any, []any, error) {
+	if status, _ := parsed["status"].(string); status == "error" {
+		errorType, _ := parsed["errorType"].(string)
+		message, _ := parsed["error"].(string)
+		return nil, nil, &APIError{Type: errorType, Message: message}
+	}
+	data, ok := parsed["data"].(map[string]any)
+	if !ok {
+		return nil, nil, invalid("missing data")
+	}
+	results, ok := data["result"].([]any)
+	if !ok {
+		return nil, nil, invalid("missing data.result")
+	}
+	return data, results, nil
+}
+
+// lokiResponse decodes a Loki body. Loki reports most rejected queries in
+// JSON, with the reason in "error" or (from its HTTP error handler) in
+// "message", but LogQL parse errors come back as plain text. Either becomes
+// an *APIError carrying Loki's message; a body cut short is still an
+// ErrInvalidResponse.
+func lokiResponse(raw string) (map[string]any, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed != "" && trimmed[0] != '{' && trimmed[0] != '[' && trimmed != "null" {
+		message, _, _ := strings.Cut(trimmed, "\n")
+		return nil, &APIError{Message: message}
+	}
+	parsed, err := parseResponse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if status, ok := parsed["status"].(string); ok && status != "success" {
+		errorType, _ := parsed["errorType"].(string)
+		message, _ := parsed["error"].(string)
+		if message == "" {
+			message, _ = parsed["message"].(string)
+		}
+		return nil, &APIError{Type: errorType, Message: message}
+	}
+	return parsed, nil
+}
@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAnalyzersRejectTruncatedResponses(t *testing.T) {
+	analyzers := map[string]func(string) error{
+		"metrics": func(raw string) error { _, err := AnalyzeMetrics(raw); return err },
+		"logs":    func(raw string) error { _, err := AnalyzeLogs("checkout", raw); return err },
+		"traces":  func(raw string) error { _, err := AnalyzeTraces(raw); return err },
+		"k8s":     func(raw string) error { _, err := AnalyzeK8s("checkout", raw); return err },
+	}
+	bodies := map[string]string{
+		"truncated":   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"serv`,
+		"empty":       ``,
+		"null":        `null`,
+		"wrong shape": `{"status":"success","data":{"resultType":"vector"}}`,
+	}
+
+	for name, analyze := range analyzers {
+		for body, raw := range bodies {
+			err := analyze(raw)
+			if !errors.Is(err, ErrInvalidResponse) {
+				t.Errorf("Expected %s analyzer to reject %s body, got %v", name, body, err)
+			}
+		}
+	}
+}
+
+func TestAnalyzersReportQueryErrors(t *testing.T) {
+	raw := `{"status":"error","errorType":"bad_data","error":"parse error at char 5: unexpected \"}\""}`
+	analyzers := map[string]func(string) error{
+		"metrics": func(raw string) error { _, err := AnalyzeMetrics(raw); return err },
+		"logs":    func(raw string) error { _, err := AnalyzeLogs("checkout", raw); return err },
+	}
+
+	for name, analyze := range analyzers {
+		err := analyze(raw)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Expected %s analyzer to return an APIError, got %v", name, err)
+		}
+		if apiErr.Type != "bad_data" || apiErr.Message != `parse error at char 5: unexpected "}"` {
+			t.Errorf("Expected %s analyzer to keep errorType and error, got %+v", name, apiErr)
+		}
+		if errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("Expected %s query error not to count as a bad shape", name)
+		}
+	}
+}
+
+func TestAnalyzeLogsLokiErrors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		body      string
+		errorType string
+		message   string
+	}{
+		{"plain text", "parse error at line 1, col 11: syntax error: unexpected IDENTIFIER\n", "", "parse error at line 1, col 11: syntax error: unexpected IDENTIFIER"},
+		{"json message", `{"code":400,"message":"max entries limit per query exceeded, limit > max_entries_limit (10000 > 5000)","status":"error"}`, "", "max entries limit per query exceeded, limit > max_entries_limit (10000 > 5000)"},
+		{"json error", `{"status":"error","errorType":"timeout","error":"query timed out"}`, "timeout", "query timed out"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := AnalyzeLogs("checkout", tc.body)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected an APIError, got %v", err)
+			}
+			if apiErr.Type != tc.errorType || apiErr.Message != tc.message {
+				t.Errorf("Expected %q (%s), got %+v", tc.message, tc.errorType, apiErr)
+			}
+			if errors.Is(err, ErrInvalidResponse) {
+				t.Error("Expected a rejected query not to count as a bad shape")
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package analysis
+
+// SamplingRateAttribute is the span attribute holding the probability (0-1]
+// with which its trace was sampled, overriding the configured rate
+const SamplingRateAttribute = "sampling.rate"
+
+var samplingRate = 1.0
+
+// SetTraceSamplingRate sets the probability (0-1] with which traces are
+// sampled, e.g. 0.01 for 1%. Failure estimates scale each failed trace by its
+// inverse. Values outside (0, 1] restore 1, i.e. every trace is kept.
+func SetTraceSamplingRate(rate float64) {
+	if rate <= 0 || rate > 1 {
+		rate = 1
+	}
+	samplingRate = rate
+}
+
+// traceSamplingRate returns the sampling rate recorded on the trace's spans,
+// falling back to the configured rate
+func traceSamplingRate(trace map[string]any) float64 {
+	for _, span := range traceSpans(trace) {
+		if rate := parseFloat(spanAttribute(span, SamplingRateAttribute)); rate > 0 && rate <= 1 {
+			return rate
+		}
+	}
+	return samplingRate
+}
@@ -0,0 +1,113 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeLogsShapeVariants(t *testing.T) {
+	testCases := []struct {
+		name string
+		raw  string
+	}{
+		{"string timestamps", `{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"checkout"},"values":[["1705284838000000000","level=error msg=timeout"]]}
+		]}}`},
+		{"number timestamps", `{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"checkout"},"values":[[1705284838000000000,"level=error msg=timeout"]]}
+		]}}`},
+		{"structured metadata", `{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"checkout"},"values":[["1705284838000000000","level=error msg=timeout",{"trace_id":"abc"}]]}
+		]}}`},
+		{"legacy entries", `{"streams":[
+			{"labels":"{app=\"checkout\"}","entries":[{"ts":"2024-01-15T02:13:58Z","line":"level=error msg=timeout"}]}
+		]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := AnalyzeLogs("checkout", tc.raw)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if res.ErrorCount != 1 || len(res.Events) != 1 {
+				t.Fatalf("Expected one error line, got %+v", res)
+			}
+			if res.Events[0].Time != "2024-01-15T02:13:58Z" {
+				t.Errorf("Expected time 2024-01-15T02:13:58Z, got %q", res.Events[0].Time)
+			}
+		})
+	}
+}
+
+func TestAnalyzeLogCountsShapeVariants(t *testing.T) {
+	for _, sample := range []string{`[1705284840,"7"]`, `[1705284840,7]`, `["1705284840","7"]`} {
+		raw := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":` + sample + `}]}}`
+		res, err := AnalyzeLogs("checkout", raw)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", sample, err)
+		}
+		if res.ErrorCount != 7 || len(res.Counts) != 1 || res.Counts[0].Time != 1705284840 {
+			t.Errorf("%s: expected 7 lines at 1705284840, got %+v", sample, res.Counts)
+		}
+	}
+}
+
+func TestAnalyzeMetricsShapeVariants(t *testing.T) {
+	testCases := []struct {
+		name string
+		raw  string
+	}{
+		{"vector [number,string]", `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1705284840,"0.25"]}]}}`},
+		{"vector [number,number]", `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1705284840,0.25]}]}}`},
+		{"matrix [number,string]", `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1705284825,"0.1"],[1705284840,"0.25"]]}]}}`},
+		{"matrix [string,string]", `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[["1705284825","0.1"],["1705284840","0.25"]]}]}}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := AnalyzeMetrics(tc.raw)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if res.Latency != 0.25 {
+				t.Errorf("Expected current value 0.25, got %v", res.Latency)
+			}
+			if len(res.Series) > 0 && res.Series[len(res.Series)-1].Time != 1705284840 {
+				t.Errorf("Expected last sample at 1705284840, got %+v", res.Series)
+			}
+		})
+	}
+}
+
+func TestAnalyzeTracesShapeVariants(t *testing.T) {
+	// Numbers keep nanosecond precision that a float64 would round away
+	for _, start := range []string{`"1705284840123456789"`, `1705284840123456789`} {
+		res, err := AnalyzeTraces(`{"traces":[{"traceID":"1","rootServiceName":"checkout","status":"error","startTimeUnixNano":` + start + `}]}`)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", start, err)
+		}
+		if len(res.Events) != 1 || res.Events[0].Time != "2024-01-15T02:14:00.123456789Z" {
+			t.Errorf("%s: expected the start time in RFC3339, got %+v", start, res.Events)
+		}
+	}
+}
+
+func TestAnalyzeLogsNanosecondNumbers(t *testing.T) {
+	raw := `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"app":"checkout"},"values":[[1705284838123456789,"level=error msg=timeout"]]}
+	]}}`
+	buffered, err := AnalyzeLogs("checkout", raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	streamed, err := AnalyzeLogStream("checkout", strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for name, res := range map[string]LogResult{"buffered": buffered, "streamed": streamed} {
+		if len(res.Events) != 1 || res.Events[0].Time != "2024-01-15T02:13:58.123456789Z" {
+			t.Errorf("%s: expected the exact nanosecond time, got %+v", name, res.Events)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package analysis
+
+import "strings"
+
+// What an upstream status means for severity
+const (
+	StatusOK       = "ok"       // Benign
+	StatusDegraded = "degraded" // Counts like a pod failing readiness
+	StatusFailed   = "failed"   // Counts like a failed pod or trace
+)
+
+// DefaultStatusFallback is what pod phases the analyzers don't recognize mean
+// unless mapped: a pod in phase Unknown may well be down, so it isn't benign.
+// Unmapped trace statuses are failures.
+const DefaultStatusFallback = StatusDegraded
+
+// FallbackWildcard keys the fallback for every unmapped status
+const FallbackWildcard = "*"
+
+var statusFallbacks map[string]string
+
+// SetStatusFallbacks maps upstream statuses the analyzers don't recognize,
+// such as pod phase Unknown or trace status unset, to StatusOK,
+// StatusDegraded or StatusFailed. Statuses are matched case-insensitively;
+// FallbackWildcard sets the fallback for the rest, which is otherwise
+// DefaultStatusFallback for pods and StatusFailed for traces. nil clears the
+// mapping.
+func SetStatusFallbacks(fallbacks map[string]string) {
+	statusFallbacks = make(map[string]string, len(fallbacks))
+	for status, outcome := range fallbacks {
+		statusFallbacks[strings.ToLower(status)] = outcome
+	}
+}
+
+// fallbackStatus is what an unrecognized status means
+func fallbackStatus(status string) string {
+	if outcome, ok := mappedStatus(status); ok {
+		return outcome
+	}
+	return DefaultStatusFallback
+}
+
+// mappedStatus is what SetStatusFallbacks maps status to, if anything
+func mappedStatus(status string) (string, bool) {
+	if outcome, ok := statusFallbacks[strings.ToLower(status)]; ok {
+		return outcome, true
+	}
+	if outcome, ok := statusFallbacks[FallbackWildcard]; ok {
+		return outcome, true
+	}
+	return "", false
+}
@@ -1,39 +1,306 @@
 package analysis
 
-import "encoding/json"
+import "strings"
+
+// Kinds of trace failure, from the failing span's HTTP status code and
+// status message
+const (
+	FailureClientError = "client_error"
+	FailureServerError = "server_error"
+	FailureTimeout     = "timeout"
+	FailureCancelled   = "cancelled"
+)
 
 type TraceEvent struct {
 	Time    string
 	Message string
+	TraceID string
 }
 
 type TraceResult struct {
 	Failures int
 	Events   []TraceEvent
+
+	// Degraded counts traces whose status SetStatusFallbacks maps to
+	// StatusDegraded, by root service ("" when unknown). They are left out of
+	// Failures and the failure rate, but degrade the service like a pod
+	// failing readiness.
+	Degraded map[string]int
+
+	// Total counts the traces examined, failed or not; 0 when that isn't
+	// known, e.g. for a search that only returns failed traces
+	Total int
+
+	// ServiceTotals counts the traces examined per service, by their root
+	// service and the service.name of their spans. A search over every
+	// service returns some of each, so the failure rate of one is taken over
+	// its own traces rather than Total.
+	ServiceTotals map[string]int
+
+	// EstimatedFailures is Failures scaled up by the sampling rate of each
+	// failed trace: an estimate of the failures before sampling, equal to
+	// Failures when every trace is kept
+	EstimatedFailures float64
+
+	// ServiceFailures counts failed traces per service, by the service.name
+	// of their failing spans (or the root service when spans aren't returned)
+	ServiceFailures map[string]int
+
+	// ServiceEstimatedFailures is EstimatedFailures per service, attributed
+	// like ServiceFailures
+	ServiceEstimatedFailures map[string]float64
+
+	// FailureKinds counts failed traces by Failure* kind
+	FailureKinds map[string]int
+
+	// FirstFailure is the start (Unix nanoseconds) of each service's earliest
+	// failed span. FailedCalls counts, by caller then callee, the traces in
+	// which a failed span's parent in another service failed too. Both need
+	// span detail, so they are empty when only trace summaries are returned.
+	FirstFailure map[string]int64
+	FailedCalls  map[string]map[string]int
+
+	// For OTLP results, the services of each trace seen and the failed
+	// spans, so that MergeTraces can merge them by trace
+	otlpTraces map[string]map[string]bool
+	otlpFailed []otlpSpan
+}
+
+// FailuresFor returns the failed traces attributed to service, falling back
+// to the global count when no trace could be attributed
+func (r TraceResult) FailuresFor(service string) int {
+	if len(r.ServiceFailures) == 0 {
+		return r.Failures
+	}
+	return r.ServiceFailures[service]
+}
+
+// EstimatedFailuresFor returns the estimated failures before sampling
+// attributed to service, falling back like FailuresFor
+func (r TraceResult) EstimatedFailuresFor(service string) float64 {
+	if len(r.ServiceFailures) == 0 {
+		return r.EstimatedFailures
+	}
+	return r.ServiceEstimatedFailures[service]
+}
+
+// DegradedFor returns the degraded traces of service, counting those
+// without a root service for every service
+func (r TraceResult) DegradedFor(service string) int {
+	n := r.Degraded[""]
+	if service != "" {
+		n += r.Degraded[service]
+	}
+	return n
 }
 
-func AnalyzeTraces(raw string) TraceResult {
-	var parsed map[string]any
-	_ = json.Unmarshal([]byte(raw), &parsed)
+// FailureRate is the percentage (0-100) of the service's traces that failed,
+// or 0 when none were examined or their number isn't known. Without
+// per-service totals or attribution, it is taken over every trace.
+func (r TraceResult) FailureRate(service string) float64 {
+	failures, total := r.FailuresFor(service), r.Total
+	if len(r.ServiceTotals) > 0 && len(r.ServiceFailures) > 0 {
+		total = max(r.ServiceTotals[service], failures)
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(failures) * 100 / float64(total)
+}
 
-	traces := parsed["traces"].([]any)
+func AnalyzeTraces(raw string) (TraceResult, error) {
+	parsed, err := parseResponse(raw)
+	if err != nil {
+		return TraceResult{}, err
+	}
+
+	traces, ok := parsed["traces"].([]any)
+	if !ok {
+		return TraceResult{}, invalid("missing traces")
+	}
 
-	failures := 0
-	var events []TraceEvent
+	result := TraceResult{
+		Total:                    len(traces),
+		ServiceTotals:            make(map[string]int),
+		ServiceFailures:          make(map[string]int),
+		ServiceEstimatedFailures: make(map[string]float64),
+		FailureKinds:             make(map[string]int),
+	}
 
 	for _, t := range traces {
-		trace := t.(map[string]any)
-		status := trace["status"].(string)
-		time := trace["startTimeUnixNano"].(string)
+		trace, _ := t.(map[string]any)
+		status, ok := trace["status"].(string)
+		if !ok {
+			return TraceResult{}, invalid("trace without status")
+		}
+		time := normalizeTime(scalarString(trace["startTimeUnixNano"]))
+		traceID, _ := trace["traceID"].(string)
+
+		for _, service := range traceServices(trace) {
+			result.ServiceTotals[service]++
+		}
 
-		if status != "ok" {
-			failures++
-			events = append(events, TraceEvent{Time: time, Message: "Trace failure"})
+		outcome := traceOutcome(status)
+		if outcome == StatusDegraded {
+			if result.Degraded == nil {
+				result.Degraded = make(map[string]int)
+			}
+			root, _ := trace["rootServiceName"].(string)
+			result.Degraded[root]++
+		}
+		if outcome == StatusFailed {
+			estimated := 1 / traceSamplingRate(trace)
+			result.Failures++
+			result.EstimatedFailures += estimated
+			result.Events = append(result.Events, TraceEvent{Time: time, Message: "Trace failure", TraceID: traceID})
+			for _, service := range failingServices(trace) {
+				result.ServiceFailures[service]++
+				result.ServiceEstimatedFailures[service] += estimated
+			}
+			result.FailureKinds[traceFailureKind(trace, status)]++
+			result.recordCalls(tempoCallSpans(trace))
 		}
 	}
 
-	return TraceResult{
-		Failures: failures,
-		Events:   events,
+	return result, nil
+}
+
+// traceOutcome is what a trace's status means. Statuses other than ok and
+// error are mapped by SetStatusFallbacks; unmapped ones are failures, as a
+// trace that didn't report ok can't be assumed to have succeeded.
+func traceOutcome(status string) string {
+	switch status {
+	case "ok":
+		return StatusOK
+	case "error":
+		return StatusFailed
+	}
+	if outcome, ok := mappedStatus(status); ok {
+		return outcome
+	}
+	return StatusFailed
+}
+
+// ClassifyFailure picks the kind of a failure from its HTTP status code (0 if
+// unknown) and status message. Failures with nothing more specific to go on
+// are server errors.
+func ClassifyFailure(httpStatus int, message string) string {
+	lower := strings.ToLower(message)
+	switch {
+	case httpStatus == 408 || httpStatus == 504 ||
+		strings.Contains(lower, "timeout") || strings.Contains(lower, "timed out") || strings.Contains(lower, "deadline exceeded"):
+		return FailureTimeout
+	case httpStatus == 499 || strings.Contains(lower, "cancel"):
+		return FailureCancelled
+	case httpStatus >= 400 && httpStatus < 500:
+		return FailureClientError
+	}
+	return FailureServerError
+}
+
+// traceFailureKind classifies a failed trace by its first error span that
+// carries an HTTP status code or status message, falling back to the trace's
+// own status
+func traceFailureKind(trace map[string]any, status string) string {
+	for _, span := range traceSpans(trace) {
+		if spanAttribute(span, "status") != "error" {
+			continue
+		}
+		code := parseInt(spanAttribute(span, "http.status_code"))
+		if code == 0 {
+			code = parseInt(spanAttribute(span, "http.response.status_code"))
+		}
+		message := spanAttribute(span, "status.message")
+		if code != 0 || message != "" {
+			return ClassifyFailure(code, message)
+		}
+	}
+	return ClassifyFailure(0, status)
+}
+
+// failingServices returns the distinct service.name of the trace's error
+// spans, or the root service if the search returned no spans
+func failingServices(trace map[string]any) []string {
+	seen := make(map[string]bool)
+	var services []string
+	for _, span := range traceSpans(trace) {
+		service := spanAttribute(span, "service.name")
+		if service == "" || spanAttribute(span, "status") != "error" || seen[service] {
+			continue
+		}
+		seen[service] = true
+		services = append(services, service)
+	}
+
+	if len(services) == 0 {
+		if root, _ := trace["rootServiceName"].(string); root != "" {
+			services = append(services, root)
+		}
+	}
+	return services
+}
+
+// traceServices returns the services a Tempo search result passed through:
+// its root service and those of its returned spans
+func traceServices(trace map[string]any) []string {
+	seen := make(map[string]bool)
+	var services []string
+	add := func(service string) {
+		if service != "" && !seen[service] {
+			seen[service] = true
+			services = append(services, service)
+		}
+	}
+	root, _ := trace["rootServiceName"].(string)
+	add(root)
+	for _, span := range traceSpans(trace) {
+		add(spanAttribute(span, "service.name"))
+	}
+	return services
+}
+
+// traceSpans returns the spans of a Tempo search result, from its spanSet
+// or spanSets
+func traceSpans(trace map[string]any) []map[string]any {
+	var spanSets []any
+	if set, ok := trace["spanSet"]; ok {
+		spanSets = append(spanSets, set)
+	}
+	if sets, ok := trace["spanSets"].([]any); ok {
+		spanSets = append(spanSets, sets...)
+	}
+
+	var spans []map[string]any
+	for _, s := range spanSets {
+		set, _ := s.(map[string]any)
+		list, _ := set["spans"].([]any)
+		for _, sp := range list {
+			if span, ok := sp.(map[string]any); ok {
+				spans = append(spans, span)
+			}
+		}
+	}
+	return spans
+}
+
+// spanAttribute reads a string or integer attribute in OTLP form, e.g.
+// [{"key":"service.name","value":{"stringValue":"checkout"}}] or
+// [{"key":"http.status_code","value":{"intValue":"503"}}]
+func spanAttribute(span map[string]any, key string) string {
+	attributes, _ := span["attributes"].([]any)
+	for _, a := range attributes {
+		attr, _ := a.(map[string]any)
+		if attr["key"] != key {
+			continue
+		}
+		value, _ := attr["value"].(map[string]any)
+		if s, ok := value["stringValue"].(string); ok {
+			return s
+		}
+		if d, ok := value["doubleValue"]; ok {
+			return scalarString(d)
+		}
+		return scalarString(value["intValue"])
 	}
+	return ""
 }
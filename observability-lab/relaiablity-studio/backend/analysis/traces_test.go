@@ -0,0 +1,164 @@
+package analysis
+
+import "testing"
+
+func TestAnalyzeTracesAttributesFailuresToServices(t *testing.T) {
+	raw := `{"traces":[
+		{"traceID":"a1","rootServiceName":"frontend","startTimeUnixNano":"1705284839000000000","status":"error",
+		 "spanSets":[{"spans":[
+			{"spanID":"1","attributes":[{"key":"service.name","value":{"stringValue":"checkout"}},{"key":"status","value":{"stringValue":"error"}}]},
+			{"spanID":"2","attributes":[{"key":"service.name","value":{"stringValue":"payments"}},{"key":"status","value":{"stringValue":"error"}}]},
+			{"spanID":"3","attributes":[{"key":"service.name","value":{"stringValue":"checkout"}},{"key":"status","value":{"stringValue":"error"}}]}
+		 ]}]},
+		{"traceID":"b2","rootServiceName":"frontend","startTimeUnixNano":"1705284840000000000","status":"error",
+		 "spanSet":{"spans":[
+			{"spanID":"4","attributes":[{"key":"service.name","value":{"stringValue":"checkout"}},{"key":"status","value":{"stringValue":"error"}}]},
+			{"spanID":"5","attributes":[{"key":"service.name","value":{"stringValue":"frontend"}},{"key":"status","value":{"stringValue":"ok"}}]}
+		 ]}},
+		{"traceID":"c3","rootServiceName":"inventory","startTimeUnixNano":"1705284841000000000","status":"error"},
+		{"traceID":"d4","rootServiceName":"frontend","startTimeUnixNano":"1705284842000000000","status":"ok"}
+	]}`
+
+	res, err := AnalyzeTraces(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if res.Failures != 3 {
+		t.Errorf("Expected 3 failed traces, got %d", res.Failures)
+	}
+	// checkout is in 2 of the 4 traces, both failed; inventory in 1 of them
+	if got := res.FailureRate("checkout"); res.Total != 4 || got != 100 {
+		t.Errorf("Expected 4 traces with 100%% of checkout's failing, got %d and %v%%", res.Total, got)
+	}
+	if got := res.FailureRate("frontend"); got != 0 {
+		t.Errorf("Expected none of frontend's traces failing, got %v%%", got)
+	}
+	if got := res.ServiceTotals["frontend"]; got != 3 {
+		t.Errorf("Expected 3 traces through frontend, got %d", got)
+	}
+	expected := map[string]int{"checkout": 2, "payments": 1, "inventory": 1, "frontend": 0}
+	for service, want := range expected {
+		if got := res.FailuresFor(service); got != want {
+			t.Errorf("Expected %d failures for %s, got %d", want, service, got)
+		}
+	}
+}
+
+func TestTraceFailuresForWithoutAttribution(t *testing.T) {
+	res := TraceResult{Failures: 4}
+	if got := res.FailuresFor("checkout"); got != 4 {
+		t.Errorf("Expected the global count without attribution, got %d", got)
+	}
+}
+
+func TestAnalyzeTracesClassifiesFailures(t *testing.T) {
+	raw := `{"traces":[
+		{"traceID":"a1","status":"error","spanSet":{"spans":[
+			{"attributes":[{"key":"status","value":{"stringValue":"error"}},{"key":"http.status_code","value":{"intValue":"404"}}]}
+		]}},
+		{"traceID":"b2","status":"error","spanSet":{"spans":[
+			{"attributes":[{"key":"status","value":{"stringValue":"error"}},{"key":"http.status_code","value":{"intValue":503}}]}
+		]}},
+		{"traceID":"c3","status":"error","spanSet":{"spans":[
+			{"attributes":[{"key":"status","value":{"stringValue":"error"}},{"key":"http.status_code","value":{"intValue":"504"}}]}
+		]}},
+		{"traceID":"d4","status":"error","spanSet":{"spans":[
+			{"attributes":[{"key":"status","value":{"stringValue":"error"}},{"key":"status.message","value":{"stringValue":"context canceled"}}]}
+		]}},
+		{"traceID":"e5","status":"error","spanSet":{"spans":[
+			{"attributes":[{"key":"status","value":{"stringValue":"error"}},{"key":"http.response.status_code","value":{"stringValue":"400"}}]}
+		]}},
+		{"traceID":"f6","status":"error"},
+		{"traceID":"g7","status":"ok"}
+	]}`
+
+	res, err := AnalyzeTraces(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]int{
+		FailureClientError: 2,
+		FailureServerError: 2,
+		FailureTimeout:     1,
+		FailureCancelled:   1,
+	}
+	for kind, want := range expected {
+		if got := res.FailureKinds[kind]; got != want {
+			t.Errorf("Expected %d %s failures, got %d", want, kind, got)
+		}
+	}
+	if len(res.FailureKinds) != len(expected) {
+		t.Errorf("Expected only %d failure kinds, got %v", len(expected), res.FailureKinds)
+	}
+}
+
+func TestAnalyzeTracesEstimatesSampledFailures(t *testing.T) {
+	SetTraceSamplingRate(0.01)
+	defer SetTraceSamplingRate(1)
+
+	raw := `{"traces":[
+		{"traceID":"a1","rootServiceName":"checkout","status":"error"},
+		{"traceID":"b2","rootServiceName":"search","status":"error"},
+		{"traceID":"c3","rootServiceName":"checkout","status":"error",
+		 "spanSet":{"spans":[
+			{"spanID":"1","attributes":[{"key":"sampling.rate","value":{"doubleValue":0.5}},{"key":"status","value":{"stringValue":"error"}}]}
+		 ]}},
+		{"traceID":"d4","rootServiceName":"checkout","status":"ok"}
+	]}`
+
+	res, err := AnalyzeTraces(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.Failures != 3 {
+		t.Errorf("Expected 3 sampled failures, got %d", res.Failures)
+	}
+	// Two traces at the configured 1% and one recording its own 50%
+	if res.EstimatedFailures != 202 {
+		t.Errorf("Expected an estimate of 202 failures, got %v", res.EstimatedFailures)
+	}
+	if res.EstimatedFailuresFor("checkout") != 102 || res.EstimatedFailuresFor("search") != 100 {
+		t.Errorf("Expected estimates of 102 for checkout and 100 for search, got %v and %v",
+			res.EstimatedFailuresFor("checkout"), res.EstimatedFailuresFor("search"))
+	}
+
+	SetTraceSamplingRate(0)
+	if res, _ := AnalyzeTraces(`{"traces":[{"traceID":"a1","status":"error"}]}`); res.EstimatedFailures != 1 {
+		t.Errorf("Expected an unsampled estimate to equal the failures, got %v", res.EstimatedFailures)
+	}
+}
+
+func TestAnalyzeTracesUnrecognizedStatus(t *testing.T) {
+	raw := `{"traces":[
+		{"traceID":"a1","rootServiceName":"checkout","status":"unset"},
+		{"traceID":"b2","rootServiceName":"checkout","status":"error"},
+		{"traceID":"c3","rootServiceName":"checkout","status":"ok"}
+	]}`
+
+	testCases := []struct {
+		name      string
+		fallbacks map[string]string
+		failures  int
+		degraded  int
+	}{
+		{"default", nil, 2, 0},
+		{"mapped to degraded", map[string]string{"unset": StatusDegraded}, 1, 1},
+		{"mapped to ok", map[string]string{FallbackWildcard: StatusOK}, 1, 0},
+	}
+	defer SetStatusFallbacks(nil)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetStatusFallbacks(tc.fallbacks)
+			res, err := AnalyzeTraces(raw)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if res.Failures != tc.failures || res.DegradedFor("checkout") != tc.degraded || len(res.Events) != tc.failures {
+				t.Errorf("Expected %d failed and %d degraded traces, got %d and %d", tc.failures, tc.degraded, res.Failures, res.DegradedFor("checkout"))
+			}
+		})
+	}
+}
@@ -9,11 +9,14 @@ import (
 	"net/url"
 	"strconv"
 	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/config"
 )
 
 type LokiClient struct {
 	baseURL    string
 	httpClient *http.Client
+	Templates  config.QueryTemplates // Per-service query overrides
 }
 
 type LokiQueryResponse struct {
@@ -128,7 +131,10 @@ func (l *LokiClient) QueryLogs(ctx context.Context, query string, start, end tim
 
 // GetErrorLogs retrieves error logs for a service
 func (l *LokiClient) GetErrorLogs(ctx context.Context, service string, since time.Time, limit int) ([]LogEntry, error) {
-	query := fmt.Sprintf(`{app="%s"} |= "error" or |= "ERROR" or |= "exception" or |~ "(?i)error"`, service)
+	queries, err := l.Templates.For(service)
+	if err != nil {
+		return nil, err
+	}
 
 	end := time.Now()
 	start := since
@@ -136,7 +142,7 @@ func (l *LokiClient) GetErrorLogs(ctx context.Context, service string, since tim
 		start = end.Add(-15 * time.Minute)
 	}
 
-	return l.QueryLogs(ctx, query, start, end, limit)
+	return l.QueryLogs(ctx, queries.Log, start, end, limit)
 }
 
 // GetServiceLogs retrieves all logs for a service
@@ -8,11 +8,14 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/config"
 )
 
 type PrometheusClient struct {
 	BaseURL    string
 	HTTPClient *http.Client
+	Templates  config.QueryTemplates // Per-service query overrides
 }
 
 type PrometheusResponse struct {
@@ -112,13 +115,12 @@ func (c *PrometheusClient) QueryRange(ctx context.Context, query string, start,
 // Helper methods for common queries
 
 func (c *PrometheusClient) GetErrorRate(ctx context.Context, service string) (float64, error) {
-	query := fmt.Sprintf(`
-		rate(http_requests_total{service="%s",status=~"5.."}[5m]) 
-		/ 
-		rate(http_requests_total{service="%s"}[5m]) * 100
-	`, service, service)
+	queries, err := c.Templates.For(service)
+	if err != nil {
+		return 0, err
+	}
 
-	resp, err := c.Query(ctx, query, time.Time{})
+	resp, err := c.Query(ctx, queries.Error, time.Time{})
 	if err != nil {
 		return 0, err
 	}
@@ -143,13 +145,12 @@ func (c *PrometheusClient) GetErrorRate(ctx context.Context, service string) (fl
 }
 
 func (c *PrometheusClient) GetLatencyP95(ctx context.Context, service string) (float64, error) {
-	query := fmt.Sprintf(`
-		histogram_quantile(0.95, 
-			rate(http_request_duration_seconds_bucket{service="%s"}[5m])
-		)
-	`, service)
+	queries, err := c.Templates.For(service)
+	if err != nil {
+		return 0, err
+	}
 
-	resp, err := c.Query(ctx, query, time.Time{})
+	resp, err := c.Query(ctx, queries.Latency, time.Time{})
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BurnRateWindow is a multi-window burn-rate alert: it fires while the error
+// budget burns faster than Threshold over both the Short and the Long window.
+// The long window makes the alert significant, the short one lets it reset
+// soon after the burn stops.
+type BurnRateWindow struct {
+	Short     time.Duration
+	Long      time.Duration
+	Threshold float64
+}
+
+// DefaultBurnRateWindows are the window pairs recommended by the SRE
+// workbook for a 30 day SLO: two fast-burn pairs that page and two slow-burn
+// pairs that open tickets
+var DefaultBurnRateWindows = []BurnRateWindow{
+	{Short: 5 * time.Minute, Long: time.Hour, Threshold: 14.4},
+	{Short: 30 * time.Minute, Long: 6 * time.Hour, Threshold: 6},
+	{Short: 2 * time.Hour, Long: 24 * time.Hour, Threshold: 3},
+	{Short: 6 * time.Hour, Long: 72 * time.Hour, Threshold: 1},
+}
+
+// Validate rejects pairs whose short window isn't shorter than the long one,
+// or without a positive threshold
+func (w BurnRateWindow) Validate() error {
+	if w.Short <= 0 || w.Long <= w.Short {
+		return fmt.Errorf("burn rate window %s/%s: short must be positive and shorter than long", w.Short, w.Long)
+	}
+	if w.Threshold <= 0 {
+		return fmt.Errorf("burn rate window %s/%s: threshold must be positive", w.Short, w.Long)
+	}
+	return nil
+}
+
+// ParseBurnRateWindow parses a pair written short/long:threshold, e.g. 5m/1h:14.4
+func ParseBurnRateWindow(s string) (BurnRateWindow, error) {
+	windows, threshold, ok := strings.Cut(strings.TrimSpace(s), ":")
+	short, long, ok2 := strings.Cut(windows, "/")
+	if !ok || !ok2 {
+		return BurnRateWindow{}, fmt.Errorf("burn rate window %q: expected short/long:threshold", s)
+	}
+	var w BurnRateWindow
+	var err error
+	if w.Short, err = time.ParseDuration(short); err != nil {
+		return BurnRateWindow{}, fmt.Errorf("burn rate window %q: %v", s, err)
+	}
+	if w.Long, err = time.ParseDuration(long); err != nil {
+		return BurnRateWindow{}, fmt.Errorf("burn rate window %q: %v", s, err)
+	}
+	if w.Threshold, err = strconv.ParseFloat(threshold, 64); err != nil {
+		return BurnRateWindow{}, fmt.Errorf("burn rate window %q: %v", s, err)
+	}
+	return w, w.Validate()
+}
@@ -1,17 +1,599 @@
 package config
 
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMetricStep is the resolution of Prometheus range queries when
+// METRIC_STEP isn't set
+const DefaultMetricStep = 15 * time.Second
+
 type Config struct {
-	PrometheusURL string
-	LokiURL       string
-	TempoURL      string
-	KubeConfig    string
+	PrometheusURL  string
+	LokiURL        string
+	TempoURL       string
+	KubeConfig     string
+	KubeClusters   string // Comma-separated "name" or "name=/path/to/kubeconfig" entries
+	QueryTemplates QueryTemplates
+	Timezone       string // Default IANA zone for timeline timestamps
+
+	// Optional HTTP proxies per upstream; empty uses HTTP_PROXY/HTTPS_PROXY
+	PrometheusProxy string
+	LokiProxy       string
+	TempoProxy      string
+
+	// UpstreamConcurrency caps simultaneous requests to Prometheus/Loki/Tempo
+	UpstreamConcurrency int
+	// UpstreamWarnBytes logs a warning when a single upstream response exceeds it
+	UpstreamWarnBytes int
+	// NoDataSeverity is reported for services with no metrics or logs at all
+	NoDataSeverity string
+	// LatencyWarning and LatencyCritical grade a service by its p95 latency
+	// even when its error rate is low (0 disables a level)
+	LatencyWarning  time.Duration
+	LatencyCritical time.Duration
+	// IncidentIDScheme names the incident ID generator ("timestamp" or "uuid")
+	IncidentIDScheme string
+	// CorrelationStrategy names the strategy that grades incidents ("default")
+	CorrelationStrategy string
+	// StreamClientBuffer is how many updates a slow SSE client may fall behind
+	StreamClientBuffer int
+	// StreamMaxClients caps concurrent SSE clients (0 allows any number)
+	StreamMaxClients int
+	// UserAgent overrides the User-Agent sent to Prometheus/Loki/Tempo
+	UserAgent string
+	// MetricStep is the resolution of Prometheus range queries
+	MetricStep time.Duration
+	// QueryTimeout bounds Prometheus-side evaluation of each metric query
+	QueryTimeout time.Duration
+	// MaxMessageLength truncates longer log messages in incidents
+	MaxMessageLength int
+	// LogSampleThreshold is the line count above which log analysis samples;
+	// LogSampleEvery is the sampling interval
+	LogSampleThreshold int
+	LogSampleEvery     int
+	// MaxLogVolume rejects incidents whose log query Loki estimates matches
+	// more lines than this (0, the default, disables the check)
+	MaxLogVolume int
+	// LogCorrelationFields are the JSON log fields attached to log events as labels
+	LogCorrelationFields []string
+	// RootCauseKeywords make a log line an error and root cause candidate
+	// (case-insensitive; empty uses the analysis defaults)
+	RootCauseKeywords []string
+	// IncidentRetention prunes stored incidents older than this (0 keeps them)
+	IncidentRetention time.Duration
+	// IncidentRetentionCount keeps only the newest N incidents per service (0 keeps all)
+	IncidentRetentionCount int
+	// IncidentCleanupInterval is how often the retention policy is applied
+	IncidentCleanupInterval time.Duration
+	// NotifyWebhookURL receives critical incidents as JSON; empty disables paging
+	NotifyWebhookURL string
+	// NotifyTemplate is a text/template formatting pages from an incident,
+	// from NOTIFY_TEMPLATE or the file NOTIFY_TEMPLATE_FILE; empty uses the default
+	NotifyTemplate string
+	// MinPageConfidence is the confidence (0-1) a critical incident needs to page
+	MinPageConfidence float64
+	// MaintenanceWindows suppress notifications for their services while active
+	MaintenanceWindows MaintenanceWindows
+	// AlertDedupWindow ignores repeats of a firing alert for this long
+	AlertDedupWindow time.Duration
+	// CacheMaxAge is the Cache-Control max-age of cacheable GET endpoints;
+	// CacheMaxAgeRoutes overrides it per route template
+	CacheMaxAge       time.Duration
+	CacheMaxAgeRoutes map[string]time.Duration
+	// SLOObjectives supplies objectives for SLOs stored without a target
+	SLOObjectives SLOObjectives
+	// TraceSource is where incidents read traces from: "tempo" searches Tempo,
+	// "otlp" uses exports pushed to /v1/traces within OTLPTraceWindow
+	TraceSource     string
+	OTLPTraceWindow time.Duration
+	// BulkConcurrency is how many incidents the bulk endpoint builds in
+	// parallel by default; MaxBulkConcurrency caps ?max_concurrency=
+	BulkConcurrency    int
+	MaxBulkConcurrency int
+	// StartupCheck probes the upstreams once at startup: "off", "warn" logs
+	// the results, "fail" also exits if a RequiredUpstreams entry is unreachable
+	StartupCheck      string
+	RequiredUpstreams []string
+	// RootCauseWindow is how close trace and pod failures must be to an
+	// error log to corroborate it as the root cause
+	RootCauseWindow time.Duration
+	// PodNotReadyThreshold is how long a running pod may be not Ready before
+	// it counts as degraded
+	PodNotReadyThreshold time.Duration
+	// FingerprintFields are the incident parts that identify a recurring
+	// issue: any of "service", "root_cause" and "impact"
+	FingerprintFields []string
+	// RecoveryEvaluations is how many consecutive healthy evaluations a
+	// warning or critical service needs before it is reported healthy; it is
+	// "recovering" in between (1 or less reports healthy immediately)
+	RecoveryEvaluations int
+	// BasicAuthUser enables basic auth on the API instead of JWT; the
+	// password is checked against BasicAuthPasswordHash (bcrypt) if set,
+	// otherwise BasicAuthPassword
+	BasicAuthUser         string
+	BasicAuthPassword     string
+	BasicAuthPasswordHash string
+	// NotifyWarnings also notifies for warning incidents, except during
+	// QuietHours, when QuietHoursMode drops them or queues them until after
+	NotifyWarnings bool
+	QuietHours     QuietHoursList
+	QuietHoursMode string
+	// IncidentWebhookURL receives notified incidents as plain JSON, sent with
+	// IncidentWebhookHeaders and signed with IncidentWebhookSecret if set
+	IncidentWebhookURL     string
+	IncidentWebhookHeaders map[string]string
+	IncidentWebhookSecret  string
+	// NotifyRetries is how often a failed webhook delivery is retried
+	NotifyRetries int
+	// K8sNamespaces scopes the Kubernetes queries, per service if overridden
+	K8sNamespaces K8sNamespaces
+	// TraceSamplingRate is the probability (0-1] traces are sampled with,
+	// used to estimate failures before sampling
+	TraceSamplingRate float64
+	// SLOMinRequests is the traffic an SLO's window needs before it can be
+	// degraded (0 disables the check), counted by SLOTrafficQuery
+	SLOMinRequests  float64
+	SLOTrafficQuery string
+	// OOMLinkWindow is how soon after an OOMKill a latency spike is linked to it
+	OOMLinkWindow time.Duration
+	// DebugEndpoints enables /api/debug/source, which returns raw upstream
+	// data, and /api/debug/config
+	DebugEndpoints bool
+	// SourceDeadline is how long an incident build waits for each source
+	// before leaving it out as timed out (0 waits indefinitely);
+	// SourceDeadlines overrides it per source
+	SourceDeadline  time.Duration
+	SourceDeadlines map[string]time.Duration
+	// MetricLabelAllowlist are the labels attached to rl_* metrics;
+	// MetricHashedLabels are attached with their values hashed
+	MetricLabelAllowlist []string
+	MetricHashedLabels   []string
+	// TraceFailureWarning and TraceFailureCritical are the percentages of
+	// failed traces that grade a service warning and critical (0 disables)
+	TraceFailureWarning  float64
+	TraceFailureCritical float64
+	// CommonRootCauseMinServices is how many services in a bulk build must
+	// share a root cause for it to be collapsed into a common one. Collapsing
+	// clears root_cause on those incidents, so it is off (0) by default.
+	CommonRootCauseMinServices int
+	// RangeConcurrency is how many windows an incident arc analyzes at once
+	RangeConcurrency int
+	// JSONPrecision is the decimals error rates, burn rates and budgets are
+	// rounded to in responses (negative keeps full precision)
+	JSONPrecision int
+	// NewIncidentWindow is how long after it was first seen an incident is
+	// reported as is_new
+	NewIncidentWindow time.Duration
+	// LogStructuredMetadata surfaces the structured metadata of Loki entries
+	// on log events
+	LogStructuredMetadata bool
+	// NotifySeverityFloor is the lowest severity any notifier is sent,
+	// "warning" or "critical"; empty follows NotifyWarnings
+	NotifySeverityFloor string
+	// AnchorBefore and AnchorAfter window the timeline around the earliest
+	// high-severity signal (0 leaves that side unbounded)
+	AnchorBefore time.Duration
+	AnchorAfter  time.Duration
+	// MetricsBackend is how metrics are queried: "query" uses the HTTP
+	// query API, "remote_read" the remote-read protocol
+	MetricsBackend string
+	// LogDedup collapses log lines with the same message into the first of
+	// them, with a count
+	LogDedup bool
+	// SLOBurnRateWindows are the multi-window burn-rate alerts evaluated for
+	// every SLO
+	SLOBurnRateWindows []BurnRateWindow
+	// GrafanaURL is the Grafana base URL incidents link dashboards on;
+	// GrafanaDashboards maps a link name to a dashboard UID
+	GrafanaURL        string
+	GrafanaDashboards map[string]string
+	// ServiceDependencies maps each service to the downstream services its
+	// failures propagate to
+	ServiceDependencies map[string][]string
+	// StatusFallbacks maps upstream statuses the analyzers don't recognize
+	// (pod phase Unknown, trace status unset) to ok, degraded or failed
+	StatusFallbacks map[string]string
+	// LogStreamLimit, when positive, streams windowed log queries of up to
+	// this many lines through a token-by-token decoder instead of reading the
+	// whole Loki response into memory
+	LogStreamLimit int
+	// ServiceTiers maps a service to its business tier (0 most critical),
+	// which raises or lowers the priority of its incidents
+	ServiceTiers map[string]int
+	// Server timeouts: ServerReadHeaderTimeout bounds reading request
+	// headers, ServerReadTimeout the whole request, ServerWriteTimeout the
+	// response (each event, for the SSE stream) and ServerIdleTimeout how
+	// long a keep-alive connection waits for its next request. 0 disables a
+	// timeout, as in net/http: a ReadHeaderTimeout or IdleTimeout of 0 falls
+	// back to ReadTimeout.
+	ServerReadHeaderTimeout time.Duration
+	ServerReadTimeout       time.Duration
+	ServerWriteTimeout      time.Duration
+	ServerIdleTimeout       time.Duration
 }
 
 func Load() Config {
-	return Config{
-		PrometheusURL: "http://localhost:9090",
-		LokiURL:       "http://localhost:3100",
-		TempoURL:      "http://localhost:3200",
+	cfg := Config{
+		PrometheusURL: getEnv("PROMETHEUS_URL", "http://prometheus:9090"),
+		LokiURL:       getEnv("LOKI_URL", "http://loki:3100"),
+		TempoURL:      getEnv("TEMPO_URL", "http://tempo:3200"),
+		Timezone:      getEnv("TIMELINE_TZ", "UTC"),
+		KubeConfig:    getEnv("KUBE_CONFIG", ""),
+		KubeClusters:  getEnv("KUBE_CLUSTERS", ""),
+
+		PrometheusProxy: getEnv("PROMETHEUS_PROXY", ""),
+		LokiProxy:       getEnv("LOKI_PROXY", ""),
+		TempoProxy:      getEnv("TEMPO_PROXY", ""),
+
+		UpstreamConcurrency: getEnvInt("UPSTREAM_CONCURRENCY", 20),
+		UpstreamWarnBytes:   getEnvInt("UPSTREAM_WARN_BYTES", 10<<20),
+		NoDataSeverity:      getEnv("NO_DATA_SEVERITY", "no_data"),
+		LatencyWarning:      getEnvDuration("LATENCY_WARNING", time.Second),
+		LatencyCritical:     getEnvDuration("LATENCY_CRITICAL", 5*time.Second),
+		IncidentIDScheme:    getEnv("INCIDENT_ID_SCHEME", "timestamp"),
+		CorrelationStrategy: getEnv("CORRELATION_STRATEGY", "default"),
+		StreamClientBuffer:  getEnvInt("SSE_CLIENT_BUFFER", 16),
+		StreamMaxClients:    getEnvInt("SSE_MAX_CLIENTS", 1000),
+		UserAgent:           getEnv("UPSTREAM_USER_AGENT", ""),
+		MetricStep:          getEnvDuration("METRIC_STEP", DefaultMetricStep),
+		QueryTimeout:        getEnvDuration("PROMETHEUS_QUERY_TIMEOUT", 25*time.Second),
+		MaxMessageLength:    getEnvInt("MAX_MESSAGE_LENGTH", 2000),
+		LogSampleThreshold:  getEnvInt("LOG_SAMPLE_THRESHOLD", 1000),
+		LogSampleEvery:      getEnvInt("LOG_SAMPLE_EVERY", 10),
+		MaxLogVolume:        getEnvInt("MAX_LOG_VOLUME", 0),
+
+		IncidentRetention:       getEnvDuration("INCIDENT_RETENTION", 7*24*time.Hour),
+		IncidentRetentionCount:  getEnvInt("INCIDENT_RETENTION_COUNT", 500),
+		IncidentCleanupInterval: getEnvDuration("INCIDENT_CLEANUP_INTERVAL", 5*time.Minute),
+		NotifyWebhookURL:        getEnv("NOTIFY_WEBHOOK_URL", ""),
+		MinPageConfidence:       getEnvFloat("MIN_PAGE_CONFIDENCE", 0),
+		AlertDedupWindow:        getEnvDuration("ALERT_DEDUP_WINDOW", 15*time.Minute),
+		CacheMaxAge:             getEnvDuration("CACHE_MAX_AGE", 5*time.Second),
+		TraceSource:             getEnv("TRACE_SOURCE", "tempo"),
+		OTLPTraceWindow:         getEnvDuration("OTLP_TRACE_WINDOW", 15*time.Minute),
+		BulkConcurrency:         getEnvInt("BULK_CONCURRENCY", 4),
+		MaxBulkConcurrency:      getEnvInt("BULK_MAX_CONCURRENCY", 16),
+		StartupCheck:            getEnv("STARTUP_CHECK", "off"),
+		RequiredUpstreams:       strings.Split(getEnv("REQUIRED_UPSTREAMS", "prometheus"), ","),
+		RootCauseWindow:         getEnvDuration("ROOT_CAUSE_WINDOW", time.Minute),
+		PodNotReadyThreshold:    getEnvDuration("POD_NOT_READY_THRESHOLD", 2*time.Minute),
+		FingerprintFields:       strings.Split(getEnv("FINGERPRINT_FIELDS", "service,root_cause,impact"), ","),
+		RecoveryEvaluations:     getEnvInt("RECOVERY_EVALUATIONS", 3),
+		BasicAuthUser:           getEnv("BASIC_AUTH_USER", ""),
+		BasicAuthPassword:       getEnv("BASIC_AUTH_PASSWORD", ""),
+		BasicAuthPasswordHash:   getEnv("BASIC_AUTH_PASSWORD_HASH", ""),
+		NotifyWarnings:          getEnvBool("NOTIFY_WARNINGS", false),
+		QuietHoursMode:          getEnv("QUIET_HOURS_MODE", QuietDrop),
+		IncidentWebhookURL:      getEnv("INCIDENT_WEBHOOK_URL", ""),
+		IncidentWebhookSecret:   getEnv("INCIDENT_WEBHOOK_SECRET", ""),
+		NotifyRetries:           getEnvInt("NOTIFY_RETRIES", 2),
+		DebugEndpoints:          getEnvBool("DEBUG_ENDPOINTS", false),
+		TraceSamplingRate:       getEnvFloat("TRACE_SAMPLING_RATE", 1),
+		SLOMinRequests:          getEnvFloat("SLO_MIN_REQUESTS", 0),
+		OOMLinkWindow:           getEnvDuration("OOM_LINK_WINDOW", 5*time.Minute),
+		SLOTrafficQuery:         getEnv("SLO_TRAFFIC_QUERY", ""),
+		LogStructuredMetadata:   getEnvBool("LOG_STRUCTURED_METADATA", true),
+		SourceDeadline:          getEnvDuration("SOURCE_DEADLINE", 0),
+		TraceFailureWarning:     getEnvFloat("TRACE_FAILURE_WARNING", 0),
+		TraceFailureCritical:    getEnvFloat("TRACE_FAILURE_CRITICAL", 0),
+
+		CommonRootCauseMinServices: getEnvInt("COMMON_ROOT_CAUSE_MIN_SERVICES", 0),
+		RangeConcurrency:           getEnvInt("RANGE_CONCURRENCY", 4),
+		JSONPrecision:              getEnvInt("JSON_PRECISION", 3),
+		NewIncidentWindow:          getEnvDuration("NEW_INCIDENT_WINDOW", 15*time.Minute),
+		NotifySeverityFloor:        getEnv("NOTIFY_SEVERITY_FLOOR", ""),
+		AnchorBefore:               getEnvDuration("TIMELINE_ANCHOR_BEFORE", 0),
+		AnchorAfter:                getEnvDuration("TIMELINE_ANCHOR_AFTER", 0),
+		MetricsBackend:             getEnv("METRICS_BACKEND", "query"),
+		LogDedup:                   getEnvBool("LOG_DEDUP", false),
+		GrafanaURL:                 strings.TrimRight(getEnv("GRAFANA_URL", ""), "/"),
+		LogStreamLimit:             getEnvInt("LOG_STREAM_LIMIT", 0),
+		ServerReadHeaderTimeout:    getEnvTimeout("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+		ServerReadTimeout:          getEnvTimeout("SERVER_READ_TIMEOUT", 15*time.Second),
+		ServerWriteTimeout:         getEnvTimeout("SERVER_WRITE_TIMEOUT", 15*time.Second),
+		ServerIdleTimeout:          getEnvTimeout("SERVER_IDLE_TIMEOUT", 60*time.Second),
+	}
+
+	switch cfg.MetricsBackend {
+	case "query", "remote_read":
+	default:
+		log.Printf("Warning: Ignoring invalid METRICS_BACKEND=%q", cfg.MetricsBackend)
+		cfg.MetricsBackend = "query"
+	}
+
+	switch cfg.NotifySeverityFloor {
+	case "", "warning", "critical":
+	default:
+		log.Printf("Warning: Ignoring invalid NOTIFY_SEVERITY_FLOOR=%q", cfg.NotifySeverityFloor)
+		cfg.NotifySeverityFloor = ""
+	}
+
+	// QUERY_TEMPLATES holds a JSON object of service -> {error_query, latency_query, log_query}
+	if raw := os.Getenv("QUERY_TEMPLATES"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.QueryTemplates); err != nil {
+			log.Printf("Warning: Ignoring invalid QUERY_TEMPLATES: %v", err)
+		}
+	}
+
+	// MAINTENANCE_WINDOWS holds a JSON array of {service, start, end, recurring}
+	if raw := os.Getenv("MAINTENANCE_WINDOWS"); raw != "" {
+		var windows MaintenanceWindows
+		if err := json.Unmarshal([]byte(raw), &windows); err != nil {
+			log.Printf("Warning: Ignoring invalid MAINTENANCE_WINDOWS: %v", err)
+			// Unmarshal may have filled windows before failing
+			windows = nil
+		}
+		for _, w := range windows {
+			if err := w.Validate(); err != nil {
+				log.Printf("Warning: Ignoring %v", err)
+				continue
+			}
+			cfg.MaintenanceWindows = append(cfg.MaintenanceWindows, w)
+		}
+	}
+
+	// QUIET_HOURS holds a JSON array of {days, start, end, timezone}
+	if raw := os.Getenv("QUIET_HOURS"); raw != "" {
+		var quiet QuietHoursList
+		if err := json.Unmarshal([]byte(raw), &quiet); err != nil {
+			log.Printf("Warning: Ignoring invalid QUIET_HOURS: %v", err)
+			quiet = nil
+		}
+		for _, q := range quiet {
+			if err := q.Validate(); err != nil {
+				log.Printf("Warning: Ignoring %v", err)
+				continue
+			}
+			cfg.QuietHours = append(cfg.QuietHours, q)
+		}
+	}
+
+	// K8S_NAMESPACE is the default namespace; K8S_ALLOWED_NAMESPACES holds
+	// comma-separated namespaces and K8S_SERVICE_NAMESPACES service=namespace
+	// overrides, e.g. checkout=shop,payments=billing
+	cfg.K8sNamespaces.Default = getEnv("K8S_NAMESPACE", "")
+	if raw := os.Getenv("K8S_ALLOWED_NAMESPACES"); raw != "" {
+		for _, ns := range strings.Split(raw, ",") {
+			if ns = strings.TrimSpace(ns); ns != "" {
+				cfg.K8sNamespaces.Allowed = append(cfg.K8sNamespaces.Allowed, ns)
+			}
+		}
+	}
+	if raw := os.Getenv("K8S_SERVICE_NAMESPACES"); raw != "" {
+		cfg.K8sNamespaces.Services = make(map[string]string)
+		for _, entry := range strings.Split(raw, ",") {
+			service, ns, _ := strings.Cut(strings.TrimSpace(entry), "=")
+			if service == "" || ns == "" {
+				log.Printf("Warning: Ignoring invalid K8S_SERVICE_NAMESPACES entry %q", entry)
+				continue
+			}
+			cfg.K8sNamespaces.Services[service] = ns
+		}
+	}
+
+	// INCIDENT_WEBHOOK_HEADERS holds a JSON object of header -> value
+	if raw := os.Getenv("INCIDENT_WEBHOOK_HEADERS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.IncidentWebhookHeaders); err != nil {
+			log.Printf("Warning: Ignoring invalid INCIDENT_WEBHOOK_HEADERS: %v", err)
+		}
+	}
+
+	// LOG_CORRELATION_FIELDS holds comma-separated JSON log keys, e.g. trace_id,span_id,request_id
+	if raw := os.Getenv("LOG_CORRELATION_FIELDS"); raw != "" {
+		for _, field := range strings.Split(raw, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				cfg.LogCorrelationFields = append(cfg.LogCorrelationFields, field)
+			}
+		}
+	}
+
+	// ROOT_CAUSE_KEYWORDS holds comma-separated words, e.g. error,exception,failed,fehler
+	if raw := os.Getenv("ROOT_CAUSE_KEYWORDS"); raw != "" {
+		for _, keyword := range strings.Split(raw, ",") {
+			if keyword = strings.TrimSpace(keyword); keyword != "" {
+				cfg.RootCauseKeywords = append(cfg.RootCauseKeywords, keyword)
+			}
+		}
+	}
+
+	// CACHE_MAX_AGE_ROUTES holds comma-separated route=duration overrides
+	if raw := os.Getenv("CACHE_MAX_AGE_ROUTES"); raw != "" {
+		cfg.CacheMaxAgeRoutes = make(map[string]time.Duration)
+		for _, entry := range strings.Split(raw, ",") {
+			route, value, _ := strings.Cut(strings.TrimSpace(entry), "=")
+			d, err := time.ParseDuration(value)
+			if route == "" || err != nil || d < 0 {
+				log.Printf("Warning: Ignoring invalid CACHE_MAX_AGE_ROUTES entry %q", entry)
+				continue
+			}
+			cfg.CacheMaxAgeRoutes[route] = d
+		}
+	}
+
+	// STATUS_FALLBACKS holds comma-separated status=ok|degraded|failed pairs;
+	// * sets the fallback for every other unrecognized status
+	if raw := os.Getenv("STATUS_FALLBACKS"); raw != "" {
+		cfg.StatusFallbacks = make(map[string]string)
+		for _, entry := range strings.Split(raw, ",") {
+			status, outcome, _ := strings.Cut(strings.TrimSpace(entry), "=")
+			if status == "" || (outcome != "ok" && outcome != "degraded" && outcome != "failed") {
+				log.Printf("Warning: Ignoring invalid STATUS_FALLBACKS entry %q", entry)
+				continue
+			}
+			cfg.StatusFallbacks[status] = outcome
+		}
+	}
+
+	// SERVICE_TIERS holds comma-separated service=tier pairs
+	if raw := os.Getenv("SERVICE_TIERS"); raw != "" {
+		cfg.ServiceTiers = make(map[string]int)
+		for _, entry := range strings.Split(raw, ",") {
+			service, value, _ := strings.Cut(strings.TrimSpace(entry), "=")
+			tier, err := strconv.Atoi(value)
+			if service == "" || err != nil || tier < 0 {
+				log.Printf("Warning: Ignoring invalid SERVICE_TIERS entry %q", entry)
+				continue
+			}
+			cfg.ServiceTiers[service] = tier
+		}
+	}
+
+	// SERVICE_DEPENDENCIES holds a JSON object of service -> [downstream services]
+	if raw := os.Getenv("SERVICE_DEPENDENCIES"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.ServiceDependencies); err != nil {
+			log.Printf("Warning: Ignoring invalid SERVICE_DEPENDENCIES: %v", err)
+			cfg.ServiceDependencies = nil
+		}
 	}
 
+	// GRAFANA_DASHBOARDS holds comma-separated name=uid pairs
+	if raw := os.Getenv("GRAFANA_DASHBOARDS"); raw != "" {
+		cfg.GrafanaDashboards = make(map[string]string)
+		for _, entry := range strings.Split(raw, ",") {
+			name, uid, _ := strings.Cut(strings.TrimSpace(entry), "=")
+			if name == "" || uid == "" {
+				log.Printf("Warning: Ignoring invalid GRAFANA_DASHBOARDS entry %q", entry)
+				continue
+			}
+			cfg.GrafanaDashboards[name] = uid
+		}
+	}
+
+	// SLO_BURN_RATE_WINDOWS holds comma-separated short/long:threshold pairs,
+	// e.g. 5m/1h:14.4,30m/6h:6
+	if raw := os.Getenv("SLO_BURN_RATE_WINDOWS"); raw != "" {
+		for _, entry := range strings.Split(raw, ",") {
+			w, err := ParseBurnRateWindow(entry)
+			if err != nil {
+				log.Printf("Warning: Ignoring %v", err)
+				continue
+			}
+			cfg.SLOBurnRateWindows = append(cfg.SLOBurnRateWindows, w)
+		}
+	}
+
+	// METRIC_LABEL_ALLOWLIST and METRIC_HASHED_LABELS hold comma-separated label names
+	for _, label := range strings.Split(os.Getenv("METRIC_LABEL_ALLOWLIST"), ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			cfg.MetricLabelAllowlist = append(cfg.MetricLabelAllowlist, label)
+		}
+	}
+	for _, label := range strings.Split(os.Getenv("METRIC_HASHED_LABELS"), ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			cfg.MetricHashedLabels = append(cfg.MetricHashedLabels, label)
+		}
+	}
+
+	// SOURCE_DEADLINES holds comma-separated source=duration overrides, where 0
+	// waits for the source indefinitely
+	if raw := os.Getenv("SOURCE_DEADLINES"); raw != "" {
+		cfg.SourceDeadlines = make(map[string]time.Duration)
+		for _, entry := range strings.Split(raw, ",") {
+			source, value, _ := strings.Cut(strings.TrimSpace(entry), "=")
+			d, err := time.ParseDuration(value)
+			if source == "" || err != nil || d < 0 {
+				log.Printf("Warning: Ignoring invalid SOURCE_DEADLINES entry %q", entry)
+				continue
+			}
+			cfg.SourceDeadlines[source] = d
+		}
+	}
+
+	// SLO_DEFAULT_OBJECTIVES holds a JSON object of SLO type -> {target, threshold_ms};
+	// SLO_OBJECTIVES holds service -> SLO type -> {target, threshold_ms}
+	if raw := os.Getenv("SLO_DEFAULT_OBJECTIVES"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.SLOObjectives.Defaults); err != nil {
+			log.Printf("Warning: Ignoring invalid SLO_DEFAULT_OBJECTIVES: %v", err)
+		}
+	}
+	if raw := os.Getenv("SLO_OBJECTIVES"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.SLOObjectives.Overrides); err != nil {
+			log.Printf("Warning: Ignoring invalid SLO_OBJECTIVES: %v", err)
+		}
+	}
+
+	// NOTIFY_TEMPLATE_FILE takes precedence over an inline NOTIFY_TEMPLATE. A
+	// configured template that can't be read must not silently change pages.
+	cfg.NotifyTemplate = getEnv("NOTIFY_TEMPLATE", "")
+	if path := os.Getenv("NOTIFY_TEMPLATE_FILE"); path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read NOTIFY_TEMPLATE_FILE: %v", err)
+		}
+		cfg.NotifyTemplate = string(raw)
+	}
+
+	return cfg
+}
+
+// CacheMaxAgeFor returns the max-age for a route template
+func (c Config) CacheMaxAgeFor(route string) time.Duration {
+	if d, ok := c.CacheMaxAgeRoutes[route]; ok {
+		return d
+	}
+	return c.CacheMaxAge
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+		log.Printf("Warning: Ignoring invalid %s=%q", key, value)
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+		log.Printf("Warning: Ignoring invalid %s=%q", key, value)
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+		log.Printf("Warning: Ignoring invalid %s=%q", key, value)
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("Warning: Ignoring invalid %s=%q", key, value)
+	}
+	return defaultValue
+}
+
+// getEnvTimeout is getEnvDuration for timeouts that 0 disables
+func getEnvTimeout(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+			return d
+		}
+		log.Printf("Warning: Ignoring invalid %s=%q", key, value)
+	}
+	return defaultValue
 }
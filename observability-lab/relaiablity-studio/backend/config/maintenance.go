@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Recurrence periods for a MaintenanceWindow
+const (
+	RecurNone   = ""
+	RecurDaily  = "daily"
+	RecurWeekly = "weekly"
+)
+
+// MaintenanceWindow silences alerting for a service between Start and End.
+// A recurring window repeats every day or week from its first occurrence.
+type MaintenanceWindow struct {
+	Service   string    `json:"service"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Recurring string    `json:"recurring,omitempty"` // RecurNone, RecurDaily or RecurWeekly
+}
+
+// Validate rejects windows that end before they start, or that are longer
+// than the period they recur over
+func (w MaintenanceWindow) Validate() error {
+	if w.Service == "" {
+		return fmt.Errorf("maintenance window has no service")
+	}
+	if !w.End.After(w.Start) {
+		return fmt.Errorf("maintenance window for %s must end after it starts", w.Service)
+	}
+	switch w.Recurring {
+	case RecurNone:
+		return nil
+	case RecurDaily, RecurWeekly:
+		if w.End.Sub(w.Start) > w.period() {
+			return fmt.Errorf("maintenance window for %s is longer than its %s period", w.Service, w.Recurring)
+		}
+		return nil
+	default:
+		return fmt.Errorf("maintenance window for %s has unknown recurrence %q", w.Service, w.Recurring)
+	}
+}
+
+// Active reports whether the window covers now
+func (w MaintenanceWindow) Active(now time.Time) bool {
+	if now.Before(w.Start) {
+		return false
+	}
+	period := w.period()
+	if period == 0 {
+		return now.Before(w.End)
+	}
+	return now.Sub(w.Start)%period < w.End.Sub(w.Start)
+}
+
+func (w MaintenanceWindow) period() time.Duration {
+	switch w.Recurring {
+	case RecurDaily:
+		return 24 * time.Hour
+	case RecurWeekly:
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+// MaintenanceWindows is the configured set of windows
+type MaintenanceWindows []MaintenanceWindow
+
+// Suppressed reports whether a window for service is active at now, so the
+// windows can be used as a notify.Suppressor
+func (ws MaintenanceWindows) Suppressed(service string, now time.Time) (string, bool) {
+	for _, w := range ws {
+		if w.Service == service && w.Active(now) {
+			return fmt.Sprintf("in maintenance window starting %s", w.Start.Format(time.RFC3339)), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindowActive(t *testing.T) {
+	start := time.Date(2024, 1, 14, 2, 0, 0, 0, time.UTC)
+	once := MaintenanceWindow{Service: "checkout", Start: start, End: start.Add(2 * time.Hour)}
+	daily := once
+	daily.Recurring = RecurDaily
+	weekly := once
+	weekly.Recurring = RecurWeekly
+
+	testCases := []struct {
+		name   string
+		window MaintenanceWindow
+		at     time.Time
+		active bool
+	}{
+		{"before start", once, start.Add(-time.Minute), false},
+		{"during", once, start.Add(time.Hour), true},
+		{"at end", once, start.Add(2 * time.Hour), false},
+		{"one-off next day", once, start.Add(25 * time.Hour), false},
+		{"daily next day", daily, start.Add(25 * time.Hour), true},
+		{"daily between", daily, start.Add(27 * time.Hour), false},
+		{"weekly next day", weekly, start.Add(25 * time.Hour), false},
+		{"weekly next week", weekly, start.Add(7*24*time.Hour + time.Hour), true},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.window.Active(tc.at); got != tc.active {
+			t.Errorf("%s: expected active=%v, got %v", tc.name, tc.active, got)
+		}
+	}
+}
+
+func TestMaintenanceWindowValidate(t *testing.T) {
+	start := time.Date(2024, 1, 14, 2, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		window MaintenanceWindow
+		valid  bool
+	}{
+		{MaintenanceWindow{Service: "checkout", Start: start, End: start.Add(time.Hour)}, true},
+		{MaintenanceWindow{Start: start, End: start.Add(time.Hour)}, false},
+		{MaintenanceWindow{Service: "checkout", Start: start, End: start}, false},
+		{MaintenanceWindow{Service: "checkout", Start: start, End: start.Add(25 * time.Hour), Recurring: RecurDaily}, false},
+		{MaintenanceWindow{Service: "checkout", Start: start, End: start.Add(time.Hour), Recurring: "monthly"}, false},
+	}
+
+	for _, tc := range testCases {
+		if err := tc.window.Validate(); (err == nil) != tc.valid {
+			t.Errorf("Expected %+v valid=%v, got err=%v", tc.window, tc.valid, err)
+		}
+	}
+}
+
+func TestLoadIgnoresInvalidMaintenanceWindows(t *testing.T) {
+	// The first window decodes before the second fails
+	t.Setenv("MAINTENANCE_WINDOWS", `[{"service":"checkout","start":"2024-01-14T02:00:00Z","end":"2024-01-14T04:00:00Z"},{"service":5}]`)
+	if windows := Load().MaintenanceWindows; len(windows) != 0 {
+		t.Errorf("Expected an invalid MAINTENANCE_WINDOWS ignored, got %+v", windows)
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import "fmt"
+
+// K8sNamespaces scopes Kubernetes queries. A service is looked up in its
+// Services override, else Default; an empty namespace means all namespaces.
+// When Allowed is set only those namespaces may be queried.
+type K8sNamespaces struct {
+	Default  string
+	Allowed  []string
+	Services map[string]string
+}
+
+// For returns the namespace to query for service
+func (n K8sNamespaces) For(service string) (string, error) {
+	namespace := n.Default
+	if ns, ok := n.Services[service]; ok {
+		namespace = ns
+	}
+	if len(n.Allowed) == 0 {
+		return namespace, nil
+	}
+	for _, allowed := range n.Allowed {
+		if namespace == allowed {
+			return namespace, nil
+		}
+	}
+	if namespace == "" {
+		return "", fmt.Errorf("no namespace for %s; querying all namespaces is not allowed", service)
+	}
+	return "", fmt.Errorf("namespace %q for %s is not allowed", namespace, service)
+}
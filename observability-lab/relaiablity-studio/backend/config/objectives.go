@@ -0,0 +1,42 @@
+package config
+
+// SLO types with built-in default objectives
+const (
+	SLOAvailability = "availability"
+	SLOLatency      = "latency"
+)
+
+// Objective is the target of an SLO. Target is the percentage of good
+// requests; latency objectives also set the threshold a request must beat,
+// so p95 < 300ms is {Target: 95, ThresholdMs: 300}.
+type Objective struct {
+	Target      float64 `json:"target"`
+	ThresholdMs float64 `json:"threshold_ms,omitempty"`
+}
+
+// DefaultObjectives are used for SLO types that SLO_DEFAULT_OBJECTIVES does
+// not override
+var DefaultObjectives = map[string]Objective{
+	SLOAvailability: {Target: 99.9},
+	SLOLatency:      {Target: 95, ThresholdMs: 300},
+}
+
+// SLOObjectives resolves the objective of SLOs that don't set their own.
+// Defaults is keyed by SLO type, Overrides by service then SLO type.
+type SLOObjectives struct {
+	Defaults  map[string]Objective
+	Overrides map[string]map[string]Objective
+}
+
+// For returns the objective for a service's SLO of sloType: the service's
+// override if it has one, otherwise the default for the type
+func (o SLOObjectives) For(service, sloType string) (Objective, bool) {
+	if obj, ok := o.Overrides[service][sloType]; ok {
+		return obj, true
+	}
+	if obj, ok := o.Defaults[sloType]; ok {
+		return obj, true
+	}
+	obj, ok := DefaultObjectives[sloType]
+	return obj, ok
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+// QueryTemplate holds the PromQL/LogQL/TraceQL expressions for a service.
+// Each expression may reference {{.Service}}, and PromQL expressions
+// {{.Aggregation}}: Aggregation, "sum" unless set. GroupBy is rejected, as a
+// series per group can't be read as the service's error rate or latency.
+// ErrorQuery must return a percentage (0-100), not a fraction. TraceQuery
+// is optional: without it traces come from Tempo's unfiltered search. A
+// TraceQuery that selects only failed traces can't give a failure rate on
+// its own, so TraceTotalQuery selects all of the service's traces to count;
+// without it the trace failure rate isn't graded. BreakdownBy (e.g.
+// "instance" or "pod") also runs ErrorQuery aggregated by that label to
+// find outlier instances; this needs an ErrorQuery that uses
+// {{.Aggregation}}. LogQuery may reference {{.LineFilter}}, a line filter
+// for the configured ROOT_CAUSE_KEYWORDS.
+type QueryTemplate struct {
+	ErrorQuery      string   `json:"error_query"`
+	LatencyQuery    string   `json:"latency_query"`
+	LogQuery        string   `json:"log_query"`
+	TraceQuery      string   `json:"trace_query"`
+	TraceTotalQuery string   `json:"trace_total_query"`
+	Aggregation     string   `json:"aggregation"`
+	GroupBy         []string `json:"group_by"`
+	BreakdownBy     string   `json:"breakdown_by"`
+}
+
+// QueryTemplates maps a service name to its query templates
+type QueryTemplates map[string]QueryTemplate
+
+// Queries holds the rendered expressions for a single service
+type Queries struct {
+	Error   string
+	Latency string
+	Log     string
+	Trace   string // Empty without a TraceQL query
+
+	// TraceTotal counts the traces Trace's failures are a share of; empty
+	// when the template doesn't set one
+	TraceTotal string
+
+	// ErrorBreakdown is Error per BreakdownBy label value; empty unless
+	// the template sets BreakdownBy
+	ErrorBreakdown string
+	BreakdownBy    string
+}
+
+// DefaultAggregation aggregates PromQL queries that don't choose a function
+const DefaultAggregation = "sum"
+
+// Aggregations are the PromQL aggregation functions a template may choose
+var Aggregations = map[string]bool{"sum": true, "avg": true, "max": true, "min": true}
+
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// DefaultQueryTemplate is used for services without their own template
+var DefaultQueryTemplate = QueryTemplate{
+	ErrorQuery:   `{{.Aggregation}}(rate(http_requests_total{service="{{.Service}}",status=~"5.."}[5m])) / {{.Aggregation}}(rate(http_requests_total{service="{{.Service}}"}[5m])) * 100`,
+	LatencyQuery: `histogram_quantile(0.95, rate(http_request_duration_seconds_bucket{service="{{.Service}}"}[5m]))`,
+	LogQuery:     `{app="{{.Service}}"} {{.LineFilter}}`,
+}
+
+// For renders the queries for service. Expressions the service does not
+// override fall back to DefaultQueryTemplate.
+func (t QueryTemplates) For(service string) (Queries, error) {
+	tmpl := DefaultQueryTemplate
+	if custom, ok := t[service]; ok {
+		if custom.ErrorQuery != "" {
+			tmpl.ErrorQuery = custom.ErrorQuery
+		}
+		if custom.LatencyQuery != "" {
+			tmpl.LatencyQuery = custom.LatencyQuery
+		}
+		if custom.LogQuery != "" {
+			tmpl.LogQuery = custom.LogQuery
+		}
+		if custom.TraceQuery != "" {
+			tmpl.TraceQuery = custom.TraceQuery
+		}
+		tmpl.TraceTotalQuery = custom.TraceTotalQuery
+		tmpl.Aggregation, tmpl.GroupBy = custom.Aggregation, custom.GroupBy
+		tmpl.BreakdownBy = custom.BreakdownBy
+	}
+
+	// A query per group would return a series per group, of which only one
+	// would be read
+	if len(tmpl.GroupBy) > 0 {
+		return Queries{}, fmt.Errorf("group_by for %s would split its error and latency queries into a series per group; use breakdown_by for per-label error rates", service)
+	}
+	aggregation, err := tmpl.aggregation()
+	if err != nil {
+		return Queries{}, fmt.Errorf("invalid aggregation for %s: %w", service, err)
+	}
+	data := templateData{Service: service, Aggregation: aggregation, LineFilter: analysis.LogLineFilter()}
+
+	var q Queries
+	if q.Error, err = render("error_query", tmpl.ErrorQuery, data); err != nil {
+		return Queries{}, err
+	}
+	if q.Latency, err = render("latency_query", tmpl.LatencyQuery, data); err != nil {
+		return Queries{}, err
+	}
+	if q.Log, err = render("log_query", tmpl.LogQuery, data); err != nil {
+		return Queries{}, err
+	}
+	if q.Trace, err = render("trace_query", tmpl.TraceQuery, data); err != nil {
+		return Queries{}, err
+	}
+	if q.TraceTotal, err = render("trace_total_query", tmpl.TraceTotalQuery, data); err != nil {
+		return Queries{}, err
+	}
+
+	if tmpl.BreakdownBy != "" {
+		breakdown := tmpl
+		breakdown.GroupBy = []string{tmpl.BreakdownBy}
+		if data.Aggregation, err = breakdown.aggregation(); err != nil {
+			return Queries{}, fmt.Errorf("invalid breakdown_by for %s: %w", service, err)
+		}
+		if q.ErrorBreakdown, err = render("error_query", tmpl.ErrorQuery, data); err != nil {
+			return Queries{}, err
+		}
+		q.BreakdownBy = tmpl.BreakdownBy
+	}
+	return q, nil
+}
+
+// AggregationFor returns the aggregation function service's template chooses
+// (DefaultAggregation unless set), for queries outside the templates such as
+// the SLO traffic query
+func (t QueryTemplates) AggregationFor(service string) (string, error) {
+	tmpl := t[service]
+	tmpl.GroupBy = nil
+	fn, err := tmpl.aggregation()
+	if err != nil {
+		return "", fmt.Errorf("invalid aggregation for %s: %w", service, err)
+	}
+	return fn, nil
+}
+
+// templateData is what query templates can reference
+type templateData struct {
+	Service     string
+	Aggregation string
+	LineFilter  string
+}
+
+// aggregation renders the template's aggregation operator, e.g. "sum" or
+// "avg by (instance, zone)", checking it against Aggregations
+func (t QueryTemplate) aggregation() (string, error) {
+	fn := t.Aggregation
+	if fn == "" {
+		fn = DefaultAggregation
+	}
+	if !Aggregations[fn] {
+		return "", fmt.Errorf("%q is not one of sum, avg, max or min", fn)
+	}
+	if len(t.GroupBy) == 0 {
+		return fn, nil
+	}
+	for _, label := range t.GroupBy {
+		if !labelName.MatchString(label) {
+			return "", fmt.Errorf("invalid group_by label %q", label)
+		}
+	}
+	return fmt.Sprintf("%s by (%s) ", fn, strings.Join(t.GroupBy, ", ")), nil
+}
+
+func render(name, text string, data templateData) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render %s for %s: %w", name, data.Service, err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQueryTemplatesFor(t *testing.T) {
+	templates := QueryTemplates{
+		"checkout": {
+			ErrorQuery: `sum(rate(requests_total{job="{{.Service}}",code=~"5.."}[5m]))`,
+		},
+	}
+
+	custom, err := templates.For("checkout")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `sum(rate(requests_total{job="checkout",code=~"5.."}[5m]))`; custom.Error != want {
+		t.Errorf("Expected custom error query %q, got %q", want, custom.Error)
+	}
+	// Expressions without an override fall back to the default template
+	if want := `histogram_quantile(0.95, rate(http_request_duration_seconds_bucket{service="checkout"}[5m]))`; custom.Latency != want {
+		t.Errorf("Expected default latency query %q, got %q", want, custom.Latency)
+	}
+
+	def, err := templates.For("payments")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `sum(rate(http_requests_total{service="payments",status=~"5.."}[5m])) / sum(rate(http_requests_total{service="payments"}[5m])) * 100`; def.Error != want {
+		t.Errorf("Expected default error query %q, got %q", want, def.Error)
+	}
+	if want := `{app="payments"} |~ "(?i)(error|exception|panic|fatal)"`; def.Log != want {
+		t.Errorf("Expected default log query %q, got %q", want, def.Log)
+	}
+}
+
+func TestQueryTemplatesInvalid(t *testing.T) {
+	templates := QueryTemplates{"checkout": {ErrorQuery: `rate({{.Service}`}}
+	if _, err := templates.For("checkout"); err == nil {
+		t.Error("Expected error for malformed template")
+	}
+}
+
+func TestQueryTemplatesAggregation(t *testing.T) {
+	templates := QueryTemplates{
+		"checkout": {Aggregation: "avg"},
+		"payments": {Aggregation: "max"},
+		"search":   {Aggregation: "count"},
+		"cart":     {GroupBy: []string{"instance"}},
+	}
+
+	checkout, err := templates.For("checkout")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `avg(rate(http_requests_total{service="checkout",status=~"5.."}[5m])) / avg(rate(http_requests_total{service="checkout"}[5m])) * 100`; checkout.Error != want {
+		t.Errorf("Expected error query %q, got %q", want, checkout.Error)
+	}
+
+	payments, err := templates.For("payments")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `max(rate(http_requests_total{service="payments",status=~"5.."}[5m])) / max(rate(http_requests_total{service="payments"}[5m])) * 100`; payments.Error != want {
+		t.Errorf("Expected error query %q, got %q", want, payments.Error)
+	}
+
+	for _, service := range []string{"search", "cart"} {
+		if _, err := templates.For(service); err == nil {
+			t.Errorf("Expected %s's aggregation to be rejected", service)
+		}
+	}
+
+	if fn, err := templates.AggregationFor("checkout"); err != nil || fn != "avg" {
+		t.Errorf("Expected checkout's aggregation avg, got %q (%v)", fn, err)
+	}
+	if fn, err := templates.AggregationFor("inventory"); err != nil || fn != DefaultAggregation {
+		t.Errorf("Expected the default aggregation, got %q (%v)", fn, err)
+	}
+	if _, err := templates.AggregationFor("search"); err == nil {
+		t.Error("Expected search's aggregation to be rejected")
+	}
+}
+
+func TestQueryTemplatesBreakdown(t *testing.T) {
+	templates := QueryTemplates{
+		"checkout": {BreakdownBy: "pod"},
+		"payments": {BreakdownBy: "pod-name"},
+	}
+
+	q, err := templates.For("checkout")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(q.Error, "sum(rate(") {
+		t.Errorf("Expected the service-level error query unchanged, got %s", q.Error)
+	}
+	if !strings.HasPrefix(q.ErrorBreakdown, "sum by (pod) (rate(") || q.BreakdownBy != "pod" {
+		t.Errorf("Expected the error query broken down by pod, got %s", q.ErrorBreakdown)
+	}
+
+	if _, err := templates.For("payments"); err == nil {
+		t.Error("Expected an invalid breakdown label to be rejected")
+	}
+}
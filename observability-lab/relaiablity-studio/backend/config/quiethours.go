@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Quiet hours modes: what happens to non-critical notifications during them
+const (
+	QuietDrop  = "drop"
+	QuietQueue = "queue"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// QuietHours is a daily period, on some weekdays, during which only critical
+// incidents notify. Start and End are "15:04" clock times in Timezone (an
+// IANA zone, default UTC); an End at or before Start runs past midnight.
+// Days ("mon" to "sun") are the days the period starts on; none means every day.
+type QuietHours struct {
+	Days     []string `json:"days,omitempty"`
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	Timezone string   `json:"timezone,omitempty"`
+}
+
+// Validate rejects unknown days, malformed clock times and unknown zones
+func (q QuietHours) Validate() error {
+	for _, d := range q.Days {
+		if _, ok := weekdays[strings.ToLower(d)]; !ok {
+			return fmt.Errorf("quiet hours have unknown day %q", d)
+		}
+	}
+	if _, err := time.Parse("15:04", q.Start); err != nil {
+		return fmt.Errorf("quiet hours have invalid start %q", q.Start)
+	}
+	if _, err := time.Parse("15:04", q.End); err != nil {
+		return fmt.Errorf("quiet hours have invalid end %q", q.End)
+	}
+	if _, err := time.LoadLocation(q.Timezone); err != nil {
+		return fmt.Errorf("quiet hours have unknown timezone %q", q.Timezone)
+	}
+	return nil
+}
+
+// Active reports whether now falls in the quiet hours. Invalid quiet hours
+// are never active.
+func (q QuietHours) Active(now time.Time) bool {
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return false
+	}
+	start, err1 := time.Parse("15:04", q.Start)
+	end, err2 := time.Parse("15:04", q.End)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	from, to := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if from < to {
+		return q.on(local.Weekday()) && minute >= from && minute < to
+	}
+	// Overnight: the evening of a quiet day, or the morning after one
+	yesterday := (local.Weekday() + 6) % 7
+	return (q.on(local.Weekday()) && minute >= from) || (q.on(yesterday) && minute < to)
+}
+
+func (q QuietHours) on(day time.Weekday) bool {
+	if len(q.Days) == 0 {
+		return true
+	}
+	for _, d := range q.Days {
+		if weekdays[strings.ToLower(d)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// QuietHoursList is the configured set of quiet hours
+type QuietHoursList []QuietHours
+
+// Active reports whether any of the quiet hours cover now
+func (qs QuietHoursList) Active(now time.Time) bool {
+	for _, q := range qs {
+		if q.Active(now) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHoursActive(t *testing.T) {
+	// Weeknights from 22:00 to 07:00 in New York (UTC-5 in January)
+	weeknights := QuietHours{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "22:00", End: "07:00", Timezone: "America/New_York"}
+	lunch := QuietHours{Start: "12:00", End: "13:00"}
+
+	testCases := []struct {
+		name   string
+		quiet  QuietHours
+		at     time.Time
+		active bool
+	}{
+		{"monday evening", weeknights, time.Date(2024, 1, 16, 3, 30, 0, 0, time.UTC), true},        // Mon 22:30 local
+		{"tuesday early morning", weeknights, time.Date(2024, 1, 16, 11, 0, 0, 0, time.UTC), true}, // Tue 06:00 local
+		{"tuesday after end", weeknights, time.Date(2024, 1, 16, 12, 0, 0, 0, time.UTC), false},    // Tue 07:00 local
+		{"saturday evening", weeknights, time.Date(2024, 1, 21, 3, 30, 0, 0, time.UTC), false},     // Sat 22:30 local
+		{"saturday morning after friday", weeknights, time.Date(2024, 1, 20, 11, 0, 0, 0, time.UTC), true},
+		{"every day", lunch, time.Date(2024, 1, 21, 12, 30, 0, 0, time.UTC), true},
+		{"every day outside", lunch, time.Date(2024, 1, 21, 13, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.quiet.Active(tc.at); got != tc.active {
+			t.Errorf("%s: expected active=%v, got %v", tc.name, tc.active, got)
+		}
+	}
+}
+
+func TestQuietHoursValidate(t *testing.T) {
+	invalid := []QuietHours{
+		{Days: []string{"someday"}, Start: "22:00", End: "07:00"},
+		{Start: "10pm", End: "07:00"},
+		{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus"},
+	}
+	for _, q := range invalid {
+		if err := q.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", q)
+		}
+	}
+	if err := (QuietHours{Days: []string{"Sat", "sun"}, Start: "00:00", End: "23:59"}).Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
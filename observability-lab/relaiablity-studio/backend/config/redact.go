@@ -0,0 +1,77 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces secret values
+const Redacted = "[redacted]"
+
+// Redacted returns a copy of the config safe to show operators: passwords,
+// secrets and webhook headers are replaced, passwords in upstream and proxy
+// URLs are masked, and webhook URLs, which often embed a token in their path,
+// keep only their scheme and host
+func (c Config) Redacted() Config {
+	c.BasicAuthPassword = redact(c.BasicAuthPassword)
+	c.BasicAuthPasswordHash = redact(c.BasicAuthPasswordHash)
+	c.IncidentWebhookSecret = redact(c.IncidentWebhookSecret)
+	if len(c.IncidentWebhookHeaders) > 0 {
+		headers := make(map[string]string, len(c.IncidentWebhookHeaders))
+		for name := range c.IncidentWebhookHeaders {
+			headers[name] = Redacted
+		}
+		c.IncidentWebhookHeaders = headers
+	}
+
+	c.PrometheusURL = redactUserinfo(c.PrometheusURL)
+	c.LokiURL = redactUserinfo(c.LokiURL)
+	c.TempoURL = redactUserinfo(c.TempoURL)
+	c.PrometheusProxy = redactUserinfo(c.PrometheusProxy)
+	c.LokiProxy = redactUserinfo(c.LokiProxy)
+	c.TempoProxy = redactUserinfo(c.TempoProxy)
+	c.GrafanaURL = redactUserinfo(c.GrafanaURL)
+
+	c.NotifyWebhookURL = redactPath(c.NotifyWebhookURL)
+	c.IncidentWebhookURL = redactPath(c.IncidentWebhookURL)
+	return c
+}
+
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	return Redacted
+}
+
+// redactUserinfo masks the passwords in a comma-separated list of URLs
+func redactUserinfo(list string) string {
+	if list == "" {
+		return ""
+	}
+	urls := strings.Split(list, ",")
+	for i, raw := range urls {
+		u, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			urls[i] = Redacted
+			continue
+		}
+		urls[i] = u.Redacted()
+	}
+	return strings.Join(urls, ",")
+}
+
+// redactPath keeps only the scheme and host of a URL
+func redactPath(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return Redacted
+	}
+	if u.Path == "" && u.RawQuery == "" && u.User == nil {
+		return raw
+	}
+	return u.Scheme + "://" + u.Host + "/" + Redacted
+}
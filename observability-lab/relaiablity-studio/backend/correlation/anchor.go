@@ -0,0 +1,57 @@
+package correlation
+
+import "time"
+
+var anchorBefore, anchorAfter time.Duration
+
+// SetAnchorWindow sets how far before and after the anchor the timeline
+// reaches. Zero leaves that side of the timeline unbounded.
+func SetAnchorWindow(before, after time.Duration) {
+	anchorBefore, anchorAfter = max(before, 0), max(after, 0)
+}
+
+// severeEvents are the event types that can anchor an incident: error spikes,
+// failed traces and crashing pods
+var severeEvents = map[string]bool{
+	EventErrorLog:      true,
+	EventMetricAnomaly: true,
+	EventTraceFailure:  true,
+	EventPodFailure:    true,
+	EventOOMKill:       true,
+}
+
+// Anchor returns the time of the earliest high-severity signal in the
+// timeline, which the incident is windowed around. A timeline with none has
+// no anchor.
+func Anchor(timeline []Event) (time.Time, bool) {
+	var anchor time.Time
+	for _, e := range timeline {
+		if !severeEvents[e.Type] {
+			continue
+		}
+		if at, ok := parseEventTime(e.Time); ok && (anchor.IsZero() || at.Before(anchor)) {
+			anchor = at
+		}
+	}
+	return anchor, !anchor.IsZero()
+}
+
+// windowAround keeps the events within the anchor window. Events without a
+// parseable time can't be placed and are kept.
+func windowAround(timeline []Event, anchor time.Time) []Event {
+	if anchorBefore == 0 && anchorAfter == 0 {
+		return timeline
+	}
+	kept := timeline[:0]
+	for _, e := range timeline {
+		at, ok := parseEventTime(e.Time)
+		if ok && anchorBefore > 0 && at.Before(anchor.Add(-anchorBefore)) {
+			continue
+		}
+		if ok && anchorAfter > 0 && at.After(anchor.Add(anchorAfter)) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
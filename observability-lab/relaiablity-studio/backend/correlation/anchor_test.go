@@ -0,0 +1,53 @@
+package correlation
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+func TestAnchorEarliestSevereSignal(t *testing.T) {
+	SetAnchorWindow(10*time.Minute, 5*time.Minute)
+	defer SetAnchorWindow(0, 0)
+
+	logs := analysis.LogResult{ErrorCount: 1, Events: []analysis.LogEvent{
+		{Time: "2024-01-15T01:40:00Z", Message: "cache warmed"},
+		{Time: "2024-01-15T02:05:00Z", Message: "info: retrying request"},
+		{Time: "2024-01-15T02:14:00Z", Message: "error: db connection refused"},
+	}}
+	k8s := analysis.K8sResult{BadPods: 1, Events: []analysis.K8sEvent{
+		{Time: "2024-01-15T02:00:00Z", Kind: "Deployment", Message: "Deployment checkout rolled out"},
+		{Time: "2024-01-15T02:10:00Z", Kind: "Pod", Message: "Pod checkout-1 CrashLoopBackOff"},
+		{Time: "2024-01-15T02:30:00Z", Kind: "Pod", Message: "Pod checkout-2 CrashLoopBackOff"},
+	}}
+
+	incident := correlate("checkout", logs, analysis.MetricResult{ErrorRate: 3}, analysis.TraceResult{}, k8s, nil)
+
+	expected := time.Date(2024, 1, 15, 2, 10, 0, 0, time.UTC)
+	if incident.Anchor == nil || !incident.Anchor.Equal(expected) {
+		t.Fatalf("Expected the pod crash at %s as the anchor, got %v", expected, incident.Anchor)
+	}
+
+	var times []string
+	for _, e := range incident.Timeline {
+		times = append(times, e.Time)
+	}
+	sort.Strings(times)
+	want := []string{"2024-01-15T02:00:00Z", "2024-01-15T02:05:00Z", "2024-01-15T02:10:00Z", "2024-01-15T02:14:00Z"}
+	if len(times) != len(want) {
+		t.Fatalf("Expected the timeline windowed to %v, got %v", want, times)
+	}
+	for i := range want {
+		if times[i] != want[i] {
+			t.Errorf("Expected event %d at %s, got %s", i, want[i], times[i])
+		}
+	}
+
+	healthy := correlate("checkout", analysis.LogResult{Events: []analysis.LogEvent{{Time: "2024-01-15T02:05:00Z", Message: "ok"}}},
+		analysis.MetricResult{}, analysis.TraceResult{}, analysis.K8sResult{}, nil)
+	if healthy.Anchor != nil || len(healthy.Timeline) != 1 {
+		t.Errorf("Expected no anchor and an untouched timeline without severe signals, got %v, %d events", healthy.Anchor, len(healthy.Timeline))
+	}
+}
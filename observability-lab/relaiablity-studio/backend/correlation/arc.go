@@ -0,0 +1,68 @@
+package correlation
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultRangeConcurrency is how many windows BuildIncidentOverRange analyzes
+// at once unless configured
+const DefaultRangeConcurrency = 4
+
+var rangeConcurrency = DefaultRangeConcurrency
+
+// SetRangeConcurrency sets how many windows BuildIncidentOverRange analyzes at
+// once. n <= 0 restores DefaultRangeConcurrency.
+func SetRangeConcurrency(n int) {
+	if n <= 0 {
+		n = DefaultRangeConcurrency
+	}
+	rangeConcurrency = n
+}
+
+// Snapshot is an incident as analyzed over one window of a range
+type Snapshot struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Incident Incident  `json:"incident"`
+}
+
+// BuildIncidentOverRange splits start to end into consecutive windows of step
+// (the last one may be shorter) and builds an incident for each, several at a
+// time, from the sources sources returns for the window. The snapshots are in
+// time order, showing how the incident evolved.
+func BuildIncidentOverRange(service string, start, end time.Time, step time.Duration, sources func(TimeRange) Sources) []Snapshot {
+	if step <= 0 || !start.Before(end) {
+		return nil
+	}
+	var snapshots []Snapshot
+	for t := start; t.Before(end); t = t.Add(step) {
+		snapshots = append(snapshots, Snapshot{Start: t, End: minTime(t.Add(step), end)})
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(rangeConcurrency, len(snapshots)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				s := &snapshots[i]
+				s.Incident = BuildIncident(service, sources(TimeRange{Start: s.Start, End: s.End}))
+			}
+		}()
+	}
+	for i := range snapshots {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return snapshots
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
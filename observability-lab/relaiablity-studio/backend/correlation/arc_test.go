@@ -0,0 +1,62 @@
+package correlation
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+func TestBuildIncidentOverRange(t *testing.T) {
+	SetRangeConcurrency(2)
+	defer SetRangeConcurrency(0)
+
+	start := time.Date(2024, 1, 15, 2, 0, 0, 0, time.UTC)
+	end := start.Add(25 * time.Minute)
+
+	// The error rate climbs with each window, so snapshots must stay in order
+	var mu sync.Mutex
+	var inFlight, peak int
+	snapshots := BuildIncidentOverRange("checkout", start, end, 10*time.Minute, func(tr TimeRange) Sources {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		rate := tr.Start.Sub(start).Minutes() / 5
+		return fakeSources{metrics: analysis.MetricResult{ErrorRate: rate}}
+	})
+
+	expected := []struct {
+		start, end time.Time
+		errorRate  float64
+	}{
+		{start, start.Add(10 * time.Minute), 0},
+		{start.Add(10 * time.Minute), start.Add(20 * time.Minute), 2},
+		{start.Add(20 * time.Minute), end, 4},
+	}
+	if len(snapshots) != len(expected) {
+		t.Fatalf("Expected %d snapshots, got %d", len(expected), len(snapshots))
+	}
+	for i, want := range expected {
+		s := snapshots[i]
+		if !s.Start.Equal(want.start) || !s.End.Equal(want.end) {
+			t.Errorf("Snapshot %d: Expected %s to %s, got %s to %s", i, want.start, want.end, s.Start, s.End)
+		}
+		if s.Incident.Impact.ErrorRate != want.errorRate {
+			t.Errorf("Snapshot %d: Expected error rate %v, got %v", i, want.errorRate, s.Incident.Impact.ErrorRate)
+		}
+	}
+	if peak > 2 {
+		t.Errorf("Expected at most 2 windows in flight, got %d", peak)
+	}
+
+	if got := BuildIncidentOverRange("checkout", end, start, time.Minute, nil); got != nil {
+		t.Errorf("Expected no snapshots for an empty range, got %d", len(got))
+	}
+}
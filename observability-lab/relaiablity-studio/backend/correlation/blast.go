@@ -0,0 +1,87 @@
+package correlation
+
+import (
+	"net"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+// BlastRadius counts the distinct services, namespaces and instances an
+// incident touched. A single-service incident has a blast radius of one
+// service.
+type BlastRadius struct {
+	Services   int `json:"services"`
+	Namespaces int `json:"namespaces"`
+	Instances  int `json:"instances"`
+}
+
+// ComputeBlastRadius finds the affected services (the incident's own plus
+// the failing services its failed calls reach), namespaces (of timeline
+// events) and instances (of timeline events, plus metric outliers). An
+// outlier naming a pod, or the IP of one, counts as that pod.
+func ComputeBlastRadius(service string, timeline []Event, metrics analysis.MetricResult, traces analysis.TraceResult, k8s analysis.K8sResult) BlastRadius {
+	namespaces := make(map[string]bool)
+	instances := make(map[string]bool)
+	for _, e := range timeline {
+		if e.Namespace != "" {
+			namespaces[e.Namespace] = true
+		}
+		if e.Instance != "" {
+			instances[e.Namespace+"/"+e.Instance] = true
+		}
+	}
+
+	// Outliers are labelled by pod name or by the scraped ip:port
+	pods := make(map[string]string)
+	for _, e := range k8s.Events {
+		if e.Pod == "" {
+			continue
+		}
+		pods[e.Pod] = e.Namespace + "/" + e.Pod
+		if e.PodIP != "" {
+			pods[e.PodIP] = e.Namespace + "/" + e.Pod
+		}
+	}
+	for instance := range metrics.Outliers {
+		host := instance
+		if h, _, err := net.SplitHostPort(instance); err == nil {
+			host = h
+		}
+		if pod, ok := pods[host]; ok {
+			instance = pod
+		}
+		instances[instance] = true
+	}
+
+	return BlastRadius{Services: len(affectedServices(service, traces)), Namespaces: len(namespaces), Instances: len(instances)}
+}
+
+// affectedServices returns service and the failing services linked to it,
+// directly or through others, by failed calls in either direction. Traces
+// also return other services' failures, which without a failed call to or
+// from the incident's service aren't part of it.
+func affectedServices(service string, traces analysis.TraceResult) map[string]bool {
+	linked := make(map[string][]string)
+	for caller, callees := range traces.FailedCalls {
+		for callee, n := range callees {
+			if n > 0 {
+				linked[caller] = append(linked[caller], callee)
+				linked[callee] = append(linked[callee], caller)
+			}
+		}
+	}
+
+	affected := map[string]bool{service: true}
+	queue := []string{service}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, s := range linked[next] {
+			if !affected[s] {
+				affected[s] = true
+				queue = append(queue, s)
+			}
+		}
+	}
+	return affected
+}
@@ -0,0 +1,56 @@
+package correlation
+
+import (
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+func TestBlastRadius(t *testing.T) {
+	tests := []struct {
+		name     string
+		metrics  analysis.MetricResult
+		traces   analysis.TraceResult
+		k8s      analysis.K8sResult
+		expected BlastRadius
+	}{
+		{
+			name:     "Single service",
+			metrics:  analysis.MetricResult{ErrorRate: 4.2},
+			expected: BlastRadius{Services: 1},
+		},
+		{
+			name:    "Multi-service, multi-namespace",
+			metrics: analysis.MetricResult{ErrorRate: 4.2, Outliers: map[string]float64{"10.0.0.7:8080": 30}},
+			traces: analysis.TraceResult{
+				Failures:        4,
+				ServiceFailures: map[string]int{"checkout": 2, "payments": 1, "inventory": 0, "search": 1},
+				FailedCalls:     map[string]map[string]int{"checkout": {"payments": 1}},
+			},
+			k8s: analysis.K8sResult{BadPods: 2, Events: []analysis.K8sEvent{
+				{Time: "2024-01-15T02:14:00Z", Message: "Pod failed", Kind: analysis.K8sPodEvent, Namespace: "shop", Pod: "checkout-7d9f", PodIP: "10.0.0.7"},
+				{Time: "2024-01-15T02:15:00Z", Message: "Pod failed", Kind: analysis.K8sPodEvent, Namespace: "billing", Pod: "payments-1a2b"},
+				{Time: "2024-01-15T02:16:00Z", Message: "Container app OOMKilled", Kind: analysis.K8sOOMKillEvent, Namespace: "billing", Pod: "payments-1a2b"},
+			}},
+			expected: BlastRadius{Services: 2, Namespaces: 2, Instances: 2},
+		},
+		{
+			name:    "Unrelated trace failures, outliers by pod and unknown IP",
+			metrics: analysis.MetricResult{ErrorRate: 4.2, Outliers: map[string]float64{"checkout-7d9f": 30, "10.0.0.9:8080": 25}},
+			traces:  analysis.TraceResult{Failures: 1, ServiceFailures: map[string]int{"search": 1}},
+			k8s: analysis.K8sResult{BadPods: 1, Events: []analysis.K8sEvent{
+				{Time: "2024-01-15T02:14:00Z", Message: "Pod failed", Kind: analysis.K8sPodEvent, Namespace: "shop", Pod: "checkout-7d9f", PodIP: "10.0.0.7"},
+			}},
+			expected: BlastRadius{Services: 1, Namespaces: 1, Instances: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			incident := correlate("checkout", analysis.LogResult{}, tt.metrics, tt.traces, tt.k8s, nil)
+			if got := incident.Impact.BlastRadius; got != tt.expected {
+				t.Errorf("Expected blast radius %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
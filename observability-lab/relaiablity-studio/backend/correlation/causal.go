@@ -0,0 +1,78 @@
+package correlation
+
+import (
+	"math"
+	"sort"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+// CausalChain orders the services failing in traces from likely cause to
+// effect. Errors propagate up the call path, so a failing callee comes
+// before the failing services that called it; services the calls don't
+// order come by earliest failure, then name. Fewer than two failing
+// services have no chain.
+func CausalChain(traces analysis.TraceResult) []string {
+	failing := make(map[string]bool)
+	for service, n := range traces.ServiceFailures {
+		if n > 0 {
+			failing[service] = true
+		}
+	}
+	for service := range traces.FirstFailure {
+		failing[service] = true
+	}
+	if len(failing) < 2 {
+		return nil
+	}
+
+	// pending counts each caller's failing callees not yet in the chain
+	pending := make(map[string]int)
+	callers := make(map[string][]string)
+	for caller, callees := range traces.FailedCalls {
+		for callee := range callees {
+			if failing[caller] && failing[callee] {
+				pending[caller]++
+				callers[callee] = append(callers[callee], caller)
+			}
+		}
+	}
+
+	firstFailure := func(service string) int64 {
+		if t, ok := traces.FirstFailure[service]; ok {
+			return t
+		}
+		return math.MaxInt64
+	}
+	remaining := make([]string, 0, len(failing))
+	for service := range failing {
+		remaining = append(remaining, service)
+	}
+	sort.Slice(remaining, func(i, j int) bool {
+		a, b := remaining[i], remaining[j]
+		if firstFailure(a) != firstFailure(b) {
+			return firstFailure(a) < firstFailure(b)
+		}
+		return a < b
+	})
+
+	chain := make([]string, 0, len(remaining))
+	for len(remaining) > 0 {
+		// The earliest service with no failing callees left; if calls form a
+		// cycle, the earliest service of all
+		next := 0
+		for i, service := range remaining {
+			if pending[service] == 0 {
+				next = i
+				break
+			}
+		}
+		service := remaining[next]
+		remaining = append(remaining[:next], remaining[next+1:]...)
+		chain = append(chain, service)
+		for _, caller := range callers[service] {
+			pending[caller]--
+		}
+	}
+	return chain
+}
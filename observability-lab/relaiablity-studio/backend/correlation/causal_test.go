@@ -0,0 +1,44 @@
+package correlation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+func TestCausalChainFollowsFailedCalls(t *testing.T) {
+	// frontend calls checkout, which calls payments; every span failed. The
+	// callers' spans start first, but payments' failure caused theirs.
+	raw := `{"traces":[{"traceID":"a1","rootServiceName":"frontend","startTimeUnixNano":"1705284839000000000","status":"error",
+		"spanSet":{"spans":[
+			{"spanID":"1","startTimeUnixNano":"1705284839000000000","attributes":[{"key":"service.name","value":{"stringValue":"frontend"}},{"key":"status","value":{"stringValue":"error"}}]},
+			{"spanID":"2","parentSpanID":"1","startTimeUnixNano":"1705284839100000000","attributes":[{"key":"service.name","value":{"stringValue":"checkout"}},{"key":"status","value":{"stringValue":"error"}}]},
+			{"spanID":"3","parentSpanID":"2","startTimeUnixNano":"1705284839200000000","attributes":[{"key":"service.name","value":{"stringValue":"payments"}},{"key":"status","value":{"stringValue":"error"}}]}
+		]}}]}`
+	traces, err := analysis.AnalyzeTraces(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"payments", "checkout", "frontend"}
+	if chain := CausalChain(traces); !reflect.DeepEqual(chain, expected) {
+		t.Errorf("Expected chain %v, got %v", expected, chain)
+	}
+}
+
+func TestCausalChainWithoutCalls(t *testing.T) {
+	traces := analysis.TraceResult{
+		ServiceFailures: map[string]int{"checkout": 2, "inventory": 1, "search": 1},
+		FirstFailure:    map[string]int64{"inventory": 100, "checkout": 200},
+	}
+	expected := []string{"inventory", "checkout", "search"}
+	if chain := CausalChain(traces); !reflect.DeepEqual(chain, expected) {
+		t.Errorf("Expected time order then unknown, got %v", chain)
+	}
+
+	single := analysis.TraceResult{ServiceFailures: map[string]int{"checkout": 3}}
+	if chain := CausalChain(single); chain != nil {
+		t.Errorf("Expected no chain for a single service, got %v", chain)
+	}
+}
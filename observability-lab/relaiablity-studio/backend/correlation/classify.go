@@ -0,0 +1,60 @@
+package correlation
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+// Event types, so timelines can be grouped
+const (
+	EventDeploy        = "deploy"
+	EventScaling       = "scaling"
+	EventPodFailure    = "pod_failure"
+	EventPodNotReady   = "pod_not_ready"
+	EventOOMKill       = "oom_kill"
+	EventErrorLog      = "error_log"
+	EventLog           = "log"
+	EventTraceFailure  = "trace_failure"
+	EventLatencySpike  = "latency_spike"
+	EventMetricAnomaly = "metric_anomaly"
+)
+
+// deployWord matches "deploy" and its inflections as a whole word, so names
+// like "redeployment-service" don't read as deploys
+var deployWord = regexp.MustCompile(`\bdeploy(s|ed|ing|ment|ments)?\b`)
+
+// ClassifyEvent types a timeline event from its source ("logs", "traces",
+// "kubernetes" or "metrics"), the analyzer's event kind if known, and its
+// message
+func ClassifyEvent(source, kind, message string) string {
+	lower := strings.ToLower(message)
+	switch source {
+	case "kubernetes":
+		switch {
+		case kind == analysis.K8sRolloutEvent, strings.Contains(lower, "rollout"), deployWord.MatchString(lower):
+			return EventDeploy
+		case kind == "" && strings.Contains(lower, "scal"):
+			return EventScaling
+		case kind == analysis.K8sNotReadyEvent:
+			return EventPodNotReady
+		case kind == analysis.K8sOOMKillEvent, strings.Contains(lower, "oomkill"):
+			return EventOOMKill
+		}
+		return EventPodFailure
+	case "traces":
+		return EventTraceFailure
+	case "metrics":
+		if strings.Contains(lower, "latency") {
+			return EventLatencySpike
+		}
+		return EventMetricAnomaly
+	case "logs":
+		if analysis.IsErrorLine(message) {
+			return EventErrorLog
+		}
+		return EventLog
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package correlation
+
+import (
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+func TestClassifyEvent(t *testing.T) {
+	testCases := []struct {
+		source, kind, message string
+		expected              string
+	}{
+		{"kubernetes", analysis.K8sRolloutEvent, "Rollout started: deployment checkout revision 7 (1/3 replicas updated)", EventDeploy},
+		{"kubernetes", "", "Deployment checkout rolled out", EventDeploy},
+		{"kubernetes", "", "checkout deployed v42", EventDeploy},
+		{"kubernetes", analysis.K8sPodEvent, "Pod failed", EventPodFailure},
+		{"kubernetes", analysis.K8sPodEvent, "Pod redeployment-service-1 failed", EventPodFailure},
+		{"kubernetes", "", "Scaled up replica set checkout-7d9 to 5", EventScaling},
+		{"logs", "", `level=error msg="DB connection timeout"`, EventErrorLog},
+		{"logs", "", "panic: runtime error: index out of range", EventErrorLog},
+		{"logs", "", `level=info msg="request served"`, EventLog},
+		{"traces", "", "Trace failure", EventTraceFailure},
+		{"metrics", "", "p95 latency 2.3s", EventLatencySpike},
+		{"metrics", "", "error rate 4.2%", EventMetricAnomaly},
+		{"deploys", "", "checkout v42", ""},
+	}
+
+	for _, tc := range testCases {
+		if got := ClassifyEvent(tc.source, tc.kind, tc.message); got != tc.expected {
+			t.Errorf("%s %q: expected type %q, got %q", tc.source, tc.message, tc.expected, got)
+		}
+	}
+}
+
+func TestTimelineEventTypes(t *testing.T) {
+	src := fakeSources{
+		logs:   analysis.LogResult{ErrorCount: 1, Events: []analysis.LogEvent{{Time: "2024-01-15T02:13:58Z", Message: "level=error msg=timeout"}}},
+		traces: analysis.TraceResult{Failures: 1, Events: []analysis.TraceEvent{{Time: "1705284840000000000", Message: "Trace failure"}}},
+		k8s: analysis.K8sResult{BadPods: 1, Events: []analysis.K8sEvent{
+			{Time: "2024-01-15T02:14:00Z", Message: "Pod failed", Kind: analysis.K8sPodEvent},
+			{Time: "2024-01-15T02:10:00Z", Message: "Rollout started: deployment checkout revision 7 (3/3 replicas updated)", Kind: analysis.K8sRolloutEvent},
+		}},
+	}
+
+	incident := BuildIncident("checkout", src)
+
+	expected := map[string]string{
+		"level=error msg=timeout": EventErrorLog,
+		"Trace failure":           EventTraceFailure,
+		"Pod failed":              EventPodFailure,
+		"Rollout started: deployment checkout revision 7 (3/3 replicas updated)": EventDeploy,
+	}
+	if len(incident.Timeline) != len(expected) {
+		t.Fatalf("Expected %d events, got %+v", len(expected), incident.Timeline)
+	}
+	for _, e := range incident.Timeline {
+		if want := expected[e.Message]; e.Type != want {
+			t.Errorf("Expected %s event %q typed %q, got %q", e.Source, e.Message, want, e.Type)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package correlation
+
+// CommonRootCause is a root cause several services reported, e.g. a shared
+// database failing in a cascade
+type CommonRootCause struct {
+	RootCause string   `json:"root_cause"` // As the first of Services reported it
+	Services  []string `json:"services"`
+}
+
+// CollapseRootCauses finds root causes shared, once normalized, by at least
+// minServices of the incidents. Those incidents are returned with their root
+// cause cleared and SharedRootCause set, so it is listed once among the common
+// root causes rather than repeated. minServices <= 0 disables collapsing.
+func CollapseRootCauses(incidents []Incident, minServices int) ([]Incident, []CommonRootCause) {
+	if minServices <= 0 {
+		return incidents, nil
+	}
+	minServices = max(minServices, 2)
+
+	groups := make(map[string][]int)
+	var order []string
+	for i, incident := range incidents {
+		if incident.RootCause == "" {
+			continue
+		}
+		pattern := NormalizeRootCause(incident.RootCause)
+		if _, seen := groups[pattern]; !seen {
+			order = append(order, pattern)
+		}
+		groups[pattern] = append(groups[pattern], i)
+	}
+
+	collapsed := append([]Incident(nil), incidents...)
+	var common []CommonRootCause
+	for _, pattern := range order {
+		members := groups[pattern]
+		if len(members) < minServices {
+			continue
+		}
+		c := CommonRootCause{RootCause: incidents[members[0]].RootCause}
+		for _, i := range members {
+			c.Services = append(c.Services, incidents[i].Service)
+			collapsed[i].RootCause = ""
+			collapsed[i].SharedRootCause = true
+		}
+		common = append(common, c)
+	}
+	return collapsed, common
+}
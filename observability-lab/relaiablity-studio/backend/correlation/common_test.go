@@ -0,0 +1,45 @@
+package correlation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCollapseRootCauses(t *testing.T) {
+	incidents := []Incident{
+		{Service: "checkout", RootCause: "error: database unavailable after 3 retries"},
+		{Service: "search", RootCause: "error: cache miss storm"},
+		{Service: "payments", RootCause: "Error: database unavailable after 5 retries (confirmed by traces)"},
+		{Service: "inventory", RootCause: "error: database unavailable after 3 retries"},
+		{Service: "frontend"},
+	}
+
+	collapsed, common := CollapseRootCauses(incidents, 2)
+
+	expected := []CommonRootCause{{
+		RootCause: "error: database unavailable after 3 retries",
+		Services:  []string{"checkout", "payments", "inventory"},
+	}}
+	if !reflect.DeepEqual(common, expected) {
+		t.Fatalf("Expected common root causes %+v, got %+v", expected, common)
+	}
+	for i, incident := range collapsed {
+		shared := i == 0 || i == 2 || i == 3
+		if incident.SharedRootCause != shared || (shared && incident.RootCause != "") {
+			t.Errorf("Expected %s shared=%t, got shared=%t with root cause %q", incident.Service, shared, incident.SharedRootCause, incident.RootCause)
+		}
+	}
+	if collapsed[1].RootCause != "error: cache miss storm" {
+		t.Errorf("Expected a service's own root cause to be kept, got %q", collapsed[1].RootCause)
+	}
+	if incidents[0].RootCause == "" {
+		t.Error("Expected the input incidents to be left untouched")
+	}
+
+	if _, common := CollapseRootCauses(incidents, 4); common != nil {
+		t.Errorf("Expected no collapse below the minimum services, got %+v", common)
+	}
+	if _, common := CollapseRootCauses(incidents, 0); common != nil {
+		t.Errorf("Expected collapsing disabled, got %+v", common)
+	}
+}
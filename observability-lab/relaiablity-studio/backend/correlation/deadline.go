@@ -0,0 +1,124 @@
+package correlation
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+var (
+	sourceDeadline  time.Duration
+	sourceDeadlines map[string]time.Duration
+)
+
+// SetSourceDeadlines sets how long BuildIncident waits for each source before
+// leaving it out as timed out, so a slow upstream doesn't hold up the
+// incident. overrides is keyed by source name (logs, metrics, traces, k8s).
+// Zero waits for the source however long it takes, which is the default.
+func SetSourceDeadlines(d time.Duration, overrides map[string]time.Duration) {
+	sourceDeadline, sourceDeadlines = d, overrides
+}
+
+func deadlineFor(name string) time.Duration {
+	if d, ok := sourceDeadlines[name]; ok {
+		return d
+	}
+	return sourceDeadline
+}
+
+// source fetches one signal. The returned apply stores the result, and is
+// only called when the source answered in time.
+type source struct {
+	name  string
+	fetch func(ctx context.Context) (apply func(), err error)
+}
+
+// fetched is a source's outcome, reported from its goroutine
+type fetched struct {
+	index    int
+	apply    func()
+	err      string
+	badShape bool
+}
+
+// gather runs the sources, recording failures in errs like collect. Without
+// deadlines they run one after another; otherwise concurrently, and a source
+// still running at its deadline is recorded as timed out, its context
+// cancelled and its late result dropped, leaving its health as timed out.
+func gather(errs map[string]string, sources []source) {
+	deadlines := false
+	for _, s := range sources {
+		deadlines = deadlines || deadlineFor(s.name) > 0
+	}
+	if !deadlines {
+		for _, s := range sources {
+			collect(errs, s.name, func() error {
+				apply, err := s.fetch(context.Background())
+				if apply != nil {
+					apply()
+				}
+				return err
+			})
+		}
+		return
+	}
+
+	start := time.Now()
+	results := make(chan fetched, len(sources))
+	cancels := make([]context.CancelFunc, len(sources))
+	for i, s := range sources {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancels[i] = cancel
+		go func(i int, s source) {
+			var apply func()
+			err, badShape := run(func() (err error) {
+				apply, err = s.fetch(ctx)
+				return err
+			})
+			results <- fetched{index: i, apply: apply, err: err, badShape: badShape}
+		}(i, s)
+	}
+
+	pending := make(map[int]bool, len(sources))
+	for i := range sources {
+		pending[i] = true
+	}
+	for len(pending) > 0 {
+		// Wake up at the earliest deadline still pending
+		var timeout <-chan time.Time
+		var next time.Duration
+		for i := range pending {
+			if d := deadlineFor(sources[i].name); d > 0 && (next == 0 || d < next) {
+				next = d
+			}
+		}
+		if next > 0 {
+			timeout = time.After(time.Until(start.Add(next)))
+		}
+
+		select {
+		case r := <-results:
+			if !pending[r.index] {
+				continue
+			}
+			delete(pending, r.index)
+			cancels[r.index]()
+			if r.apply != nil {
+				r.apply()
+			}
+			if r.err != "" {
+				errs[sources[r.index].name] = r.err
+			}
+			recordHealth(sources[r.index].name, r.err, r.badShape)
+		case <-timeout:
+			for i := range pending {
+				if d := deadlineFor(sources[i].name); d > 0 && time.Since(start) >= d {
+					delete(pending, i)
+					cancels[i]()
+					errs[sources[i].name] = fmt.Sprintf("timed out after %s", d)
+					recordHealth(sources[i].name, errs[sources[i].name], false)
+				}
+			}
+		}
+	}
+}
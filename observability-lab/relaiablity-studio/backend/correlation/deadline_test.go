@@ -0,0 +1,87 @@
+package correlation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+// slowMetrics answers metrics only after delay, giving up early when its
+// context is cancelled unless it ignores cancellation
+type slowMetrics struct {
+	fakeSources
+	delay        time.Duration
+	ignoreCancel bool
+	cancelled    chan struct{}
+}
+
+func (s slowMetrics) Metrics(ctx context.Context, service string) (analysis.MetricResult, error) {
+	done := ctx.Done()
+	if s.ignoreCancel {
+		done = nil
+	}
+	select {
+	case <-time.After(s.delay):
+		return s.fakeSources.Metrics(ctx, service)
+	case <-done:
+		close(s.cancelled)
+		return analysis.MetricResult{}, ctx.Err()
+	}
+}
+
+func TestBuildIncidentSourceDeadline(t *testing.T) {
+	SetSourceDeadlines(100*time.Millisecond, map[string]time.Duration{"k8s": 0})
+	defer SetSourceDeadlines(0, nil)
+
+	src := slowMetrics{
+		fakeSources: fakeSources{
+			logs:    analysis.LogResult{RootCause: "DB connection timeout", ErrorCount: 2},
+			metrics: analysis.MetricResult{ErrorRate: 50},
+			k8s:     analysis.K8sResult{BadPods: 1},
+		},
+		delay:     2 * time.Second,
+		cancelled: make(chan struct{}),
+	}
+
+	start := time.Now()
+	incident := BuildIncident("checkout", src)
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Fatalf("Expected incident within the soft deadline, took %s", elapsed)
+	}
+
+	if err := incident.SourceErrors["metrics"]; !strings.Contains(err, "timed out") {
+		t.Errorf("Expected metrics to be marked timed out, got %q", err)
+	}
+	if len(incident.SourceErrors) != 1 {
+		t.Errorf("Expected only metrics to be left out, got %v", incident.SourceErrors)
+	}
+	if incident.Impact.ErrorRate != 0 {
+		t.Errorf("Expected the late error rate to be dropped, got %f", incident.Impact.ErrorRate)
+	}
+	if incident.RootCause != "DB connection timeout" || incident.Impact.BadPods != 1 {
+		t.Errorf("Expected logs and k8s to be used, got root cause %q and %d bad pods", incident.RootCause, incident.Impact.BadPods)
+	}
+
+	select {
+	case <-src.cancelled:
+	case <-time.After(time.Second):
+		t.Error("Expected the timed-out source's context cancelled")
+	}
+}
+
+func TestBuildIncidentLateSourceKeepsTimedOutHealth(t *testing.T) {
+	SetSourceDeadlines(50*time.Millisecond, nil)
+	defer SetSourceDeadlines(0, nil)
+
+	start := time.Now()
+	BuildIncident("checkout", slowMetrics{delay: 200 * time.Millisecond, ignoreCancel: true})
+	time.Sleep(400 * time.Millisecond)
+
+	h := Health()["metrics"]
+	if !strings.Contains(h.LastError, "timed out") || (h.LastSuccess != nil && h.LastSuccess.After(start)) {
+		t.Errorf("Expected the late answer not to overwrite the timed-out health, got %+v", h)
+	}
+}
@@ -0,0 +1,33 @@
+package correlation
+
+import "sort"
+
+var dependencies map[string][]string
+
+// SetDependencyGraph sets the static service dependency graph, mapping each
+// service to the downstream services its failures propagate to. nil clears it.
+func SetDependencyGraph(graph map[string][]string) {
+	dependencies = graph
+}
+
+// ImpactedDownstream returns every service reachable from service in the
+// dependency graph, directly or through others, sorted. These are likely to
+// be affected before their own signals show it.
+func ImpactedDownstream(service string) []string {
+	seen := map[string]bool{service: true}
+	queue := []string{service}
+	var impacted []string
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, dep := range dependencies[next] {
+			if !seen[dep] {
+				seen[dep] = true
+				impacted = append(impacted, dep)
+				queue = append(queue, dep)
+			}
+		}
+	}
+	sort.Strings(impacted)
+	return impacted
+}
@@ -0,0 +1,40 @@
+package correlation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+func TestImpactedDownstream(t *testing.T) {
+	SetDependencyGraph(map[string][]string{
+		"checkout": {"payments", "inventory"},
+		"payments": {"ledger", "checkout"},
+		"search":   {"inventory"},
+	})
+	defer SetDependencyGraph(nil)
+
+	testCases := []struct {
+		service  string
+		expected []string
+	}{
+		{"checkout", []string{"inventory", "ledger", "payments"}},
+		{"search", []string{"inventory"}},
+		{"ledger", nil},
+	}
+	for _, tc := range testCases {
+		if got := ImpactedDownstream(tc.service); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Expected %s to impact %v, got %v", tc.service, tc.expected, got)
+		}
+	}
+
+	failing := correlate("checkout", analysis.LogResult{ErrorCount: 5, RootCause: "error: db down"}, analysis.MetricResult{ErrorRate: 8}, analysis.TraceResult{}, analysis.K8sResult{}, nil)
+	if !reflect.DeepEqual(failing.ImpactedDownstream, []string{"inventory", "ledger", "payments"}) {
+		t.Errorf("Expected the failing checkout incident to list its downstream services, got %v", failing.ImpactedDownstream)
+	}
+	healthy := correlate("checkout", analysis.LogResult{Events: []analysis.LogEvent{{Message: "ok"}}}, analysis.MetricResult{ErrorRate: 0.1}, analysis.TraceResult{}, analysis.K8sResult{}, nil)
+	if healthy.ImpactedDownstream != nil {
+		t.Errorf("Expected no impacted services for a healthy incident, got %v", healthy.ImpactedDownstream)
+	}
+}
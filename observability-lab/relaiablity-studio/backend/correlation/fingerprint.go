@@ -0,0 +1,81 @@
+package correlation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Parts of an incident that can make up its fingerprint
+const (
+	FingerprintService   = "service"
+	FingerprintRootCause = "root_cause"
+	FingerprintImpact    = "impact"
+)
+
+// DefaultFingerprintFields fingerprint incidents by all their parts
+var DefaultFingerprintFields = []string{FingerprintService, FingerprintRootCause, FingerprintImpact}
+
+var fingerprintFields = DefaultFingerprintFields
+
+// SetFingerprintFields picks the parts that make incidents the same recurring
+// issue. Unknown names are ignored; none restores DefaultFingerprintFields.
+func SetFingerprintFields(fields []string) {
+	if len(fields) == 0 {
+		fields = DefaultFingerprintFields
+	}
+	fingerprintFields = fields
+}
+
+// Variable parts of a root cause that differ between occurrences of the same
+// issue, most specific first
+var (
+	uuidPattern   = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	numberPattern = regexp.MustCompile(`\b(0x)?[0-9a-f]*[0-9][0-9a-f]*`) // Also hex IDs and the number in "3021ms"
+	spacesPattern = regexp.MustCompile(`\s+`)
+)
+
+// Fingerprint identifies an incident's underlying issue, so recurrences of it
+// can be grouped across time. Incidents of the same service, with root causes
+// that differ only in IDs, numbers and timestamps and the same kind of
+// impact share a fingerprint.
+func Fingerprint(incident Incident) string {
+	h := sha256.New()
+	for _, field := range fingerprintFields {
+		switch field {
+		case FingerprintService:
+			fmt.Fprintf(h, "service=%s\n", incident.Service)
+		case FingerprintRootCause:
+			fmt.Fprintf(h, "root_cause=%s\n", NormalizeRootCause(incident.RootCause))
+		case FingerprintImpact:
+			fmt.Fprintf(h, "impact=%s\n", impactSignature(incident.Impact))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// NormalizeRootCause reduces a root cause to its pattern: lowercased, with
+// UUIDs, hex IDs and numbers replaced and corroboration notes dropped
+func NormalizeRootCause(rootCause string) string {
+	if i := strings.Index(rootCause, " (confirmed by "); i >= 0 {
+		rootCause = rootCause[:i]
+	}
+	s := strings.ToLower(rootCause)
+	s = uuidPattern.ReplaceAllString(s, "<id>")
+	s = numberPattern.ReplaceAllString(s, "<n>")
+	return strings.TrimSpace(spacesPattern.ReplaceAllString(s, " "))
+}
+
+// impactSignature describes the kind of impact rather than its size: whether
+// the SLO and pods were affected and which kinds of trace failure occurred
+func impactSignature(impact Impact) string {
+	kinds := make([]string, 0, len(impact.FailureMix))
+	for kind := range impact.FailureMix {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return fmt.Sprintf("slo=%t pods=%t failures=%s", impact.SLOAffected, impact.BadPods > 0, strings.Join(kinds, ","))
+}
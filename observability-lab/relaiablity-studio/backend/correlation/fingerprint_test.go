@@ -0,0 +1,36 @@
+package correlation
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	base := Incident{
+		Service:   "checkout",
+		RootCause: `level=error msg="DB connection timeout" db=orders-primary request_id=8f14e45f-ceea-467a-9af0-2c5b6e0e1c6a after 3021ms`,
+		Impact:    Impact{SLOAffected: true, ErrorRate: 4.2, BadPods: 1},
+	}
+	recurrence := Incident{
+		Service:   "checkout",
+		RootCause: `level=error msg="DB connection timeout" db=orders-primary request_id=1c6a8f14-e45f-467a-9af0-ceea2c5b6e0e after 5120ms (confirmed by traces)`,
+		Impact:    Impact{SLOAffected: true, ErrorRate: 7.9, BadPods: 3},
+	}
+	otherCause := base
+	otherCause.RootCause = `level=error msg="payment gateway unreachable"`
+	otherService := base
+	otherService.Service = "payments"
+
+	if Fingerprint(base) != Fingerprint(recurrence) {
+		t.Errorf("Expected structurally identical incidents to share a fingerprint")
+	}
+	if Fingerprint(base) == Fingerprint(otherCause) {
+		t.Errorf("Expected a different root cause to change the fingerprint")
+	}
+	if Fingerprint(base) == Fingerprint(otherService) {
+		t.Errorf("Expected a different service to change the fingerprint")
+	}
+
+	SetFingerprintFields([]string{FingerprintRootCause})
+	defer SetFingerprintFields(nil)
+	if Fingerprint(base) != Fingerprint(otherService) {
+		t.Errorf("Expected services to be ignored when only the root cause is fingerprinted")
+	}
+}
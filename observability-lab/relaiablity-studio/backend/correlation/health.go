@@ -0,0 +1,50 @@
+package correlation
+
+import (
+	"sync"
+	"time"
+)
+
+// SourceHealth describes the most recent collections from one source
+type SourceHealth struct {
+	LastSuccess   *time.Time `json:"last_success,omitempty"` // Last time the source parsed cleanly
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+	ExpectedShape bool       `json:"expected_shape"` // Whether the last response parsed as the analyzer expects
+}
+
+var (
+	healthMu     sync.Mutex
+	sourceHealth = make(map[string]SourceHealth)
+)
+
+// Health returns a snapshot of every source's health, keyed by source name
+func Health() map[string]SourceHealth {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+
+	snapshot := make(map[string]SourceHealth, len(sourceHealth))
+	for name, h := range sourceHealth {
+		snapshot[name] = h
+	}
+	return snapshot
+}
+
+// recordHealth notes the outcome of one collection. badShape means the
+// response didn't have the shape the analyzer expects.
+func recordHealth(name string, err string, badShape bool) {
+	now := time.Now()
+
+	healthMu.Lock()
+	defer healthMu.Unlock()
+
+	h := sourceHealth[name]
+	h.ExpectedShape = !badShape
+	if err == "" {
+		h.LastSuccess = &now
+	} else {
+		h.LastError = err
+		h.LastErrorAt = &now
+	}
+	sourceHealth[name] = h
+}
@@ -0,0 +1,48 @@
+package correlation
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator builds an incident ID for a service at a point in time
+type IDGenerator func(service string, at time.Time) string
+
+// IDSchemes are the built-in generators, selectable by name in config
+var IDSchemes = map[string]IDGenerator{
+	"timestamp": TimestampID,
+	"uuid":      UUIDID,
+}
+
+var newIncidentID IDGenerator = TimestampID
+
+// SetIDGenerator replaces the incident ID scheme. nil restores TimestampID.
+func SetIDGenerator(g IDGenerator) {
+	if g == nil {
+		g = TimestampID
+	}
+	newIncidentID = g
+}
+
+// TimestampID renders IDs like "checkout-20240115T0214Z-a1b2c3d4". The
+// suffix is random, so replicas and restarts don't repeat each other's IDs.
+func TimestampID(service string, at time.Time) string {
+	return fmt.Sprintf("%s-%s-%08x", service, at.UTC().Format("20060102T1504Z"), randomSuffix())
+}
+
+// UUIDID renders IDs like "checkout-6f1c2e0a-..."
+func UUIDID(service string, at time.Time) string {
+	return service + "-" + uuid.NewString()
+}
+
+func randomSuffix() uint32 {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return uint32(time.Now().UnixNano())
+	}
+	return binary.BigEndian.Uint32(b[:])
+}
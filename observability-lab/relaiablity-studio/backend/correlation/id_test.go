@@ -0,0 +1,40 @@
+package correlation
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestampIDFormat(t *testing.T) {
+	at := time.Date(2024, 1, 15, 2, 14, 37, 0, time.UTC)
+	id := TimestampID("checkout", at)
+
+	if !regexp.MustCompile(`^checkout-20240115T0214Z-[0-9a-f]{8}$`).MatchString(id) {
+		t.Errorf("Expected checkout-20240115T0214Z-xxxxxxxx, got %q", id)
+	}
+}
+
+func TestIncidentIDsUniqueWithinSecond(t *testing.T) {
+	at := time.Date(2024, 1, 15, 2, 14, 37, 0, time.UTC)
+	if a, b := TimestampID("checkout", at), TimestampID("checkout", at); a == b {
+		t.Errorf("Expected distinct IDs for the same service and second, got %q twice", a)
+	}
+
+	first := BuildIncident("checkout", fakeSources{})
+	second := BuildIncident("checkout", fakeSources{})
+	if first.ID == second.ID {
+		t.Errorf("Expected distinct incident IDs, got %q twice", first.ID)
+	}
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	SetIDGenerator(UUIDID)
+	defer SetIDGenerator(nil)
+
+	id := BuildIncident("checkout", fakeSources{}).ID
+	if !strings.HasPrefix(id, "checkout-") || len(id) != len("checkout-")+36 {
+		t.Errorf("Expected checkout-<uuid>, got %q", id)
+	}
+}
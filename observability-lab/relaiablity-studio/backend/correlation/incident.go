@@ -0,0 +1,404 @@
+package correlation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+// Incident is the correlated view of a service built from logs, metrics, traces and k8s
+type Incident struct {
+	ID        string  `json:"id"`
+	Service   string  `json:"service"`
+	Severity  string  `json:"severity"`
+	RootCause string  `json:"root_cause"`
+	Summary   string  `json:"summary"`
+	Impact    Impact  `json:"impact"`
+	Timeline  []Event `json:"timeline"`
+
+	// Priority (P1 to P4) is how much the incident matters to the business:
+	// its severity, raised or lowered by the service's tier. See Priority.
+	Priority string `json:"priority,omitempty"`
+
+	// Anchor is the earliest high-severity signal; the timeline is windowed
+	// around it. See Anchor and SetAnchorWindow.
+	Anchor *time.Time `json:"anchor,omitempty"`
+
+	// Confidence (0-1) in the asserted root cause. LowConfidence flags
+	// non-healthy incidents that operators should dig into before trusting.
+	Confidence    float64 `json:"confidence"`
+	LowConfidence bool    `json:"low_confidence"`
+
+	// SourceErrors records sources that failed and were left out of the incident
+	SourceErrors map[string]string `json:"source_errors,omitempty"`
+
+	// Fingerprint is shared by recurrences of the same issue; see Fingerprint
+	Fingerprint string `json:"fingerprint"`
+
+	// CausalChain orders the services failing together from likely cause to
+	// effect, when traces show more than one
+	CausalChain []string `json:"causal_chain,omitempty"`
+
+	// ImpactedDownstream are the services a failing service's failures are
+	// likely to reach, from the dependency graph; see ImpactedDownstream
+	ImpactedDownstream []string `json:"impacted_downstream,omitempty"`
+
+	// SharedRootCause is set when the root cause was collapsed into a common
+	// root cause of several services; see CollapseRootCauses
+	SharedRootCause bool `json:"shared_root_cause,omitempty"`
+
+	// Suppressed is set while the service is acknowledged or in maintenance,
+	// so no notifications are sent for the incident
+	Suppressed bool `json:"suppressed,omitempty"`
+
+	// ComputedAt is when the incident's signals were fetched. DataAgeSeconds
+	// is how old they were when the response was served, e.g. from a cache.
+	ComputedAt     time.Time `json:"computed_at"`
+	DataAgeSeconds float64   `json:"data_age_seconds"`
+
+	// FirstSeen is when the service's current run of non-healthy incidents
+	// began; it is set by the incident store. Duration (seconds) is how long
+	// the incident had been ongoing when served, and IsNew whether that is
+	// within the new-incident window.
+	FirstSeen *time.Time `json:"first_seen,omitempty"`
+	Duration  float64    `json:"duration,omitempty"`
+	IsNew     bool       `json:"is_new,omitempty"`
+
+	// Dashboards link to the configured Grafana dashboards for the service
+	// over the incident's time range; they are added when it is served
+	Dashboards []DashboardLink `json:"dashboards,omitempty"`
+}
+
+// DefaultNewIncidentWindow is how long an incident counts as new
+const DefaultNewIncidentWindow = 15 * time.Minute
+
+var newIncidentWindow = DefaultNewIncidentWindow
+
+// SetNewIncidentWindow sets how long after it was first seen an incident
+// counts as new rather than ongoing. d <= 0 restores DefaultNewIncidentWindow.
+func SetNewIncidentWindow(d time.Duration) {
+	if d <= 0 {
+		d = DefaultNewIncidentWindow
+	}
+	newIncidentWindow = d
+}
+
+// WithAge returns the incident with DataAgeSeconds, and Duration and IsNew if
+// it has been seen before, set as of now
+func (i Incident) WithAge(now time.Time) Incident {
+	i.DataAgeSeconds = 0
+	if !i.ComputedAt.IsZero() {
+		i.DataAgeSeconds = math.Round(now.Sub(i.ComputedAt).Seconds()*1000) / 1000
+	}
+	i.Duration, i.IsNew = 0, false
+	if i.FirstSeen != nil {
+		ongoing := max(now.Sub(*i.FirstSeen), 0)
+		i.Duration = math.Round(ongoing.Seconds()*1000) / 1000
+		i.IsNew = ongoing < newIncidentWindow
+	}
+	return i
+}
+
+// Sources fetches and analyzes each upstream signal for a service. ctx is
+// cancelled when BuildIncident stops waiting for the signal.
+type Sources interface {
+	Logs(ctx context.Context, service string) (analysis.LogResult, error)
+	Metrics(ctx context.Context, service string) (analysis.MetricResult, error)
+	Traces(ctx context.Context, service string) (analysis.TraceResult, error)
+	K8s(ctx context.Context, service string) (analysis.K8sResult, error)
+}
+
+// LowConfidenceThreshold is the confidence below which an incident is flagged
+const LowConfidenceThreshold = 0.5
+
+// DefaultNoDataSeverity is reported when a service has no metrics or logs at all
+const DefaultNoDataSeverity = "no_data"
+
+var noDataSeverity = DefaultNoDataSeverity
+
+// SetNoDataSeverity overrides the severity reported for services without any
+// data. An empty value restores DefaultNoDataSeverity.
+func SetNoDataSeverity(severity string) {
+	if severity == "" {
+		severity = DefaultNoDataSeverity
+	}
+	noDataSeverity = severity
+}
+
+var latencyWarning, latencyCritical time.Duration
+
+// SetLatencyThresholds sets the p95 latency at which a service is graded
+// warning and critical regardless of its error rate. Zero disables a level.
+func SetLatencyThresholds(warning, critical time.Duration) {
+	latencyWarning, latencyCritical = warning, critical
+}
+
+var traceFailureWarning, traceFailureCritical float64
+
+// SetTraceFailureThresholds sets the percentage of failed traces at which a
+// service is graded warning and critical, even without error logs or a high
+// error rate. Zero disables a level.
+func SetTraceFailureThresholds(warning, critical float64) {
+	traceFailureWarning, traceFailureCritical = warning, critical
+}
+
+// BuildIncident collects every source for a service and correlates them into a
+// single incident. Sources are best-effort: one that fails is recorded in
+// SourceErrors and the incident is built from the sources that succeeded.
+// With source deadlines set, sources that are too slow are left out the same
+// way; see SetSourceDeadlines.
+func BuildIncident(service string, src Sources) Incident {
+	var (
+		logs    analysis.LogResult
+		metrics analysis.MetricResult
+		traces  analysis.TraceResult
+		k8s     analysis.K8sResult
+	)
+	errs := make(map[string]string)
+
+	gather(errs, []source{
+		{"logs", func(ctx context.Context) (func(), error) {
+			res, err := src.Logs(ctx, service)
+			return func() { logs = res }, err
+		}},
+		{"metrics", func(ctx context.Context) (func(), error) {
+			res, err := src.Metrics(ctx, service)
+			if err != nil {
+				return func() { metrics = res }, err
+			}
+			// An out-of-range rate would silently skew severity, so drop it
+			if err = analysis.ValidateErrorRate(res.ErrorRate); err != nil {
+				res = analysis.MetricResult{}
+			}
+			return func() { metrics = res }, err
+		}},
+		{"traces", func(ctx context.Context) (func(), error) {
+			res, err := src.Traces(ctx, service)
+			return func() { traces = res }, err
+		}},
+		{"k8s", func(ctx context.Context) (func(), error) {
+			res, err := src.K8s(ctx, service)
+			return func() { k8s = res }, err
+		}},
+	})
+
+	return correlate(service, logs, metrics, traces, k8s, errs)
+}
+
+// collect runs a single source, recording its error (or panic from a malformed
+// upstream response) under name instead of failing the whole build
+func collect(errs map[string]string, name string, fetch func() error) {
+	err, badShape := run(fetch)
+	if err != "" {
+		errs[name] = err
+	}
+	recordHealth(name, err, badShape)
+}
+
+// run calls fetch, returning its error or panic, and whether the upstream
+// response had an unexpected shape
+func run(fetch func() error) (msg string, badShape bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg, badShape = fmt.Sprintf("%v", r), true
+		}
+	}()
+	if err := fetch(); err != nil {
+		return err.Error(), errors.Is(err, analysis.ErrInvalidResponse)
+	}
+	return "", false
+}
+
+// correlate merges already-analyzed signals into an incident using the
+// configured strategy
+func correlate(service string, logs analysis.LogResult, metrics analysis.MetricResult, traces analysis.TraceResult, k8s analysis.K8sResult, sourceErrors map[string]string) Incident {
+	signals := Signals{Service: service, Logs: logs, Metrics: metrics, Traces: traces, K8s: k8s, SourceErrors: sourceErrors}
+
+	impact := Impact{
+		SLOAffected:    metrics.ErrorRate > 1,
+		ErrorRate:      metrics.ErrorRate,
+		BadPods:        k8s.BadPods,
+		DegradedPods:   k8s.DegradedPods,
+		DegradedTraces: traces.DegradedFor(service),
+	}
+	if estimated := traces.EstimatedFailuresFor(service); estimated > float64(traces.FailuresFor(service)) {
+		impact.EstimatedTraceFailures = estimated
+	}
+	if len(metrics.Outliers) > 0 {
+		impact.OutlierInstances = metrics.Outliers
+	}
+	if len(traces.FailureKinds) > 0 {
+		impact.FailureMix = traces.FailureKinds
+	}
+	severity := strategy.Severity(signals)
+	rootCause := strategy.RootCause(signals)
+	confidence := calculateConfidence(logs, metrics, traces.FailuresFor(service), k8s)
+
+	// Linking after windowing keeps links from pointing at events the
+	// window dropped
+	timeline := classified(strategy.Timeline(signals))
+	anchor, anchored := Anchor(timeline)
+	if anchored {
+		timeline = windowAround(timeline, anchor)
+	}
+	timeline = LinkOOMKills(timeline)
+	impact.BlastRadius = ComputeBlastRadius(service, timeline, metrics, traces, k8s)
+
+	now := time.Now()
+	incident := Incident{
+		ID:        newIncidentID(service, now),
+		Service:   service,
+		Severity:  severity,
+		Priority:  Priority(service, severity),
+		RootCause: rootCause,
+		Summary:   Summarize(service, severity, impact, rootCause),
+		Impact:    impact,
+		Timeline:  timeline,
+
+		Confidence:    confidence,
+		LowConfidence: severity != "healthy" && confidence < LowConfidenceThreshold,
+		CausalChain:   CausalChain(traces),
+
+		ComputedAt: now.UTC(),
+	}
+	if anchored {
+		anchor = anchor.UTC()
+		incident.Anchor = &anchor
+	}
+	if severity == "warning" || severity == "critical" {
+		incident.ImpactedDownstream = ImpactedDownstream(service)
+	}
+	if len(sourceErrors) > 0 {
+		incident.SourceErrors = sourceErrors
+	}
+	incident.Fingerprint = Fingerprint(incident)
+	return incident
+}
+
+// classified types any events a strategy left untyped
+func classified(timeline []Event) []Event {
+	for i, e := range timeline {
+		if e.Type == "" {
+			timeline[i].Type = ClassifyEvent(e.Source, "", e.Message)
+		}
+	}
+	return timeline
+}
+
+// calculateSeverity grades the signals that were collected. When a source is
+// missing, the absence of problems can't be trusted, so "unknown" is reported
+// instead of "healthy". Likewise a service with no metrics and no logs is a
+// monitoring gap rather than a healthy service. Latency is the p95 over the
+// query's rate window, so a single slow request doesn't breach a threshold.
+// traceFailureRate is the percentage of the service's traces that failed;
+// degradedTraces, like degraded pods, warn.
+func calculateSeverity(logs analysis.LogResult, metrics analysis.MetricResult, traceFailureRate float64, degradedTraces int, k8s analysis.K8sResult, missingData bool) string {
+	latency := time.Duration(metrics.Latency * float64(time.Second))
+	if k8s.BadPods > 0 && (metrics.ErrorRate > 1 || logs.ErrorCount > 0) {
+		return "critical"
+	}
+	if latencyCritical > 0 && latency >= latencyCritical {
+		return "critical"
+	}
+	if traceFailureCritical > 0 && traceFailureRate >= traceFailureCritical {
+		return "critical"
+	}
+	if k8s.BadPods > 0 || k8s.DegradedPods > 0 || degradedTraces > 0 || metrics.ErrorRate > 1 || logs.ErrorCount > 0 {
+		return "warning"
+	}
+	if latencyWarning > 0 && latency >= latencyWarning {
+		return "warning"
+	}
+	if traceFailureWarning > 0 && traceFailureRate >= traceFailureWarning {
+		return "warning"
+	}
+	if missingData {
+		return "unknown"
+	}
+	if metrics.NoData && logs.NoData {
+		return noDataSeverity
+	}
+	return "healthy"
+}
+
+// calculateConfidence scores how strongly the signals support the incident.
+// The strongest single signal sets the base, and each additional source that
+// agrees (reports a problem) raises it further. traceFailures counts only the
+// failed traces attributed to the service.
+func calculateConfidence(logs analysis.LogResult, metrics analysis.MetricResult, traceFailures int, k8s analysis.K8sResult) float64 {
+	strengths := []float64{
+		saturate(float64(logs.ErrorCount), 10),
+		saturate(metrics.ErrorRate, 5),
+		saturate(float64(traceFailures), 5),
+		saturate(float64(k8s.BadPods), 2),
+	}
+
+	strongest := 0.0
+	agreeing := 0
+	for _, s := range strengths {
+		if s > 0 {
+			agreeing++
+		}
+		if s > strongest {
+			strongest = s
+		}
+	}
+	if agreeing == 0 {
+		return 0
+	}
+
+	agreement := float64(agreeing-1) / float64(len(strengths)-1)
+	return 0.6*strongest + 0.4*agreement
+}
+
+// saturate maps v onto 0-1, reaching 1 at full
+func saturate(v, full float64) float64 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= full {
+		return 1
+	}
+	return v / full
+}
+
+// Summarize renders a one-line description of an incident, e.g.
+// "Checkout degraded: 4.2% error rate, 2 failed pods, root cause: DB connection timeout"
+func Summarize(service, severity string, impact Impact, rootCause string) string {
+	state := "degraded"
+	switch severity {
+	case "healthy":
+		state = "healthy"
+	case SeverityRecovering:
+		state = "recovering"
+	case "critical":
+		state = "critical"
+	case "unknown":
+		state = "status unknown"
+	case DefaultNoDataSeverity:
+		state = "has no data"
+	}
+
+	pods := fmt.Sprintf("%d failed pods", impact.BadPods)
+	if impact.BadPods == 1 {
+		pods = "1 failed pod"
+	}
+
+	summary := fmt.Sprintf("%s %s: %.1f%% error rate, %s", displayName(service), state, impact.ErrorRate, pods)
+	if rootCause != "" {
+		summary += ", root cause: " + rootCause
+	}
+	return summary
+}
+
+func displayName(service string) string {
+	if service == "" {
+		return service
+	}
+	return strings.ToUpper(service[:1]) + service[1:]
+}
@@ -0,0 +1,374 @@
+package correlation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+func TestSummarize(t *testing.T) {
+	testCases := []struct {
+		name      string
+		severity  string
+		impact    Impact
+		rootCause string
+		expected  string
+	}{
+		{
+			"Degraded with root cause", "warning",
+			Impact{ErrorRate: 4.2, BadPods: 2}, "DB connection timeout",
+			"Checkout degraded: 4.2% error rate, 2 failed pods, root cause: DB connection timeout",
+		},
+		{
+			"Single failed pod", "critical",
+			Impact{ErrorRate: 12.34, BadPods: 1}, "",
+			"Checkout critical: 12.3% error rate, 1 failed pod",
+		},
+		{
+			"Healthy", "healthy",
+			Impact{}, "",
+			"Checkout healthy: 0.0% error rate, 0 failed pods",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Summarize("checkout", tc.severity, tc.impact, tc.rootCause); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildIncidentSummary(t *testing.T) {
+	logs := analysis.LogResult{
+		RootCause:  "DB connection timeout",
+		ErrorCount: 3,
+		Events:     []analysis.LogEvent{{Time: "1700000000000000000", Message: "DB connection timeout"}},
+	}
+	metrics := analysis.MetricResult{ErrorRate: 4.2}
+	k8s := analysis.K8sResult{BadPods: 2, Events: []analysis.K8sEvent{{Time: "2024-01-15T02:14:00Z", Message: "Pod failed"}}}
+
+	incident := correlate("checkout", logs, metrics, analysis.TraceResult{}, k8s, nil)
+
+	expected := "Checkout critical: 4.2% error rate, 2 failed pods, root cause: DB connection timeout"
+	if incident.Summary != expected {
+		t.Errorf("Expected summary %q, got %q", expected, incident.Summary)
+	}
+	if len(incident.Timeline) != 2 {
+		t.Errorf("Expected 2 timeline events, got %d", len(incident.Timeline))
+	}
+}
+
+func TestConfidenceStrongVsWeakSignal(t *testing.T) {
+	strong := correlate("checkout",
+		analysis.LogResult{RootCause: "panic: nil map", ErrorCount: 40},
+		analysis.MetricResult{ErrorRate: 12},
+		analysis.TraceResult{Failures: 15},
+		analysis.K8sResult{BadPods: 3},
+		nil,
+	)
+	weak := correlate("checkout",
+		analysis.LogResult{RootCause: "error: retrying", ErrorCount: 1},
+		analysis.MetricResult{},
+		analysis.TraceResult{},
+		analysis.K8sResult{},
+		nil,
+	)
+
+	if strong.Confidence <= weak.Confidence {
+		t.Errorf("Expected strong signal (%f) to be more confident than weak (%f)", strong.Confidence, weak.Confidence)
+	}
+	if strong.Confidence < 0.9 || strong.LowConfidence {
+		t.Errorf("Expected high confidence for multi-source signal, got %f (low=%v)", strong.Confidence, strong.LowConfidence)
+	}
+	if weak.Confidence >= LowConfidenceThreshold || !weak.LowConfidence {
+		t.Errorf("Expected weak single-source signal to be flagged low confidence, got %f (low=%v)", weak.Confidence, weak.LowConfidence)
+	}
+}
+
+func TestConfidenceUsesServiceTraceFailures(t *testing.T) {
+	logs := analysis.LogResult{RootCause: "error: retrying", ErrorCount: 1}
+	traces := analysis.TraceResult{Failures: 15, ServiceFailures: map[string]int{"payments": 15}}
+
+	withoutTraces := correlate("checkout", logs, analysis.MetricResult{}, analysis.TraceResult{}, analysis.K8sResult{}, nil)
+	otherService := correlate("checkout", logs, analysis.MetricResult{}, traces, analysis.K8sResult{}, nil)
+	if otherService.Confidence != withoutTraces.Confidence {
+		t.Errorf("Expected another service's trace failures ignored, got confidence %f vs %f", otherService.Confidence, withoutTraces.Confidence)
+	}
+
+	sameService := correlate("payments", logs, analysis.MetricResult{}, traces, analysis.K8sResult{}, nil)
+	if sameService.Confidence <= withoutTraces.Confidence {
+		t.Errorf("Expected the service's own trace failures to raise confidence, got %f", sameService.Confidence)
+	}
+}
+
+func TestConfidenceHealthyNotFlagged(t *testing.T) {
+	incident := correlate("checkout", analysis.LogResult{}, analysis.MetricResult{}, analysis.TraceResult{}, analysis.K8sResult{}, nil)
+	if incident.Confidence != 0 || incident.LowConfidence {
+		t.Errorf("Expected healthy incident to have no confidence flag, got %f (low=%v)", incident.Confidence, incident.LowConfidence)
+	}
+}
+
+// fakeSources returns canned results, failing any source listed in errs
+type fakeSources struct {
+	logs    analysis.LogResult
+	metrics analysis.MetricResult
+	traces  analysis.TraceResult
+	k8s     analysis.K8sResult
+	errs    map[string]error
+}
+
+func (f fakeSources) Logs(context.Context, string) (analysis.LogResult, error) {
+	return f.logs, f.errs["logs"]
+}
+func (f fakeSources) Metrics(context.Context, string) (analysis.MetricResult, error) {
+	return f.metrics, f.errs["metrics"]
+}
+func (f fakeSources) Traces(context.Context, string) (analysis.TraceResult, error) {
+	return f.traces, f.errs["traces"]
+}
+func (f fakeSources) K8s(context.Context, string) (analysis.K8sResult, error) {
+	if err, ok := f.errs["k8s"]; ok && err == nil {
+		// Simulate the analyzer panicking on a malformed kubectl response
+		panic("malformed kubectl response")
+	}
+	return f.k8s, f.errs["k8s"]
+}
+
+func TestBuildIncidentK8sFailure(t *testing.T) {
+	src := fakeSources{
+		logs:    analysis.LogResult{RootCause: "error: DB connection timeout", ErrorCount: 4},
+		metrics: analysis.MetricResult{ErrorRate: 4.2},
+		errs:    map[string]error{"k8s": errors.New("kubectl: connection refused")},
+	}
+
+	incident := BuildIncident("checkout", src)
+
+	if incident.SourceErrors["k8s"] != "kubectl: connection refused" {
+		t.Errorf("Expected k8s error to be recorded, got %v", incident.SourceErrors)
+	}
+	if len(incident.SourceErrors) != 1 {
+		t.Errorf("Expected only k8s to fail, got %v", incident.SourceErrors)
+	}
+	if incident.Severity != "warning" || incident.Impact.ErrorRate != 4.2 {
+		t.Errorf("Expected incident built from logs and metrics, got severity=%s impact=%+v", incident.Severity, incident.Impact)
+	}
+}
+
+func TestBuildIncidentRecoversAnalyzerPanic(t *testing.T) {
+	src := fakeSources{errs: map[string]error{"k8s": nil}}
+
+	incident := BuildIncident("checkout", src)
+
+	if _, ok := incident.SourceErrors["k8s"]; !ok {
+		t.Fatalf("Expected k8s panic to be recorded, got %v", incident.SourceErrors)
+	}
+	// With no problems seen but a source missing, healthy can't be asserted
+	if incident.Severity != "unknown" {
+		t.Errorf("Expected severity unknown, got %s", incident.Severity)
+	}
+}
+
+func TestBuildIncidentErrorRateUnits(t *testing.T) {
+	src := fakeSources{metrics: analysis.MetricResult{ErrorRate: 4.2}}
+
+	incident := BuildIncident("checkout", src)
+
+	// Percent in, percent out: impact, severity and summary all read 4.2 as 4.2%
+	if incident.Impact.ErrorRate != 4.2 || !incident.Impact.SLOAffected {
+		t.Errorf("Expected 4.2%% error rate to affect the SLO, got %+v", incident.Impact)
+	}
+	if incident.Severity != "warning" {
+		t.Errorf("Expected severity warning, got %s", incident.Severity)
+	}
+	if want := "Checkout degraded: 4.2% error rate, 0 failed pods"; incident.Summary != want {
+		t.Errorf("Expected summary %q, got %q", want, incident.Summary)
+	}
+}
+
+func TestBuildIncidentRejectsErrorRateOver100(t *testing.T) {
+	src := fakeSources{metrics: analysis.MetricResult{ErrorRate: 420}}
+
+	incident := BuildIncident("checkout", src)
+
+	if _, ok := incident.SourceErrors["metrics"]; !ok {
+		t.Errorf("Expected out-of-range error rate to be flagged, got %v", incident.SourceErrors)
+	}
+	if incident.Impact.ErrorRate != 0 {
+		t.Errorf("Expected invalid error rate to be dropped, got %v", incident.Impact.ErrorRate)
+	}
+	if incident.Severity != "unknown" {
+		t.Errorf("Expected severity unknown, got %s", incident.Severity)
+	}
+}
+
+// must unwraps an analyzer result built from a known-good fixture
+func must[T any](res T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func TestBuildIncidentNoData(t *testing.T) {
+	// Every upstream answered successfully, but nothing matched the service
+	empty := fakeSources{
+		logs:    must(analysis.AnalyzeLogs("ghost", `{"status":"success","data":{"resultType":"streams","result":[]}}`)),
+		metrics: must(analysis.AnalyzeMetrics(`{"status":"success","data":{"resultType":"vector","result":[]}}`)),
+		traces:  must(analysis.AnalyzeTraces(`{"traces":[]}`)),
+		k8s:     must(analysis.AnalyzeK8s("ghost", `{"items":[]}`)),
+	}
+
+	incident := BuildIncident("ghost", empty)
+	if incident.Severity != DefaultNoDataSeverity {
+		t.Errorf("Expected severity %s, got %s", DefaultNoDataSeverity, incident.Severity)
+	}
+	if len(incident.SourceErrors) != 0 {
+		t.Errorf("Expected no source errors for valid empty responses, got %v", incident.SourceErrors)
+	}
+	if want := "Ghost has no data: 0.0% error rate, 0 failed pods"; incident.Summary != want {
+		t.Errorf("Expected summary %q, got %q", want, incident.Summary)
+	}
+
+	// Healthy data is still healthy
+	healthy := empty
+	healthy.metrics = must(analysis.AnalyzeMetrics(`{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"service":"checkout"},"value":[1705302840,"0"]}
+	]}}`))
+	if got := BuildIncident("checkout", healthy).Severity; got != "healthy" {
+		t.Errorf("Expected service with zero error rate to be healthy, got %s", got)
+	}
+
+	SetNoDataSeverity("healthy")
+	defer SetNoDataSeverity("")
+	if got := BuildIncident("ghost", empty).Severity; got != "healthy" {
+		t.Errorf("Expected configured no-data severity, got %s", got)
+	}
+}
+
+func TestSeverityLatencyOnly(t *testing.T) {
+	SetLatencyThresholds(500*time.Millisecond, 2*time.Second)
+	defer SetLatencyThresholds(0, 0)
+
+	testCases := []struct {
+		name     string
+		latency  float64 // p95 in seconds
+		expected string
+	}{
+		{"Normal latency", 0.12, "healthy"},
+		{"Latency over warning", 1.2, "warning"},
+		{"Latency 10x normal", 2.4, "critical"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := fakeSources{metrics: analysis.MetricResult{ErrorRate: 0, Latency: tc.latency}}
+			if got := BuildIncident("checkout", src).Severity; got != tc.expected {
+				t.Errorf("Expected severity %s, got %s", tc.expected, got)
+			}
+		})
+	}
+
+	SetLatencyThresholds(0, 0)
+	src := fakeSources{metrics: analysis.MetricResult{Latency: 2.4}}
+	if got := BuildIncident("checkout", src).Severity; got != "healthy" {
+		t.Errorf("Expected latency ignored without thresholds, got %s", got)
+	}
+}
+
+func TestSeverityTraceFailures(t *testing.T) {
+	SetTraceFailureThresholds(10, 50)
+	defer SetTraceFailureThresholds(0, 0)
+
+	testCases := []struct {
+		name     string
+		failures int
+		expected string
+	}{
+		{"Few failed traces", 1, "healthy"},
+		{"Failures over warning", 20, "warning"},
+		{"80% of traces failing", 80, "critical"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := fakeSources{
+				logs:    analysis.LogResult{},
+				metrics: analysis.MetricResult{},
+				traces:  analysis.TraceResult{Total: 100, Failures: tc.failures, ServiceFailures: map[string]int{"checkout": tc.failures}},
+			}
+			if got := BuildIncident("checkout", src).Severity; got != tc.expected {
+				t.Errorf("Expected severity %s, got %s", tc.expected, got)
+			}
+		})
+	}
+
+	SetTraceFailureThresholds(0, 0)
+	src := fakeSources{traces: analysis.TraceResult{Total: 100, Failures: 80}}
+	if got := BuildIncident("checkout", src).Severity; got != "healthy" {
+		t.Errorf("Expected trace failures ignored without thresholds, got %s", got)
+	}
+}
+
+func TestSeverityUnrecognizedTraceStatus(t *testing.T) {
+	SetTraceFailureThresholds(10, 50)
+	defer SetTraceFailureThresholds(0, 0)
+	defer analysis.SetStatusFallbacks(nil)
+
+	raw := `{"traces":[
+		{"traceID":"a1","rootServiceName":"checkout","status":"unset"},
+		{"traceID":"b2","rootServiceName":"checkout","status":"ok"},
+		{"traceID":"c3","rootServiceName":"checkout","status":"ok"},
+		{"traceID":"d4","rootServiceName":"checkout","status":"ok"}
+	]}`
+	testCases := []struct {
+		name      string
+		fallbacks map[string]string
+		expected  string
+		degraded  int
+	}{
+		{"unmapped fails", nil, "warning", 0},
+		{"mapped to degraded", map[string]string{"unset": analysis.StatusDegraded}, "warning", 1},
+		{"mapped to ok", map[string]string{"unset": analysis.StatusOK}, "healthy", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis.SetStatusFallbacks(tc.fallbacks)
+			traces, err := analysis.AnalyzeTraces(raw)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			incident := correlate("checkout", analysis.LogResult{}, analysis.MetricResult{}, traces, analysis.K8sResult{}, nil)
+			if incident.Severity != tc.expected || incident.Impact.DegradedTraces != tc.degraded {
+				t.Errorf("Expected %s with %d degraded traces, got %s with %d", tc.expected, tc.degraded, incident.Severity, incident.Impact.DegradedTraces)
+			}
+		})
+	}
+}
+
+func TestImpactJSONPrecision(t *testing.T) {
+	incident := Incident{Impact: Impact{
+		ErrorRate:        4.299999999998,
+		OutlierInstances: map[string]float64{"10.0.0.7:8080": 31.234567},
+	}}
+
+	encoded, err := json.Marshal(incident)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{`"error_rate":4.3,`, `"10.0.0.7:8080":31.235`} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("Expected %s in %s", want, encoded)
+		}
+	}
+	if incident.Impact.OutlierInstances["10.0.0.7:8080"] != 31.234567 {
+		t.Error("Expected the incident itself to keep full precision")
+	}
+}
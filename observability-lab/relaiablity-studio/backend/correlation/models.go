@@ -1,13 +1,45 @@
 package correlation
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/utils"
 )
 
 type Event struct {
-	Time    string `json:"time"`
-	Source  string `json:"source"`
-	Message string `json:"message"`
+	Time    string            `json:"time"`
+	Source  string            `json:"source"`
+	Type    string            `json:"type"` // One of the Event* types, see ClassifyEvent
+	Message string            `json:"message"`
+	Cluster string            `json:"cluster,omitempty"` // Kubernetes events only, when several clusters are configured
+	Labels  map[string]string `json:"labels,omitempty"`  // Log events and latency spikes: trace_id, span_id and other correlation fields
+	Links   []EventLink       `json:"links,omitempty"`   // Other events in the timeline this one is causally related to
+
+	// Metadata is the structured metadata Loki attached to a log line
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Count is how many log lines had this message, when duplicates are collapsed
+	Count int `json:"count,omitempty"`
+
+	// Namespace and Instance locate Kubernetes events; Instance is the pod
+	Namespace string `json:"namespace,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+}
+
+// EventLink relates a timeline event to another one, identified by its time
+// and type, with a note explaining the relation
+type EventLink struct {
+	Time string `json:"time"`
+	Type string `json:"type"`
+	Note string `json:"note"`
+}
+
+// DashboardLink is a Grafana dashboard filtered to an incident's service and
+// time range
+type DashboardLink struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
 }
 
 type ImpactSummary struct {
@@ -23,10 +55,44 @@ type Anomaly struct {
 	Labels map[string]string `json:"labels"`
 }
 
+// Impact summarizes who is affected by an incident. ErrorRate is a percentage (0-100).
 type Impact struct {
 	SLOAffected bool    `json:"slo_affected"`
 	ErrorRate   float64 `json:"error_rate"`
 	BadPods     int     `json:"bad_pods"`
+	// DegradedPods are running but failing readiness probes
+	DegradedPods int `json:"degraded_pods,omitempty"`
+	// DegradedTraces have a status mapped to degraded; see
+	// analysis.SetStatusFallbacks
+	DegradedTraces int `json:"degraded_traces,omitempty"`
+	// OutlierInstances maps instances with unusually high error rates to
+	// their error rate (percent)
+	OutlierInstances map[string]float64 `json:"outlier_instances,omitempty"`
+
+	// EstimatedTraceFailures estimates the service's failed traces before
+	// sampling; only set when traces are sampled
+	EstimatedTraceFailures float64 `json:"estimated_trace_failures,omitempty"`
+
+	// FailureMix counts failed traces by kind (client_error, server_error,
+	// timeout, cancelled)
+	FailureMix map[string]int `json:"failure_mix,omitempty"`
+
+	// BlastRadius is how widely the incident spread; see ComputeBlastRadius
+	BlastRadius BlastRadius `json:"blast_radius"`
+}
+
+// MarshalJSON rounds the error rates to the configured precision
+func (i Impact) MarshalJSON() ([]byte, error) {
+	type plain Impact
+	p := plain(i)
+	p.ErrorRate = utils.Round(p.ErrorRate)
+	if len(p.OutlierInstances) > 0 {
+		p.OutlierInstances = make(map[string]float64, len(i.OutlierInstances))
+		for instance, rate := range i.OutlierInstances {
+			p.OutlierInstances[instance] = utils.Round(rate)
+		}
+	}
+	return json.Marshal(p)
 }
 
 type TimeRange struct {
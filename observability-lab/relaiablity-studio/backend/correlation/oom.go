@@ -0,0 +1,118 @@
+package correlation
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+// DefaultOOMLinkWindow is how soon after an OOMKill a latency spike is
+// attributed to it, while the pod restarts and warms up
+const DefaultOOMLinkWindow = 5 * time.Minute
+
+var oomLinkWindow = DefaultOOMLinkWindow
+
+// SetOOMLinkWindow sets how soon after an OOMKill a latency spike is linked
+// to it. Zero or less restores DefaultOOMLinkWindow.
+func SetOOMLinkWindow(d time.Duration) {
+	if d <= 0 {
+		d = DefaultOOMLinkWindow
+	}
+	oomLinkWindow = d
+}
+
+// LinkOOMKills links each latency spike to the latest OOMKill preceding it
+// within the window, recording the link on both events. Spikes come from a
+// latency series, so only windowed incidents (?start=&end=) have any to link;
+// live incidents read latency as an instant value.
+func LinkOOMKills(timeline []Event) []Event {
+	for i, spike := range timeline {
+		if spike.Type != EventLatencySpike {
+			continue
+		}
+		spikeAt, ok := parseEventTime(spike.Time)
+		if !ok {
+			continue
+		}
+
+		cause := -1
+		var causeAt time.Time
+		for j, e := range timeline {
+			if e.Type != EventOOMKill {
+				continue
+			}
+			at, ok := parseEventTime(e.Time)
+			if !ok || at.After(spikeAt) || spikeAt.Sub(at) > oomLinkWindow {
+				continue
+			}
+			if cause < 0 || at.After(causeAt) {
+				cause, causeAt = j, at
+			}
+		}
+		if cause < 0 {
+			continue
+		}
+
+		gap := spikeAt.Sub(causeAt).Round(time.Second)
+		timeline[i].Links = append(timeline[i].Links, EventLink{
+			Time: timeline[cause].Time,
+			Type: EventOOMKill,
+			Note: fmt.Sprintf("Likely caused by %s %s earlier, while the pod restarted", timeline[cause].Message, gap),
+		})
+		timeline[cause].Links = append(timeline[cause].Links, EventLink{
+			Time: spike.Time,
+			Type: EventLatencySpike,
+			Note: fmt.Sprintf("Followed by a latency spike %s later", gap),
+		})
+	}
+	return timeline
+}
+
+// latencySpikes reports each time the latency series rises to the warning
+// threshold from below it. Without a series or a threshold there are none,
+// which is always the case for live incidents' instant queries.
+func latencySpikes(metrics analysis.MetricResult) []Event {
+	if latencyWarning <= 0 {
+		return nil
+	}
+	// Range queries sample at a fixed step; a lone point has none
+	step := 0.0
+	if len(metrics.Series) > 1 {
+		step = metrics.Series[1].Time - metrics.Series[0].Time
+	}
+	var spikes []Event
+	above := false
+	for _, p := range metrics.Series {
+		latency := time.Duration(p.Value * float64(time.Second))
+		if latency >= latencyWarning && !above {
+			spike := Event{
+				Time:    time.Unix(0, int64(p.Time*float64(time.Second))).UTC().Format(time.RFC3339),
+				Source:  "metrics",
+				Type:    EventLatencySpike,
+				Message: fmt.Sprintf("Latency spike: p95 %s", latency.Round(time.Millisecond)),
+			}
+			if traceID := nearestExemplar(metrics.Exemplars, p.Time, step); traceID != "" {
+				spike.Labels = map[string]string{"trace_id": traceID}
+			}
+			spikes = append(spikes, spike)
+		}
+		above = latency >= latencyWarning
+	}
+	return spikes
+}
+
+// nearestExemplar returns the trace ID of the exemplar closest to t (Unix
+// seconds), so a spike can deep-link to a request that was slow at the time.
+// Exemplars more than within seconds away belong to another sample and are
+// not linked.
+func nearestExemplar(exemplars []analysis.Exemplar, t, within float64) string {
+	traceID, best := "", math.Inf(1)
+	for _, e := range exemplars {
+		if d := math.Abs(e.Time - t); d <= within && d < best {
+			traceID, best = e.TraceID, d
+		}
+	}
+	return traceID
+}
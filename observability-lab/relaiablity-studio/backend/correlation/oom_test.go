@@ -0,0 +1,122 @@
+package correlation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+func TestOOMKillLinkedToLatencySpike(t *testing.T) {
+	SetLatencyThresholds(time.Second, 0)
+	defer SetLatencyThresholds(0, 0)
+
+	oomAt := time.Date(2024, 1, 15, 2, 10, 0, 0, time.UTC)
+	k8s := `{"items":[{"kind":"Pod","metadata":{"name":"checkout-1"},"status":{"phase":"Running",
+		"containerStatuses":[{"name":"checkout","lastState":{"terminated":{"reason":"OOMKilled","finishedAt":"` + oomAt.Format(time.RFC3339) + `"}}}]}}]}`
+	k8sResult, err := analysis.AnalyzeK8s("checkout", k8s)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// p95 latency climbs past 1s 45s after the OOMKill, and again 20m later
+	series := []analysis.MetricPoint{
+		{Time: float64(oomAt.Add(-15 * time.Second).Unix()), Value: 0.2},
+		{Time: float64(oomAt.Add(45 * time.Second).Unix()), Value: 2.4},
+		{Time: float64(oomAt.Add(60 * time.Second).Unix()), Value: 1.8},
+		{Time: float64(oomAt.Add(10 * time.Minute).Unix()), Value: 0.3},
+		{Time: float64(oomAt.Add(20 * time.Minute).Unix()), Value: 3.1},
+	}
+	exemplars := []analysis.Exemplar{{TraceID: "slow-trace", Time: float64(oomAt.Add(50 * time.Second).Unix()), Value: 2.3}}
+	incident := correlate("checkout", analysis.LogResult{}, analysis.MetricResult{Series: series, Exemplars: exemplars}, analysis.TraceResult{}, k8sResult, nil)
+
+	var oom, linked, unlinked *Event
+	for i, e := range incident.Timeline {
+		switch {
+		case e.Type == EventOOMKill:
+			oom = &incident.Timeline[i]
+		case e.Type == EventLatencySpike && len(e.Links) > 0:
+			linked = &incident.Timeline[i]
+		case e.Type == EventLatencySpike:
+			unlinked = &incident.Timeline[i]
+		}
+	}
+	if oom == nil || linked == nil || unlinked == nil {
+		t.Fatalf("Expected an OOMKill, a linked spike and an unlinked spike, got %+v", incident.Timeline)
+	}
+
+	if linked.Labels["trace_id"] != "slow-trace" {
+		t.Errorf("Expected the spike to link the nearest exemplar's trace, got %v", linked.Labels)
+	}
+
+	link := linked.Links[0]
+	if link.Type != EventOOMKill || link.Time != oom.Time || !strings.Contains(link.Note, "Container checkout OOMKilled 45s earlier") {
+		t.Errorf("Expected the spike linked to the OOMKill, got %+v", link)
+	}
+	if len(oom.Links) != 1 || oom.Links[0].Time != linked.Time || oom.Links[0].Type != EventLatencySpike {
+		t.Errorf("Expected the OOMKill linked to the spike, got %+v", oom.Links)
+	}
+}
+
+func TestOOMKillNotLinkedOutsideAnchorWindow(t *testing.T) {
+	SetLatencyThresholds(time.Second, 0)
+	defer SetLatencyThresholds(0, 0)
+	SetAnchorWindow(time.Minute, 30*time.Second)
+	defer SetAnchorWindow(0, 0)
+
+	oomAt := time.Date(2024, 1, 15, 2, 10, 0, 0, time.UTC)
+	k8s := analysis.K8sResult{Events: []analysis.K8sEvent{
+		{Time: oomAt.Format(time.RFC3339), Message: "Container checkout OOMKilled", Kind: analysis.K8sOOMKillEvent, Pod: "checkout-1"},
+	}}
+	// The spike 45s after the OOMKill falls outside the 30s after the anchor
+	series := []analysis.MetricPoint{
+		{Time: float64(oomAt.Add(-15 * time.Second).Unix()), Value: 0.2},
+		{Time: float64(oomAt.Add(45 * time.Second).Unix()), Value: 2.4},
+	}
+	incident := correlate("checkout", analysis.LogResult{}, analysis.MetricResult{Series: series}, analysis.TraceResult{}, k8s, nil)
+
+	for _, e := range incident.Timeline {
+		if e.Type == EventLatencySpike {
+			t.Errorf("Expected the spike windowed out, got %+v", e)
+		}
+		if e.Type == EventOOMKill && len(e.Links) > 0 {
+			t.Errorf("Expected no link to the windowed-out spike, got %+v", e.Links)
+		}
+	}
+}
+
+func TestLatencySpikeIgnoresDistantExemplars(t *testing.T) {
+	SetLatencyThresholds(time.Second, 0)
+	defer SetLatencyThresholds(0, 0)
+
+	start := time.Date(2024, 1, 15, 2, 10, 0, 0, time.UTC)
+	at := func(d time.Duration) float64 { return float64(start.Add(d).Unix()) }
+	series := []analysis.MetricPoint{
+		{Time: at(0), Value: 0.2},
+		{Time: at(time.Minute), Value: 0.3},
+		{Time: at(2 * time.Minute), Value: 2.4},
+	}
+
+	testCases := []struct {
+		name     string
+		offset   time.Duration
+		expected string
+	}{
+		{"within a step", 50 * time.Second, "near-trace"},
+		{"one step away", time.Minute, "near-trace"},
+		{"several steps away", -5 * time.Minute, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exemplars := []analysis.Exemplar{{TraceID: "near-trace", Time: at(2*time.Minute + tc.offset), Value: 2.3}}
+			spikes := latencySpikes(analysis.MetricResult{Series: series, Exemplars: exemplars})
+			if len(spikes) != 1 {
+				t.Fatalf("Expected one spike, got %+v", spikes)
+			}
+			if got := spikes[0].Labels["trace_id"]; got != tc.expected {
+				t.Errorf("Expected trace %q linked, got %q", tc.expected, got)
+			}
+		})
+	}
+}
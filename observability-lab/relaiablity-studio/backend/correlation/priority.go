@@ -0,0 +1,45 @@
+package correlation
+
+import "fmt"
+
+// DefaultServiceTier is the business tier of services without one configured.
+// At this tier priority follows severity alone; each tier below it (tier 0
+// being the most critical to the business) raises priority one level, and
+// each tier above lowers it.
+const DefaultServiceTier = 1
+
+// Priorities run from P1, the most urgent, to LowestPriority
+const LowestPriority = 4
+
+var serviceTiers map[string]int
+
+// SetServiceTiers sets each service's business tier, e.g. 0 for checkout and
+// 2 for internal tools. Unlisted services are DefaultServiceTier; nil clears
+// the tiers.
+func SetServiceTiers(tiers map[string]int) {
+	serviceTiers = tiers
+}
+
+// Priority grades how much an incident matters to the business, separately
+// from how broken the service is: critical is P1, warning P2 and other
+// non-healthy severities P3, moved by the service's tier and kept within P1
+// to P4. A tier-0 service is raised a level whatever its severity. Healthy
+// incidents have no priority.
+func Priority(service, severity string) string {
+	level := 3
+	switch severity {
+	case "healthy":
+		return ""
+	case "critical":
+		level = 1
+	case "warning":
+		level = 2
+	}
+
+	tier, ok := serviceTiers[service]
+	if !ok {
+		tier = DefaultServiceTier
+	}
+	level = min(max(level+tier-DefaultServiceTier, 1), LowestPriority)
+	return fmt.Sprintf("P%d", level)
+}
@@ -0,0 +1,57 @@
+package correlation
+
+import (
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+func TestPriority(t *testing.T) {
+	SetServiceTiers(map[string]int{"checkout": 0, "search": 2, "reports": 3})
+	defer SetServiceTiers(nil)
+
+	testCases := []struct {
+		service  string
+		severity string
+		expected string
+	}{
+		{"inventory", "critical", "P1"},
+		{"inventory", "warning", "P2"},
+		{"inventory", SeverityRecovering, "P3"},
+		{"inventory", "healthy", ""},
+		{"checkout", "critical", "P1"},
+		{"checkout", "warning", "P1"},
+		{"checkout", "unknown", "P2"},
+		{"search", "critical", "P2"},
+		{"search", "warning", "P3"},
+		{"reports", "warning", "P4"},
+		{"reports", "no_data", "P4"},
+	}
+	for _, tc := range testCases {
+		if got := Priority(tc.service, tc.severity); got != tc.expected {
+			t.Errorf("Expected %s %s to be %q, got %q", tc.severity, tc.service, tc.expected, got)
+		}
+	}
+}
+
+func TestIncidentPriorityFromTier(t *testing.T) {
+	SetServiceTiers(map[string]int{"checkout": 0})
+	defer SetServiceTiers(nil)
+
+	logs := analysis.LogResult{ErrorCount: 5, RootCause: "error: db down"}
+	tier0 := correlate("checkout", logs, analysis.MetricResult{ErrorRate: 8}, analysis.TraceResult{}, analysis.K8sResult{}, nil)
+	if tier0.Severity != "warning" || tier0.Priority != "P1" {
+		t.Errorf("Expected a warning tier-0 incident elevated to P1, got %s %s", tier0.Severity, tier0.Priority)
+	}
+	other := correlate("inventory", logs, analysis.MetricResult{ErrorRate: 8}, analysis.TraceResult{}, analysis.K8sResult{}, nil)
+	if other.Severity != "warning" || other.Priority != "P2" {
+		t.Errorf("Expected a warning incident for an untiered service at P2, got %s %s", other.Severity, other.Priority)
+	}
+
+	tracker := NewRecoveryTracker(3)
+	tracker.Apply(tier0)
+	healthy := correlate("checkout", analysis.LogResult{Events: []analysis.LogEvent{{Message: "ok"}}}, analysis.MetricResult{ErrorRate: 0.1}, analysis.TraceResult{}, analysis.K8sResult{}, nil)
+	if recovering := tracker.Apply(healthy); recovering.Severity != SeverityRecovering || recovering.Priority != "P2" {
+		t.Errorf("Expected a recovering tier-0 incident at P2, got %s %q", recovering.Severity, recovering.Priority)
+	}
+}
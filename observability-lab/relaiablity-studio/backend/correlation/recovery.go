@@ -0,0 +1,62 @@
+package correlation
+
+import "sync"
+
+// SeverityRecovering is reported for a service that looks healthy again after
+// a warning or critical incident, until it has stayed healthy long enough
+const SeverityRecovering = "recovering"
+
+// RecoveryTracker holds back a service's return to healthy until it has been
+// healthy for several consecutive evaluations, so a single good reading
+// during a flapping incident doesn't declare recovery. Safe for concurrent use.
+type RecoveryTracker struct {
+	evaluations int
+
+	mu         sync.Mutex
+	recovering map[string]int // Healthy evaluations so far, by service
+}
+
+// NewRecoveryTracker requires evaluations consecutive healthy evaluations to
+// declare recovery. evaluations <= 1 reports healthy immediately.
+func NewRecoveryTracker(evaluations int) *RecoveryTracker {
+	return &RecoveryTracker{evaluations: evaluations, recovering: make(map[string]int)}
+}
+
+// Observe records a service's evaluated severity and returns the severity to
+// report. Severities other than healthy, warning and critical, such as
+// unknown, are reported as is and leave a recovery in progress untouched.
+func (t *RecoveryTracker) Observe(service, severity string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch severity {
+	case "warning", "critical":
+		t.recovering[service] = 0
+		return severity
+	case "healthy":
+		healthy, ok := t.recovering[service]
+		if !ok {
+			return severity
+		}
+		healthy++
+		if healthy >= t.evaluations {
+			delete(t.recovering, service)
+			return severity
+		}
+		t.recovering[service] = healthy
+		return SeverityRecovering
+	}
+	return severity
+}
+
+// Apply updates an incident's severity, and its summary to match, as Observe
+// reports it
+func (t *RecoveryTracker) Apply(incident Incident) Incident {
+	severity := t.Observe(incident.Service, incident.Severity)
+	if severity != incident.Severity {
+		incident.Severity = severity
+		incident.Priority = Priority(incident.Service, severity)
+		incident.Summary = Summarize(incident.Service, severity, incident.Impact, incident.RootCause)
+	}
+	return incident
+}
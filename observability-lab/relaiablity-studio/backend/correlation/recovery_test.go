@@ -0,0 +1,51 @@
+package correlation
+
+import "testing"
+
+func TestRecoveryTracker(t *testing.T) {
+	tracker := NewRecoveryTracker(3)
+
+	steps := []struct {
+		evaluated, expected string
+	}{
+		{"healthy", "healthy"}, // Nothing to recover from
+		{"critical", "critical"},
+		{"healthy", SeverityRecovering},
+		{"warning", "warning"}, // Flapping restarts the count
+		{"healthy", SeverityRecovering},
+		{"unknown", "unknown"},
+		{"healthy", SeverityRecovering},
+		{"healthy", "healthy"},
+		{"healthy", "healthy"},
+	}
+	for i, step := range steps {
+		if got := tracker.Observe("checkout", step.evaluated); got != step.expected {
+			t.Errorf("Step %d: expected %s for %s, got %s", i, step.expected, step.evaluated, got)
+		}
+	}
+
+	if got := tracker.Observe("payments", "healthy"); got != "healthy" {
+		t.Errorf("Expected other services unaffected, got %s", got)
+	}
+}
+
+func TestRecoveryTrackerDisabled(t *testing.T) {
+	tracker := NewRecoveryTracker(0)
+	tracker.Observe("checkout", "critical")
+	if got := tracker.Observe("checkout", "healthy"); got != "healthy" {
+		t.Errorf("Expected immediate recovery, got %s", got)
+	}
+}
+
+func TestRecoveryTrackerApplySummarizes(t *testing.T) {
+	tracker := NewRecoveryTracker(2)
+	tracker.Observe("checkout", "critical")
+
+	incident := tracker.Apply(Incident{Service: "checkout", Severity: "healthy"})
+	if incident.Severity != SeverityRecovering {
+		t.Fatalf("Expected recovering, got %s", incident.Severity)
+	}
+	if want := "Checkout recovering: 0.0% error rate, 0 failed pods"; incident.Summary != want {
+		t.Errorf("Expected summary %q, got %q", want, incident.Summary)
+	}
+}
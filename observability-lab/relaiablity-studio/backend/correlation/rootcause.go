@@ -0,0 +1,95 @@
+package correlation
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultAgreementWindow is how close in time a trace failure or pod failure
+// must be to an error log to corroborate it
+const DefaultAgreementWindow = time.Minute
+
+var agreementWindow = DefaultAgreementWindow
+
+// SetAgreementWindow sets how close other sources must be to an error log to
+// agree with it. Zero or less restores DefaultAgreementWindow.
+func SetAgreementWindow(d time.Duration) {
+	if d <= 0 {
+		d = DefaultAgreementWindow
+	}
+	agreementWindow = d
+}
+
+// AgreedRootCause picks the error log that other sources agree with most: a
+// failing trace within the window counts, the trace the log line names counts
+// double whenever it started, and a pod failure, OOMKill or pod going not
+// ready within the window counts. The earliest log wins ties. Without any
+// agreement it falls back to the first error log.
+func AgreedRootCause(s Signals) string {
+	best, bestScore := "", 0
+	var bestSources []string
+	for _, e := range s.Logs.Events {
+		if ClassifyEvent("logs", "", e.Message) != EventErrorLog {
+			continue
+		}
+		at, ok := parseEventTime(e.Time)
+		if !ok {
+			continue
+		}
+
+		score := 0
+		var sources []string
+		if n := traceAgreement(s, at, e.Labels["trace_id"]); n > 0 {
+			score += n
+			sources = append(sources, "traces")
+		}
+		if podFailureNear(s, at) {
+			score++
+			sources = append(sources, "kubernetes")
+		}
+		if score > bestScore {
+			best, bestScore, bestSources = e.Message, score, sources
+		}
+	}
+
+	if bestScore == 0 {
+		return s.Logs.RootCause
+	}
+	return best + " (confirmed by " + strings.Join(bestSources, ", ") + ")"
+}
+
+// traceAgreement scores the trace failures for a log at: 2 if the log's own
+// trace failed, 1 for any other failure within the window, 0 for none
+func traceAgreement(s Signals, at time.Time, traceID string) int {
+	score := 0
+	for _, t := range s.Traces.Events {
+		if traceID != "" && t.TraceID == traceID {
+			return 2
+		}
+		if tt, ok := parseEventTime(t.Time); ok && within(at, tt) {
+			score = 1
+		}
+	}
+	return score
+}
+
+// podFailureNear reports whether a pod failed, was OOMKilled or went not
+// ready within the window of at
+func podFailureNear(s Signals, at time.Time) bool {
+	for _, e := range s.K8s.Events {
+		switch ClassifyEvent("kubernetes", e.Kind, e.Message) {
+		case EventPodFailure, EventOOMKill, EventPodNotReady:
+		default:
+			continue
+		}
+		if t, ok := parseEventTime(e.Time); ok && within(at, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func within(a, b time.Time) bool {
+	d := a.Sub(b)
+	return d <= agreementWindow && d >= -agreementWindow
+}
@@ -0,0 +1,51 @@
+package correlation
+
+import (
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+func TestAgreedRootCause(t *testing.T) {
+	logs := analysis.LogResult{
+		RootCause: "error: cache miss storm",
+		Events: []analysis.LogEvent{
+			{Time: "2024-01-15T02:00:00Z", Message: "error: cache miss storm"},
+			{Time: "2024-01-15T02:10:00Z", Message: "error: db connection refused", Labels: map[string]string{"trace_id": "abc"}},
+			{Time: "2024-01-15T02:20:00Z", Message: "error: upstream reset"},
+		},
+	}
+	crash := analysis.K8sResult{Events: []analysis.K8sEvent{
+		{Time: "2024-01-15T02:20:30Z", Message: "Pod checkout-1 CrashLoopBackOff", Kind: analysis.K8sPodEvent},
+	}}
+	// 2024-01-15T02:20:20Z and 02:40:00Z, in Tempo's nanosecond epoch
+	nearby := analysis.TraceResult{Events: []analysis.TraceEvent{{Time: "1705285220000000000", TraceID: "zzz"}}}
+	named := analysis.TraceResult{Events: []analysis.TraceEvent{{Time: "1705286400000000000", TraceID: "abc"}}}
+
+	tests := []struct {
+		name     string
+		signals  Signals
+		expected string
+	}{
+		{"no agreement keeps the first error", Signals{Logs: logs}, "error: cache miss storm"},
+		{"trace and pod failure nearby", Signals{Logs: logs, Traces: nearby, K8s: crash}, "error: upstream reset (confirmed by traces, kubernetes)"},
+		{"OOMKill nearby", Signals{Logs: logs, K8s: analysis.K8sResult{Events: []analysis.K8sEvent{
+			{Time: "2024-01-15T02:19:45Z", Message: "Container app OOMKilled", Kind: analysis.K8sOOMKillEvent},
+		}}}, "error: upstream reset (confirmed by kubernetes)"},
+		{"pod not ready nearby", Signals{Logs: logs, K8s: analysis.K8sResult{Events: []analysis.K8sEvent{
+			{Time: "2024-01-15T02:00:10Z", Message: "Pod running but not ready", Kind: analysis.K8sNotReadyEvent},
+		}}}, "error: cache miss storm (confirmed by kubernetes)"},
+		{"the log's own trace failed", Signals{Logs: logs, Traces: named}, "error: db connection refused (confirmed by traces)"},
+		{"outside the window", Signals{Logs: logs, Traces: named, K8s: analysis.K8sResult{Events: []analysis.K8sEvent{
+			{Time: "2024-01-15T03:00:00Z", Message: "Pod checkout-1 OOMKilled", Kind: analysis.K8sPodEvent},
+		}}}, "error: db connection refused (confirmed by traces)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AgreedRootCause(tt.signals); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package correlation
+
+import "github.com/sarikasharma2428-web/reliability-studio/analysis"
+
+// Signals are the analyzed sources of a service, as handed to a strategy.
+// SourceErrors lists the sources that failed and are left zero-valued.
+type Signals struct {
+	Service      string
+	Logs         analysis.LogResult
+	Metrics      analysis.MetricResult
+	Traces       analysis.TraceResult
+	K8s          analysis.K8sResult
+	SourceErrors map[string]string
+}
+
+// CorrelationStrategy holds the heuristics that turn signals into an
+// incident. Impact, confidence and the summary are derived from its results.
+type CorrelationStrategy interface {
+	Severity(s Signals) string
+	RootCause(s Signals) string
+	Timeline(s Signals) []Event
+}
+
+// Strategies are the built-in strategies, selectable by name in config
+var Strategies = map[string]CorrelationStrategy{
+	"default": DefaultStrategy{},
+}
+
+var strategy CorrelationStrategy = DefaultStrategy{}
+
+// SetStrategy replaces the correlation strategy. nil restores DefaultStrategy.
+func SetStrategy(s CorrelationStrategy) {
+	if s == nil {
+		s = DefaultStrategy{}
+	}
+	strategy = s
+}
+
+// DefaultStrategy grades severity from bad pods, error rate and error logs,
+// takes the root cause from the error log other sources agree with most, and
+// lists log, trace and k8s events and latency spikes
+type DefaultStrategy struct{}
+
+// Severity implements CorrelationStrategy
+func (DefaultStrategy) Severity(s Signals) string {
+	return calculateSeverity(s.Logs, s.Metrics, s.Traces.FailureRate(s.Service), s.Traces.DegradedFor(s.Service), s.K8s, len(s.SourceErrors) > 0)
+}
+
+// RootCause implements CorrelationStrategy
+func (DefaultStrategy) RootCause(s Signals) string {
+	return AgreedRootCause(s)
+}
+
+// Timeline implements CorrelationStrategy
+func (DefaultStrategy) Timeline(s Signals) []Event {
+	var timeline []Event
+	for _, e := range s.Logs.Events {
+		timeline = append(timeline, Event{
+			Time: e.Time, Source: "logs", Type: ClassifyEvent("logs", "", e.Message), Message: e.Message, Labels: e.Labels,
+			Metadata: e.Metadata, Count: e.Count,
+		})
+	}
+	for _, e := range s.Traces.Events {
+		timeline = append(timeline, Event{Time: e.Time, Source: "traces", Type: EventTraceFailure, Message: e.Message})
+	}
+	for _, e := range s.K8s.Events {
+		timeline = append(timeline, Event{
+			Time: e.Time, Source: "kubernetes", Type: ClassifyEvent("kubernetes", e.Kind, e.Message), Message: e.Message, Cluster: e.Cluster,
+			Namespace: e.Namespace, Instance: e.Pod,
+		})
+	}
+	timeline = append(timeline, latencySpikes(s.Metrics)...)
+	return timeline
+}
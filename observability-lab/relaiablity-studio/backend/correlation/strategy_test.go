@@ -0,0 +1,62 @@
+package correlation
+
+import (
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+// stubStrategy records the signals it was given and returns fixed results
+type stubStrategy struct {
+	calls []string
+	seen  Signals
+}
+
+func (s *stubStrategy) Severity(sig Signals) string {
+	s.calls = append(s.calls, "severity")
+	s.seen = sig
+	return "critical"
+}
+
+func (s *stubStrategy) RootCause(Signals) string {
+	s.calls = append(s.calls, "root_cause")
+	return "bad deploy"
+}
+
+func (s *stubStrategy) Timeline(Signals) []Event {
+	s.calls = append(s.calls, "timeline")
+	return []Event{{Time: "2024-01-15T02:14:00Z", Source: "deploys", Message: "checkout v42 rolled out"}}
+}
+
+func TestBuildIncidentUsesStrategy(t *testing.T) {
+	stub := &stubStrategy{}
+	SetStrategy(stub)
+	defer SetStrategy(nil)
+
+	src := fakeSources{
+		logs:    analysis.LogResult{RootCause: "DB connection timeout", ErrorCount: 1},
+		metrics: analysis.MetricResult{ErrorRate: 0.5},
+	}
+	incident := BuildIncident("checkout", src)
+
+	if len(stub.calls) != 3 {
+		t.Fatalf("Expected severity, root cause and timeline calls, got %v", stub.calls)
+	}
+	if stub.seen.Service != "checkout" || stub.seen.Metrics.ErrorRate != 0.5 || stub.seen.Logs.ErrorCount != 1 {
+		t.Errorf("Expected the collected signals passed to the strategy, got %+v", stub.seen)
+	}
+	if incident.Severity != "critical" || incident.RootCause != "bad deploy" {
+		t.Errorf("Expected strategy severity and root cause, got %s / %q", incident.Severity, incident.RootCause)
+	}
+	if len(incident.Timeline) != 1 || incident.Timeline[0].Source != "deploys" {
+		t.Errorf("Expected strategy timeline, got %+v", incident.Timeline)
+	}
+	if want := "Checkout critical: 0.5% error rate, 0 failed pods, root cause: bad deploy"; incident.Summary != want {
+		t.Errorf("Expected summary %q, got %q", want, incident.Summary)
+	}
+
+	SetStrategy(nil)
+	if got := BuildIncident("checkout", src).Severity; got != "warning" {
+		t.Errorf("Expected the default strategy restored, got severity %s", got)
+	}
+}
@@ -0,0 +1,44 @@
+package correlation
+
+import (
+	"strconv"
+	"time"
+)
+
+// InLocation returns a copy of the timeline with every parseable event time
+// rendered as RFC3339 in loc. Times that can't be parsed are left untouched.
+func InLocation(timeline []Event, loc *time.Location) []Event {
+	converted := make([]Event, len(timeline))
+	for i, e := range timeline {
+		if t, ok := parseEventTime(e.Time); ok {
+			e.Time = t.In(loc).Format(time.RFC3339)
+		}
+		if len(e.Links) > 0 {
+			e.Links = append([]EventLink(nil), e.Links...)
+			for j, l := range e.Links {
+				if t, ok := parseEventTime(l.Time); ok {
+					e.Links[j].Time = t.In(loc).Format(time.RFC3339)
+				}
+			}
+		}
+		converted[i] = e
+	}
+	return converted
+}
+
+// parseEventTime accepts RFC3339 strings (k8s) and unix epoch strings
+// (Loki and Tempo report nanoseconds)
+func parseEventTime(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, true
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if len(s) >= 16 {
+		return time.Unix(0, n), true
+	}
+	return time.Unix(n, 0), true
+}
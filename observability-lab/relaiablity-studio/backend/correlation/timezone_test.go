@@ -0,0 +1,35 @@
+package correlation
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+
+	timeline := []Event{
+		{Time: "2024-01-15T07:14:00Z", Source: "kubernetes", Message: "Pod failed"},
+		{Time: "1705302840000000000", Source: "logs", Message: "error: timeout"},
+		{Time: "not-a-time", Source: "traces", Message: "Trace failure"},
+	}
+
+	converted := InLocation(timeline, loc)
+
+	if want := "2024-01-15T02:14:00-05:00"; converted[0].Time != want {
+		t.Errorf("Expected RFC3339 event converted to %q, got %q", want, converted[0].Time)
+	}
+	if want := "2024-01-15T02:14:00-05:00"; converted[1].Time != want {
+		t.Errorf("Expected nanosecond event converted to %q, got %q", want, converted[1].Time)
+	}
+	if converted[2].Time != "not-a-time" {
+		t.Errorf("Expected unparseable time to be left untouched, got %q", converted[2].Time)
+	}
+	if timeline[0].Time != "2024-01-15T07:14:00Z" {
+		t.Error("InLocation must not modify the input timeline")
+	}
+}
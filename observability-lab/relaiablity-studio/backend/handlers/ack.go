@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sarikasharma2428-web/reliability-studio/store"
+)
+
+// AckIncident acknowledges a service's incident, suppressing pages for it
+// until the given time
+func AckIncident(w http.ResponseWriter, r *http.Request) {
+	service := mux.Vars(r)["service"]
+
+	var req struct {
+		Author string    `json:"author"`
+		Reason string    `json:"reason"`
+		Until  time.Time `json:"until"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	switch {
+	case req.Author == "":
+		http.Error(w, "author is required", http.StatusBadRequest)
+		return
+	case req.Reason == "":
+		http.Error(w, "reason is required", http.StatusBadRequest)
+		return
+	case !req.Until.After(now):
+		http.Error(w, "until must be in the future", http.StatusBadRequest)
+		return
+	}
+
+	ack := store.Ack{Author: req.Author, Reason: req.Reason, Until: req.Until, AckedAt: now}
+	history.Acknowledge(service, ack)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ack)
+}
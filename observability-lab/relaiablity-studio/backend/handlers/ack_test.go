@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+)
+
+func TestAckIncident(t *testing.T) {
+	Configure(config.Config{})
+	router := mux.NewRouter()
+	router.HandleFunc("/api/incident/{service}/ack", AckIncident).Methods("POST")
+
+	until := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	testCases := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"valid", fmt.Sprintf(`{"author":"oncall","reason":"failover","until":%q}`, until), http.StatusCreated},
+		{"missing author", fmt.Sprintf(`{"reason":"failover","until":%q}`, until), http.StatusBadRequest},
+		{"missing reason", fmt.Sprintf(`{"author":"oncall","until":%q}`, until), http.StatusBadRequest},
+		{"expired", `{"author":"oncall","reason":"failover","until":"2024-01-15T02:00:00Z"}`, http.StatusBadRequest},
+		{"malformed", `{"author":`, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest("POST", "/api/incident/checkout/ack", strings.NewReader(tc.body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d (%s)", tc.name, tc.status, rec.Code, rec.Body)
+		}
+	}
+
+	ack, ok := history.Acknowledgement("checkout", time.Now())
+	if !ok || ack.Author != "oncall" || ack.Reason != "failover" {
+		t.Errorf("Expected the acknowledgement stored, got %+v", ack)
+	}
+	if _, ok := history.Acknowledgement("checkout", time.Now().Add(2*time.Hour)); ok {
+		t.Error("Expected the acknowledgement to expire")
+	}
+}
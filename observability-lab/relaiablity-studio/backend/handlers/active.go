@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+// GetActiveIncidents is the on-call feed (GET /api/incidents/active): the
+// latest incident of every service that isn't healthy, critical first and
+// then most recent first, with how many there are of each severity
+func GetActiveIncidents(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	records := history.Active()
+
+	incidents := make([]correlation.Incident, 0, len(records))
+	bySeverity := make(map[string]int)
+	for _, record := range records {
+		incidents = append(incidents, withDashboards(record.Incident, correlation.TimeRange{}).WithAge(now))
+		bySeverity[record.Incident.Severity]++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"count":       len(incidents),
+		"by_severity": bySeverity,
+		"incidents":   incidents,
+	})
+}
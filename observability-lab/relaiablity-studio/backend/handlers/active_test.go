@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+func TestGetActiveIncidents(t *testing.T) {
+	Configure(config.Config{GrafanaURL: "https://grafana.example.com", GrafanaDashboards: map[string]string{"overview": "svc-overview"}})
+	defer Configure(config.Config{})
+	for _, incident := range []correlation.Incident{
+		{ID: "checkout-1", Service: "checkout", Severity: "critical"},
+		{ID: "payments-1", Service: "payments", Severity: "warning"},
+		{ID: "search-1", Service: "search", Severity: "critical"},
+		{ID: "cart-1", Service: "cart", Severity: "warning"},
+		{ID: "cart-2", Service: "cart", Severity: "healthy"},
+		{ID: "users-1", Service: "users", Severity: "healthy"},
+		{ID: "orders-1", Service: "orders", Severity: correlation.SeverityRecovering},
+	} {
+		history.Add(incident)
+	}
+
+	rec := httptest.NewRecorder()
+	GetActiveIncidents(rec, httptest.NewRequest("GET", "/api/incidents/active", nil))
+
+	var body struct {
+		Count      int                    `json:"count"`
+		BySeverity map[string]int         `json:"by_severity"`
+		Incidents  []correlation.Incident `json:"incidents"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Critical first, newest first within a severity; recovered services are left out
+	expected := []string{"search-1", "checkout-1", "payments-1", "orders-1"}
+	if len(body.Incidents) != len(expected) {
+		t.Fatalf("Expected incidents %v, got %+v", expected, body.Incidents)
+	}
+	for i, id := range expected {
+		if body.Incidents[i].ID != id {
+			t.Errorf("Expected incident %d to be %s, got %s", i, id, body.Incidents[i].ID)
+		}
+		if len(body.Incidents[i].Dashboards) != 1 {
+			t.Errorf("Expected %s linked to the overview dashboard, got %+v", id, body.Incidents[i].Dashboards)
+		}
+	}
+	if body.Count != 4 || body.BySeverity["critical"] != 2 || body.BySeverity["warning"] != 1 || body.BySeverity[correlation.SeverityRecovering] != 1 {
+		t.Errorf("Expected 4 incidents, 2 critical, 1 warning and 1 recovering, got %d, %v", body.Count, body.BySeverity)
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultAlertDedupWindow is used when no dedup window is configured
+const DefaultAlertDedupWindow = 15 * time.Minute
+
+// firing remembers recently handled alerts so Alertmanager's repeat
+// notifications don't rebuild and re-page the same incident
+var firing = newAlertDedup(DefaultAlertDedupWindow)
+
+// alertmanagerPayload is the subset of Alertmanager's webhook body we use
+type alertmanagerPayload struct {
+	Alerts []struct {
+		Status      string            `json:"status"` // "firing" or "resolved"
+		Labels      map[string]string `json:"labels"`
+		Fingerprint string            `json:"fingerprint"`
+	} `json:"alerts"`
+}
+
+// ReceiveAlerts is an Alertmanager webhook receiver. Each firing alert
+// builds an incident for the service in its service (or job) label, unless
+// the same alert was handled within the dedup window.
+func ReceiveAlerts(w http.ResponseWriter, r *http.Request) {
+	var payload alertmanagerPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	built := []string{}
+	deduplicated := 0
+	for _, alert := range payload.Alerts {
+		service := alert.Labels["service"]
+		if service == "" {
+			service = alert.Labels["job"]
+		}
+		key := alert.Fingerprint
+		if key == "" {
+			key = service + "/" + alert.Labels["alertname"]
+		}
+
+		if alert.Status == "resolved" {
+			firing.forget(key)
+			continue
+		}
+		if service == "" {
+			continue
+		}
+		if !firing.first(key, time.Now()) {
+			deduplicated++
+			continue
+		}
+
+		incident := buildIncident(service, upstreamSources{templates: cfg.QueryTemplates})
+		built = append(built, incident.ID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"incidents":    built,
+		"deduplicated": deduplicated,
+	})
+}
+
+// alertDedup tracks when each alert fingerprint was last handled
+type alertDedup struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// newAlertDedup creates a dedup set. window <= 0 uses DefaultAlertDedupWindow.
+func newAlertDedup(window time.Duration) *alertDedup {
+	if window <= 0 {
+		window = DefaultAlertDedupWindow
+	}
+	return &alertDedup{window: window, seen: make(map[string]time.Time)}
+}
+
+// first reports whether key hasn't been handled within the window, and if
+// so records it as handled at now
+func (d *alertDedup) first(key string, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for k, at := range d.seen {
+		if now.Sub(at) >= d.window {
+			delete(d.seen, k)
+		}
+	}
+	if _, ok := d.seen[key]; ok {
+		return false
+	}
+	d.seen[key] = now
+	return true
+}
+
+// forget drops key, so the alert builds an incident the next time it fires
+func (d *alertDedup) forget(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.seen, key)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+func TestReceiveAlertsDeduplicates(t *testing.T) {
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer prom.Close()
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[]}}`))
+	}))
+	defer loki.Close()
+	tempo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"traces":[]}`))
+	}))
+	defer tempo.Close()
+	services.SetUpstreams(prom.URL, loki.URL, tempo.URL)
+	defer services.SetUpstreams("", "", "")
+
+	Configure(config.Config{AlertDedupWindow: time.Minute})
+	defer Configure(config.Config{})
+
+	send := func(status string) (built []string, deduplicated int) {
+		body := `{"alerts":[{"status":"` + status + `","labels":{"alertname":"HighErrorRate","service":"checkout"},"fingerprint":"c0ffee"}]}`
+		rec := httptest.NewRecorder()
+		ReceiveAlerts(rec, httptest.NewRequest("POST", "/api/alerts", strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body)
+		}
+		var resp struct {
+			Incidents    []string `json:"incidents"`
+			Deduplicated int      `json:"deduplicated"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.Incidents, resp.Deduplicated
+	}
+
+	if built, _ := send("firing"); len(built) != 1 {
+		t.Fatalf("Expected the first alert to build an incident, got %v", built)
+	}
+	if built, deduplicated := send("firing"); len(built) != 0 || deduplicated != 1 {
+		t.Errorf("Expected the repeat to be deduplicated, got built=%v deduplicated=%d", built, deduplicated)
+	}
+	if n := len(History().List("checkout")); n != 1 {
+		t.Errorf("Expected only one incident build, got %d", n)
+	}
+
+	// Once resolved, the next firing is a new occurrence
+	send("resolved")
+	if built, _ := send("firing"); len(built) != 1 {
+		t.Errorf("Expected a re-fired alert to build again, got %v", built)
+	}
+}
+
+func TestAlertDedupWindowExpires(t *testing.T) {
+	d := newAlertDedup(time.Minute)
+	now := time.Now()
+
+	if !d.first("c0ffee", now) {
+		t.Fatal("Expected the first alert to be handled")
+	}
+	if d.first("c0ffee", now.Add(30*time.Second)) {
+		t.Error("Expected a repeat within the window to be deduplicated")
+	}
+	if !d.first("c0ffee", now.Add(time.Minute)) {
+		t.Error("Expected the alert to be handled again after the window")
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+// DefaultArcWindow is the length of each snapshot without ?window=
+const DefaultArcWindow = 5 * time.Minute
+
+// MaxArcSnapshots bounds the upstream queries a single arc can trigger
+const MaxArcSnapshots = 96
+
+// GetIncidentArc re-analyzes a past range window by window
+// (GET /api/incident/{service}/arc?start=&end=&window=), returning a snapshot
+// of the incident per window to show how it evolved. Windows are ?window=
+// long (default 5m) and sample metrics every ?step=. Snapshots are not
+// stored, streamed or paged for.
+func GetIncidentArc(w http.ResponseWriter, r *http.Request) {
+	service := mux.Vars(r)["service"]
+
+	loc, err := timelineLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	span, err := incidentWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if span.Start.IsZero() {
+		http.Error(w, "start and end are required", http.StatusBadRequest)
+		return
+	}
+
+	window := DefaultArcWindow
+	if raw := r.URL.Query().Get("window"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			http.Error(w, fmt.Sprintf("invalid window %q", raw), http.StatusBadRequest)
+			return
+		}
+		window = d
+	}
+	window = min(window, span.End.Sub(span.Start))
+	if n := (span.End.Sub(span.Start) + window - 1) / window; n > MaxArcSnapshots {
+		http.Error(w, fmt.Sprintf("range covers %d windows, more than the maximum of %d", n, MaxArcSnapshots), http.StatusBadRequest)
+		return
+	}
+
+	step, err := metricStep(r, correlation.TimeRange{Start: span.Start, End: span.Start.Add(window)})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snapshots := correlation.BuildIncidentOverRange(service, span.Start, span.End, window, func(tr correlation.TimeRange) correlation.Sources {
+		return upstreamSources{templates: cfg.QueryTemplates, window: tr, step: step}
+	})
+	for i := range snapshots {
+		snapshots[i].Incident.Timeline = correlation.InLocation(snapshots[i].Incident.Timeline, loc)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"service":   service,
+		"window":    window.String(),
+		"snapshots": snapshots,
+	})
+}
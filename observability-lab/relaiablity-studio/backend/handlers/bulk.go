@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+// Worker-pool sizes used when none are configured
+const (
+	DefaultBulkConcurrency    = 4
+	DefaultMaxBulkConcurrency = 16
+)
+
+// BulkIncidents builds incidents for several services at once
+// (POST /api/incidents/bulk with {"services": [...]}). ?max_concurrency= sets
+// how many are built in parallel, up to the configured ceiling. Root causes
+// shared by enough of the services are listed once, under common_root_causes.
+func BulkIncidents(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Services []string `json:"services"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Services) == 0 {
+		http.Error(w, "services is required", http.StatusBadRequest)
+		return
+	}
+
+	workers, err := bulkConcurrency(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	src := upstreamSources{templates: cfg.QueryTemplates}
+	built := buildAll(req.Services, workers, func(service string) correlation.Incident {
+		return buildIncident(service, src)
+	})
+	built, common := correlation.CollapseRootCauses(built, cfg.CommonRootCauseMinServices)
+	for i := range built {
+		built[i] = withDashboards(built[i], correlation.TimeRange{})
+	}
+
+	resp := map[string]any{
+		"incidents":       built,
+		"max_concurrency": workers,
+	}
+	if len(common) > 0 {
+		resp["common_root_causes"] = common
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// bulkConcurrency resolves ?max_concurrency=, defaulting to cfg.BulkConcurrency.
+// Values outside 1..cfg.MaxBulkConcurrency are clamped rather than rejected.
+func bulkConcurrency(r *http.Request) (int, error) {
+	ceiling := cfg.MaxBulkConcurrency
+	if ceiling <= 0 {
+		ceiling = DefaultMaxBulkConcurrency
+	}
+	n := cfg.BulkConcurrency
+	if n <= 0 {
+		n = DefaultBulkConcurrency
+	}
+
+	if raw := r.URL.Query().Get("max_concurrency"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return 0, fmt.Errorf("invalid max_concurrency %q", raw)
+		}
+		n = v
+	}
+	return min(max(n, 1), ceiling), nil
+}
+
+// buildAll runs build for every service on at most workers goroutines,
+// returning the incidents in the order the services were given
+func buildAll(services []string, workers int, build func(string) correlation.Incident) []correlation.Incident {
+	built := make([]correlation.Incident, len(services))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(services)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				built[i] = build(services[i])
+			}
+		}()
+	}
+	for i := range services {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return built
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+func TestBulkConcurrency(t *testing.T) {
+	Configure(config.Config{BulkConcurrency: 4, MaxBulkConcurrency: 8})
+	defer Configure(config.Config{})
+
+	testCases := []struct {
+		query    string
+		expected int
+	}{
+		{"", 4},
+		{"?max_concurrency=2", 2},
+		{"?max_concurrency=8", 8},
+		{"?max_concurrency=50", 8},
+		{"?max_concurrency=0", 1},
+		{"?max_concurrency=-3", 1},
+	}
+
+	for _, tc := range testCases {
+		got, err := bulkConcurrency(httptest.NewRequest("POST", "/api/incidents/bulk"+tc.query, nil))
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.query, err)
+			continue
+		}
+		if got != tc.expected {
+			t.Errorf("%q: expected concurrency %d, got %d", tc.query, tc.expected, got)
+		}
+	}
+
+	if _, err := bulkConcurrency(httptest.NewRequest("POST", "/api/incidents/bulk?max_concurrency=lots", nil)); err == nil {
+		t.Error("Expected an error for a non-numeric max_concurrency")
+	}
+}
+
+func TestBuildAllRespectsConcurrency(t *testing.T) {
+	services := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+
+	for _, workers := range []int{1, 3, 20} {
+		var mu sync.Mutex
+		inFlight, peak := 0, 0
+		built := buildAll(services, workers, func(service string) correlation.Incident {
+			mu.Lock()
+			inFlight++
+			peak = max(peak, inFlight)
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return correlation.Incident{Service: service}
+		})
+
+		if limit := min(workers, len(services)); peak > limit {
+			t.Errorf("workers=%d: expected at most %d builds in flight, got %d", workers, limit, peak)
+		}
+		for i, incident := range built {
+			if incident.Service != services[i] {
+				t.Errorf("workers=%d: expected incident %d for %s, got %s", workers, i, services[i], incident.Service)
+			}
+		}
+	}
+}
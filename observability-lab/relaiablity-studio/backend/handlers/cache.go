@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sarikasharma2428-web/reliability-studio/metrics"
+)
+
+// Cached adds Cache-Control and ETag headers to a GET handler's successful
+// responses, answering 304 Not Modified when If-None-Match matches. max-age
+// comes from cfg for the matched route template. Authenticated responses are
+// marked private and vary on Authorization, so a shared cache never serves one
+// user's response to another. Not for streaming handlers: the response is
+// buffered to hash it.
+//
+// Handlers whose body changes between otherwise identical responses, e.g.
+// with the age of the data, set their own ETag from stable content with
+// ETagFor; the rest get a hash of the body.
+func Cached(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		buf := &bufferedResponse{header: w.Header(), status: http.StatusOK}
+		h(buf, r)
+
+		if buf.status != http.StatusOK {
+			w.WriteHeader(buf.status)
+			w.Write(buf.body.Bytes())
+			return
+		}
+
+		etag := w.Header().Get("ETag")
+		if etag == "" {
+			etag = ETagFor(buf.body.String())
+			w.Header().Set("ETag", etag)
+		}
+		cacheControl := fmt.Sprintf("max-age=%d", int(cfg.CacheMaxAgeFor(metrics.RouteTemplate(r)).Seconds()))
+		if r.Header.Get("Authorization") != "" {
+			cacheControl = "private, " + cacheControl
+			w.Header().Add("Vary", "Authorization")
+		}
+		w.Header().Set("Cache-Control", cacheControl)
+
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write(buf.body.Bytes())
+	}
+}
+
+// ETagFor returns a strong ETag identifying parts
+func ETagFor(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header lists etag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponse captures a handler's status and body, sharing the real
+// writer's headers
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header         { return b.header }
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
+func (b *bufferedResponse) WriteHeader(status int)      { b.status = status }
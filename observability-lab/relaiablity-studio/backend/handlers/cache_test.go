@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+func TestCachedETag(t *testing.T) {
+	Configure(config.Config{
+		CacheMaxAge:       5 * time.Second,
+		CacheMaxAgeRoutes: map[string]time.Duration{"/api/slo/budget": time.Minute},
+	})
+	defer Configure(config.Config{})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/incident/{service}", Cached(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"service":"` + mux.Vars(r)["service"] + `"}`))
+	}))
+	router.HandleFunc("/api/slo/budget", Cached(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	router.HandleFunc("/api/broken", Cached(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream down", http.StatusBadGateway)
+	}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/incident/checkout", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Body.String() != `{"service":"checkout"}` {
+		t.Fatalf("Expected 200 with an ETag, got %d %q %q", rec.Code, etag, rec.Body)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "max-age=5" {
+		t.Errorf("Expected default max-age=5, got %q", got)
+	}
+
+	req := httptest.NewRequest("GET", "/api/incident/checkout", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("Expected 304 with no body for a matching ETag, got %d %q", rec.Code, rec.Body)
+	}
+
+	req = httptest.NewRequest("GET", "/api/incident/payments", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("Expected a different body to get 200 and a new ETag, got %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/slo/budget", nil))
+	if got := rec.Header().Get("Cache-Control"); got != "max-age=60" {
+		t.Errorf("Expected the per-route max-age=60, got %q", got)
+	}
+
+	req = httptest.NewRequest("GET", "/api/slo/budget", nil)
+	req.SetBasicAuth("oncall", "secret")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Cache-Control"); got != "private, max-age=60" || rec.Header().Get("Vary") != "Authorization" {
+		t.Errorf("Expected an authenticated response private and varying on Authorization, got %q %q", got, rec.Header().Get("Vary"))
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/broken", nil))
+	if rec.Code != http.StatusBadGateway || rec.Header().Get("ETag") != "" || rec.Header().Get("Cache-Control") != "" {
+		t.Errorf("Expected errors passed through uncached, got %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestCachedETagIgnoresAge(t *testing.T) {
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1705284840,"0.0042"]}]}}`))
+	}))
+	defer prom.Close()
+	services.SetUpstreams(prom.URL, "", "")
+	defer services.SetUpstreams("", "", "")
+
+	Configure(config.Config{CacheMaxAge: time.Minute})
+	defer Configure(config.Config{})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/incident/{service}", Cached(GetServiceIncident))
+	router.HandleFunc("/api/slo/status", Cached(GetSLOStatus))
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// A cached incident ages between requests, but is the same incident
+	computed := time.Now().Add(-30 * time.Second)
+	latest.put("checkout", correlation.Incident{ID: "checkout-cached", Service: "checkout", Fingerprint: "abc", ComputedAt: computed}, computed)
+	defer latest.evict("checkout")
+
+	first := get("/api/incident/checkout", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d %q", first.Code, etag)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if rec := get("/api/incident/checkout", etag); rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for an older copy of the same incident, got %d", rec.Code)
+	}
+
+	// A rebuilt incident is new
+	rebuilt := time.Now()
+	latest.put("checkout", correlation.Incident{ID: "checkout-rebuilt", Service: "checkout", Fingerprint: "abc", ComputedAt: rebuilt}, rebuilt)
+	if rec := get("/api/incident/checkout", etag); rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("Expected 200 and a new ETag for a rebuilt incident, got %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+
+	// computed_at differs on every SLO status, the result doesn't
+	path := "/api/slo/status?query=job:http_error_rate:ratio"
+	etag = get(path, "").Header().Get("ETag")
+	if rec := get(path, etag); etag == "" || rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for an unchanged SLO status, got %d (ETag %q)", rec.Code, etag)
+	}
+}
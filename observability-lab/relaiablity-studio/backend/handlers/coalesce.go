@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"sync"
+
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+// builds coalesces concurrent identical incident builds
+var builds = &buildGroup{}
+
+// buildGroup runs at most one build per key at a time: callers that arrive
+// while a build is in flight wait for it and share its incident, so a burst
+// of dashboard requests fans out to the upstreams once
+type buildGroup struct {
+	mu    sync.Mutex
+	calls map[string]*buildCall
+
+	// joined, if set, is called when a caller starts waiting on a build in
+	// flight; tests use it to know every caller has joined
+	joined func(key string)
+}
+
+type buildCall struct {
+	done     chan struct{}
+	incident correlation.Incident
+}
+
+// do runs build for key unless one is already in flight, and reports whether
+// the incident was shared with another caller
+func (g *buildGroup) do(key string, build func() correlation.Incident) (correlation.Incident, bool) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		if g.joined != nil {
+			g.joined(key)
+		}
+		<-call.done
+		return call.incident, true
+	}
+	if g.calls == nil {
+		g.calls = make(map[string]*buildCall)
+	}
+	call := &buildCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	call.incident = build()
+	return call.incident, false
+}
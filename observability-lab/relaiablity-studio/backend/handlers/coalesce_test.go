@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+func TestConcurrentIncidentRequestsShareOneBuild(t *testing.T) {
+	var logQueries int32
+	release := make(chan struct{})
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer prom.Close()
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&logQueries, 1)
+		<-release // Keep the build in flight until the others have joined it
+		w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[]}}`))
+	}))
+	defer loki.Close()
+	defer func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	}()
+	tempo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"traces":[]}`))
+	}))
+	defer tempo.Close()
+	services.SetUpstreams(prom.URL, loki.URL, tempo.URL)
+	defer services.SetUpstreams("", "", "")
+
+	// No incident cache, so only coalescing can save the duplicate builds
+	Configure(config.Config{})
+	defer Configure(config.Config{})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/incident/{service}", GetServiceIncident).Methods("GET")
+
+	const clients = 20
+	joined := make(chan struct{}, clients)
+	builds.joined = func(string) { joined <- struct{}{} }
+	defer func() { builds.joined = nil }()
+
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/incident/checkout", nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("Expected status 200, got %d: %s", rec.Code, rec.Body)
+			}
+		}()
+	}
+	for i := 0; i < clients-1; i++ {
+		select {
+		case <-joined:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected %d requests to join the build in flight, got %d", clients-1, i)
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&logQueries); n != 1 {
+		t.Errorf("Expected Loki to be queried once for %d concurrent requests, got %d", clients, n)
+	}
+	if n := len(History().List("checkout")); n != 1 {
+		t.Errorf("Expected one incident built, got %d", n)
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+// DefaultDashboardLookback is how far before a live incident was computed its
+// dashboard links start, unless it has been ongoing for longer
+const DefaultDashboardLookback = time.Hour
+
+// withDashboards links the configured Grafana dashboards to the incident,
+// with var-service set to its service and from/to to window, or for live
+// incidents to the time since it began (at least DefaultDashboardLookback)
+func withDashboards(incident correlation.Incident, window correlation.TimeRange) correlation.Incident {
+	if cfg.GrafanaURL == "" || len(cfg.GrafanaDashboards) == 0 {
+		return incident
+	}
+
+	from, to := window.Start, window.End
+	if from.IsZero() {
+		to = incident.ComputedAt
+		if to.IsZero() {
+			to = time.Now()
+		}
+		from = to.Add(-DefaultDashboardLookback)
+		if incident.FirstSeen != nil && incident.FirstSeen.Before(from) {
+			from = *incident.FirstSeen
+		}
+		if incident.Anchor != nil && incident.Anchor.Before(from) {
+			from = *incident.Anchor
+		}
+	}
+
+	params := url.Values{}
+	params.Set("var-service", incident.Service)
+	params.Set("from", strconv.FormatInt(from.UnixMilli(), 10))
+	params.Set("to", strconv.FormatInt(to.UnixMilli(), 10))
+
+	names := make([]string, 0, len(cfg.GrafanaDashboards))
+	for name := range cfg.GrafanaDashboards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	incident.Dashboards = make([]correlation.DashboardLink, 0, len(names))
+	for _, name := range names {
+		incident.Dashboards = append(incident.Dashboards, correlation.DashboardLink{
+			Name: name,
+			URL:  cfg.GrafanaURL + "/d/" + url.PathEscape(cfg.GrafanaDashboards[name]) + "?" + params.Encode(),
+		})
+	}
+	return incident
+}
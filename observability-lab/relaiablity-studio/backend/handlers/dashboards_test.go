@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+func TestWithDashboards(t *testing.T) {
+	Configure(config.Config{
+		GrafanaURL:        "https://grafana.example.com",
+		GrafanaDashboards: map[string]string{"service overview": "svc-overview", "latency": "http-latency"},
+	})
+	defer Configure(config.Config{})
+
+	window := correlation.TimeRange{
+		Start: time.Date(2024, 1, 15, 2, 9, 0, 0, time.UTC),
+		End:   time.Date(2024, 1, 15, 2, 19, 0, 0, time.UTC),
+	}
+	incident := withDashboards(correlation.Incident{Service: "checkout"}, window)
+	if len(incident.Dashboards) != 2 || incident.Dashboards[0].Name != "latency" || incident.Dashboards[1].Name != "service overview" {
+		t.Fatalf("Expected both dashboards linked by name, got %+v", incident.Dashboards)
+	}
+
+	link, err := url.Parse(incident.Dashboards[1].URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if link.Host != "grafana.example.com" || link.Path != "/d/svc-overview" {
+		t.Errorf("Expected the svc-overview dashboard on grafana.example.com, got %s", link)
+	}
+	params := link.Query()
+	if params.Get("var-service") != "checkout" {
+		t.Errorf("Expected var-service=checkout, got %q", params.Get("var-service"))
+	}
+	if params.Get("from") != "1705284540000" || params.Get("to") != "1705285140000" {
+		t.Errorf("Expected the window in Unix milliseconds, got from=%s to=%s", params.Get("from"), params.Get("to"))
+	}
+
+	// A live incident ongoing for 3h links from when it was first seen
+	computed := time.Date(2024, 1, 15, 5, 0, 0, 0, time.UTC)
+	firstSeen := computed.Add(-3 * time.Hour)
+	live := withDashboards(correlation.Incident{Service: "checkout", ComputedAt: computed, FirstSeen: &firstSeen}, correlation.TimeRange{})
+	if !strings.Contains(live.Dashboards[0].URL, "from=1705284000000&to=1705294800000") {
+		t.Errorf("Expected a live link from first seen to computed at, got %s", live.Dashboards[0].URL)
+	}
+
+	Configure(config.Config{})
+	if none := withDashboards(correlation.Incident{Service: "checkout"}, window); none.Dashboards != nil {
+		t.Errorf("Expected no links without GRAFANA_URL, got %+v", none.Dashboards)
+	}
+}
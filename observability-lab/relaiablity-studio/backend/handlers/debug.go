@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+// GetSourceDebug returns the raw upstream bodies behind one of a service's
+// sources (GET /api/debug/source?type=logs|metrics|traces|k8s&service=...),
+// to diagnose incidents that were parsed wrongly. Bodies are keyed by the
+// query that produced them and pretty-printed when they are JSON. Upstream
+// payloads can expose internal data, so this is off unless DEBUG_ENDPOINTS
+// is set.
+func GetSourceDebug(w http.ResponseWriter, r *http.Request) {
+	if !cfg.DebugEndpoints {
+		http.NotFound(w, r)
+		return
+	}
+	service := r.URL.Query().Get("service")
+	if service == "" {
+		http.Error(w, "service is required", http.StatusBadRequest)
+		return
+	}
+	queries, err := cfg.QueryTemplates.For(service)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bodies := make(map[string]string)
+	switch r.URL.Query().Get("type") {
+	case "logs":
+		bodies["log_query"] = debugBody(services.QueryLogs(r.Context(), queries.Log))
+	case "metrics":
+		bodies["error_query"] = debugBody(services.QueryMetrics(r.Context(), queries.Error))
+		bodies["latency_query"] = debugBody(services.QueryMetrics(r.Context(), queries.Latency))
+	case "traces":
+		if cfg.TraceSource == TraceSourceOTLP {
+			http.Error(w, "Traces are pushed over OTLP; there is no upstream query", http.StatusBadRequest)
+			return
+		}
+		if queries.Trace != "" {
+			bodies["trace_query"] = debugBody(services.SearchTraceQL(r.Context(), queries.Trace, time.Time{}, time.Time{}))
+		} else {
+			bodies["search"] = debugBody(services.GetTraces(r.Context()))
+		}
+	case "k8s":
+		namespace, err := services.NamespaceFor(service)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, state := range services.GetClusters(r.Context(), namespace) {
+			name := state.Cluster
+			if name == "" {
+				name = "current-context"
+			}
+			bodies[name] = debugBody(state.Raw, state.Err)
+		}
+	default:
+		http.Error(w, "type must be one of logs, metrics, traces or k8s", http.StatusBadRequest)
+		return
+	}
+
+	// Bodies that aren't JSON (e.g. an upstream error) are kept as strings
+	raw := make(map[string]any, len(bodies))
+	for name, body := range bodies {
+		if json.Valid([]byte(body)) {
+			raw[name] = json.RawMessage(body)
+		} else {
+			raw[name] = body
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(raw)
+}
+
+// debugBody returns an upstream body, or the error when no upstream answered
+func debugBody(body string, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return body
+}
+
+// GetConfigDebug returns the configuration the backend loaded (GET
+// /api/debug/config), to confirm which upstreams and settings are in effect.
+// Secrets are redacted and durations rendered like "15s". Even redacted, the
+// upstream topology is internal, so this is off unless DEBUG_ENDPOINTS is set.
+func GetConfigDebug(w http.ResponseWriter, r *http.Request) {
+	if !cfg.DebugEndpoints {
+		http.NotFound(w, r)
+		return
+	}
+	v := reflect.ValueOf(cfg.Redacted())
+	fields := make(map[string]any, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		value := v.Field(i).Interface()
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+		fields[v.Type().Field(i).Name] = value
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fields)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// parseFields reads ?fields=, a comma-separated list of the top-level JSON
+// fields of T to respond with, e.g. ?fields=severity,summary. No parameter
+// means every field; names T doesn't have are an error.
+func parseFields[T any](r *http.Request) ([]string, error) {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil, nil
+	}
+
+	known := jsonFields(reflect.TypeOf((*T)(nil)).Elem())
+	var fields []string
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !known[f] {
+			return nil, fmt.Errorf("unknown field %q", f)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// jsonFields returns the JSON names of a struct's exported fields
+func jsonFields(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// respondFields writes v as JSON, keeping only the given top-level fields
+// when there are any
+func respondFields(w http.ResponseWriter, v any, fields []string) {
+	w.Header().Set("Content-Type", "application/json")
+	if len(fields) == 0 {
+		json.NewEncoder(w).Encode(v)
+		return
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &all); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	projected := make(map[string]json.RawMessage, len(fields))
+	for _, f := range fields {
+		if value, ok := all[f]; ok {
+			projected[f] = value
+		}
+	}
+	json.NewEncoder(w).Encode(projected)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+func TestServiceIncidentFields(t *testing.T) {
+	Configure(config.Config{CacheMaxAge: time.Minute})
+	defer Configure(config.Config{})
+	latest.put("checkout", correlation.Incident{
+		ID:       "checkout-1",
+		Service:  "checkout",
+		Severity: "critical",
+		Summary:  "checkout is critical",
+		Timeline: []correlation.Event{{Source: "logs", Message: "error: db down"}},
+	}, time.Now())
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/incident/{service}", GetServiceIncident).Methods("GET")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/incident/checkout?fields=severity,summary", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var body map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(body) != 2 || body["severity"] != "critical" || body["summary"] != "checkout is critical" {
+		t.Errorf("Expected only severity and summary, got %v", body)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/incident/checkout?fields=severity,blast_radius", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown field, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// DefaultHistoryWindow is how far back GetIncidentHistory looks without ?since=
+const DefaultHistoryWindow = 7 * 24 * time.Hour
+
+// GetIncidentHistory groups a service's stored incidents by fingerprint
+// (GET /api/incident/{service}/history), so recurring issues show how often
+// they happened. ?since= is a lookback duration, e.g. 24h (default 7 days).
+func GetIncidentHistory(w http.ResponseWriter, r *http.Request) {
+	service := mux.Vars(r)["service"]
+
+	window := DefaultHistoryWindow
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			http.Error(w, fmt.Sprintf("invalid since %q", raw), http.StatusBadRequest)
+			return
+		}
+		window = d
+	}
+	since := time.Now().Add(-window)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"service":     service,
+		"since":       since.UTC(),
+		"recurrences": history.Recurrences(service, since),
+	})
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	_ "time/tzdata" // Runtime images don't ship a zoneinfo database
+
+	"github.com/gorilla/mux"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+	"github.com/sarikasharma2428-web/reliability-studio/notify"
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+	"github.com/sarikasharma2428-web/reliability-studio/store"
+	"github.com/sarikasharma2428-web/reliability-studio/stream"
+)
+
+// MaxIncidentWindow bounds how much history a single re-analysis may cover
+const MaxIncidentWindow = 24 * time.Hour
+
+var (
+	cfg config.Config
+
+	// incidents streams every live incident built by GetServiceIncident to SSE clients
+	incidents = stream.NewBroker(stream.DefaultClientBuffer)
+
+	// history keeps every live incident built by GetServiceIncident
+	history = store.New(store.Retention{})
+
+	// pager pages for critical incidents that aren't acknowledged or in maintenance
+	pager = notify.NewDispatcher(nil)
+
+	// recovery holds services at "recovering" until they stay healthy
+	recovery = correlation.NewRecoveryTracker(0)
+)
+
+// Configure sets the configuration used by the incident handlers
+func Configure(c config.Config) {
+	cfg = c
+	incidents = stream.NewBroker(c.StreamClientBuffer)
+	incidents.MaxSubscribers = c.StreamMaxClients
+	incidents.WriteTimeout = c.ServerWriteTimeout
+	history = store.New(store.Retention{MaxAge: c.IncidentRetention, MaxPerService: c.IncidentRetentionCount})
+
+	var notifiers []notify.Notifier
+	if c.NotifyWebhookURL != "" {
+		notifiers = append(notifiers, notify.Webhook{URL: c.NotifyWebhookURL, Retries: c.NotifyRetries})
+	}
+	if c.IncidentWebhookURL != "" {
+		notifiers = append(notifiers, notify.IncidentWebhook{
+			URL:     c.IncidentWebhookURL,
+			Headers: c.IncidentWebhookHeaders,
+			Secret:  c.IncidentWebhookSecret,
+			Retries: c.NotifyRetries,
+		})
+	}
+	pager = notify.NewDispatcher([]notify.Suppressor{history, c.MaintenanceWindows}, notifiers...)
+	pager.MinConfidence = c.MinPageConfidence
+	pager.NotifyWarnings = c.NotifyWarnings
+	pager.SeverityFloor = c.NotifySeverityFloor
+	pager.QuietHours = c.QuietHours
+	pager.QueueQuiet = c.QuietHoursMode == config.QuietQueue
+	firing = newAlertDedup(c.AlertDedupWindow)
+	pushed = newTraceBuffer(c.OTLPTraceWindow)
+	latest = newIncidentCache(c.CacheMaxAgeFor("/api/incident/{service}"))
+	recovery = correlation.NewRecoveryTracker(c.RecoveryEvaluations)
+}
+
+// History returns the store of built incidents
+func History() *store.Store {
+	return history
+}
+
+// StreamIncidents sends incidents to the client as Server-Sent Events as they are built
+func StreamIncidents(w http.ResponseWriter, r *http.Request) {
+	incidents.Handler()(w, r)
+}
+
+// GetServiceIncident correlates the logs, metrics, traces and k8s state of a service.
+// By default it reports on "now"; ?start=&end= re-runs the analysis over a past
+// window, sampling metrics every ?step= (default cfg.MetricStep). Log queries
+// estimated to match more than cfg.MaxLogVolume lines are rejected with 413.
+// Live incidents are reused for the route's cache max-age; see RefreshIncident.
+// ?fields= limits the response to the listed top-level fields.
+func GetServiceIncident(w http.ResponseWriter, r *http.Request) {
+	service := mux.Vars(r)["service"]
+
+	fields, err := parseFields[correlation.Incident](r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	loc, err := timelineLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	window, err := incidentWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	step, err := metricStep(r, window)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Live incidents are served from the cache while fresh; past windows are
+	// always recomputed
+	live := window.Start.IsZero()
+	incident, cached := correlation.Incident{}, false
+	if live {
+		incident, cached = latest.get(service, time.Now())
+	}
+	if !cached {
+		if err := checkLogVolume(r.Context(), service, window); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		// Concurrent requests for the same service and window share one build
+		key := fmt.Sprintf("%s|%d|%d|%s", service, window.Start.Unix(), window.End.Unix(), step)
+		var shared bool
+		incident, shared = builds.do(key, func() correlation.Incident {
+			return buildIncident(service, upstreamSources{templates: cfg.QueryTemplates, window: window, step: step})
+		})
+		if live && !shared {
+			latest.put(service, incident, time.Now())
+		}
+	}
+	incident.Timeline = correlation.InLocation(incident.Timeline, loc)
+	incident = withDashboards(incident, window)
+
+	// The incident is the same until it is rebuilt, whatever its age
+	w.Header().Set("ETag", ETagFor(incident.Fingerprint, incident.ComputedAt.Format(time.RFC3339Nano), r.URL.RawQuery))
+	respondFields(w, incident.WithAge(time.Now()), fields)
+}
+
+// buildIncident correlates a service's signals, then stores, streams and
+// (unless suppressed) pages for the incident. Re-analyses of a past window
+// are only returned: they report on history, not on the service now, so they
+// never page, advance recovery tracking or land in the incident history.
+func buildIncident(service string, src upstreamSources) correlation.Incident {
+	incident := correlation.BuildIncident(service, src)
+	_, incident.Suppressed = pager.Suppressed(service)
+	if src.windowed() {
+		return incident
+	}
+	incident = recovery.Apply(incident)
+	incident = history.Add(incident)
+	incidents.Publish(stream.Event{Name: "incident", Data: incident})
+	go pager.Dispatch(context.Background(), incident)
+	return incident
+}
+
+// GetCorrelationHealth reports, per source, when it last parsed cleanly, its
+// last error and whether its last response had the expected shape
+func GetCorrelationHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"sources": correlation.Health(),
+	})
+}
+
+// timelineLocation resolves the ?tz= parameter, falling back to the configured default
+func timelineLocation(r *http.Request) (*time.Location, error) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		tz = cfg.Timezone
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q", tz)
+	}
+	return loc, nil
+}
+
+// incidentWindow parses the optional ?start= and ?end= parameters (RFC3339 or
+// Unix seconds). A zero range means no window was requested.
+func incidentWindow(r *http.Request) (correlation.TimeRange, error) {
+	startParam := r.URL.Query().Get("start")
+	endParam := r.URL.Query().Get("end")
+	if startParam == "" && endParam == "" {
+		return correlation.TimeRange{}, nil
+	}
+	if startParam == "" || endParam == "" {
+		return correlation.TimeRange{}, fmt.Errorf("start and end must be given together")
+	}
+
+	start, err := parseWindowTime(startParam)
+	if err != nil {
+		return correlation.TimeRange{}, fmt.Errorf("invalid start %q", startParam)
+	}
+	end, err := parseWindowTime(endParam)
+	if err != nil {
+		return correlation.TimeRange{}, fmt.Errorf("invalid end %q", endParam)
+	}
+
+	if !start.Before(end) {
+		return correlation.TimeRange{}, fmt.Errorf("start must be before end")
+	}
+	if end.Sub(start) > MaxIncidentWindow {
+		return correlation.TimeRange{}, fmt.Errorf("window exceeds maximum of %s", MaxIncidentWindow)
+	}
+	return correlation.TimeRange{Start: start, End: end}, nil
+}
+
+// metricStep resolves the range query resolution from ?step= or the configured
+// default, and checks it against the window
+func metricStep(r *http.Request, window correlation.TimeRange) (time.Duration, error) {
+	step := cfg.MetricStep
+	if step <= 0 {
+		step = config.DefaultMetricStep
+	}
+	if raw := r.URL.Query().Get("step"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return 0, fmt.Errorf("invalid step %q", raw)
+		}
+		step = d
+	}
+
+	if window.Start.IsZero() {
+		return step, nil
+	}
+	if err := services.ValidateRange(window.Start, window.End, step); err != nil {
+		return 0, err
+	}
+	return step, nil
+}
+
+func parseWindowTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	secs, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, int64(secs*float64(time.Second))), nil
+}
@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+func TestGetServiceIncidentInvalidTimezone(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/incident/{service}", GetServiceIncident)
+
+	req := httptest.NewRequest("GET", "/api/incident/checkout?tz=Mars/Olympus_Mons", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid zone, got %d", rec.Code)
+	}
+}
+
+func TestTimelineLocationDefault(t *testing.T) {
+	Configure(config.Config{Timezone: "Asia/Kolkata"})
+	defer Configure(config.Config{})
+
+	loc, err := timelineLocation(httptest.NewRequest("GET", "/api/incident/checkout", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loc.String() != "Asia/Kolkata" {
+		t.Errorf("Expected configured default zone, got %s", loc)
+	}
+
+	loc, err = timelineLocation(httptest.NewRequest("GET", "/api/incident/checkout?tz=America/New_York", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loc.String() != "America/New_York" {
+		t.Errorf("Expected query param to override default, got %s", loc)
+	}
+}
+
+func TestGetServiceIncidentWindow(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]url.Values{}
+	record := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests[r.URL.Path] = r.URL.Query()
+			mu.Unlock()
+			w.Write([]byte(body))
+		}
+	}
+
+	prom := httptest.NewServer(record(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+	defer prom.Close()
+	loki := httptest.NewServer(record(`{"status":"success","data":{"resultType":"streams","result":[]}}`))
+	defer loki.Close()
+	tempo := httptest.NewServer(record(`{"traces":[]}`))
+	defer tempo.Close()
+
+	services.SetUpstreams(prom.URL, loki.URL, tempo.URL)
+	defer services.SetUpstreams("", "", "")
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/incident/{service}", GetServiceIncident)
+
+	// The 10 minutes around 02:14 UTC on 2024-01-15
+	req := httptest.NewRequest("GET", "/api/incident/checkout?start=2024-01-15T02:09:00Z&end=2024-01-15T02:19:00Z", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	checks := []struct {
+		path       string
+		start, end string
+	}{
+		{"/api/v1/query_range", "1705284540", "1705285140"},
+		{"/loki/api/v1/query_range", "1705284540000000000", "1705285140000000000"},
+		{"/api/search", "1705284540", "1705285140"},
+	}
+	for _, c := range checks {
+		params, ok := requests[c.path]
+		if !ok {
+			t.Errorf("Expected a request to %s, got %v", c.path, requests)
+			continue
+		}
+		if params.Get("start") != c.start || params.Get("end") != c.end {
+			t.Errorf("Expected %s bounded to [%s, %s], got [%s, %s]", c.path, c.start, c.end, params.Get("start"), params.Get("end"))
+		}
+	}
+	if step := requests["/api/v1/query_range"].Get("step"); step != "15" {
+		t.Errorf("Expected default 15s step, got %q", step)
+	}
+}
+
+func TestIncidentWindowValidation(t *testing.T) {
+	testCases := []struct {
+		name  string
+		query string
+		valid bool
+	}{
+		{"No window", "", true},
+		{"RFC3339", "start=2024-01-15T02:09:00Z&end=2024-01-15T02:19:00Z", true},
+		{"Unix seconds", "start=1705284540&end=1705285140", true},
+		{"Missing end", "start=1705284540", false},
+		{"Start after end", "start=1705285140&end=1705284540", false},
+		{"Equal bounds", "start=1705284540&end=1705284540", false},
+		{"Too long", "start=2024-01-01T00:00:00Z&end=2024-01-03T00:00:00Z", false},
+		{"Garbage", "start=yesterday&end=today", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := incidentWindow(httptest.NewRequest("GET", "/api/incident/checkout?"+tc.query, nil))
+			if (err == nil) != tc.valid {
+				t.Errorf("Expected valid=%v, got err=%v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestMetricStep(t *testing.T) {
+	Configure(config.Config{MetricStep: 30 * time.Second})
+	defer Configure(config.Config{})
+
+	window := correlation.TimeRange{
+		Start: time.Date(2024, 1, 15, 2, 9, 0, 0, time.UTC),
+		End:   time.Date(2024, 1, 15, 2, 19, 0, 0, time.UTC),
+	}
+	day := correlation.TimeRange{Start: window.Start, End: window.Start.Add(24 * time.Hour)}
+
+	testCases := []struct {
+		name     string
+		query    string
+		window   correlation.TimeRange
+		expected time.Duration
+		valid    bool
+	}{
+		{"Configured default", "", window, 30 * time.Second, true},
+		{"Override", "step=1m", window, time.Minute, true},
+		{"Too many points", "step=1s", day, 0, false},
+		{"Non-positive", "step=0s", window, 0, false},
+		{"Garbage", "step=often", window, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			step, err := metricStep(httptest.NewRequest("GET", "/api/incident/checkout?"+tc.query, nil), tc.window)
+			if (err == nil) != tc.valid {
+				t.Fatalf("Expected valid=%v, got err=%v", tc.valid, err)
+			}
+			if tc.valid && step != tc.expected {
+				t.Errorf("Expected step %s, got %s", tc.expected, step)
+			}
+		})
+	}
+}
+
+// mixedSources returns good logs and metrics, an error for traces and a
+// malformed k8s response
+type mixedSources struct{}
+
+func (mixedSources) Logs(context.Context, string) (analysis.LogResult, error) {
+	return analysis.LogResult{}, nil
+}
+func (mixedSources) Metrics(context.Context, string) (analysis.MetricResult, error) {
+	return analysis.MetricResult{ErrorRate: 1.5}, nil
+}
+func (mixedSources) Traces(context.Context, string) (analysis.TraceResult, error) {
+	return analysis.TraceResult{}, errors.New("tempo unreachable")
+}
+func (mixedSources) K8s(_ context.Context, service string) (analysis.K8sResult, error) {
+	return analysis.AnalyzeK8s(service, `{"kind":"Status"}`)
+}
+
+func TestGetCorrelationHealth(t *testing.T) {
+	correlation.BuildIncident("checkout", mixedSources{})
+
+	rec := httptest.NewRecorder()
+	GetCorrelationHealth(rec, httptest.NewRequest("GET", "/api/correlation/health", nil))
+
+	var body struct {
+		Sources map[string]correlation.SourceHealth `json:"sources"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode health: %v", err)
+	}
+
+	for _, name := range []string{"logs", "metrics"} {
+		h := body.Sources[name]
+		if h.LastSuccess == nil || !h.ExpectedShape {
+			t.Errorf("Expected %s healthy, got %+v", name, h)
+		}
+	}
+	if h := body.Sources["traces"]; h.LastError != "tempo unreachable" || h.LastErrorAt == nil || !h.ExpectedShape {
+		t.Errorf("Expected traces error recorded with a valid shape, got %+v", h)
+	}
+	if h := body.Sources["k8s"]; h.LastError == "" || h.ExpectedShape {
+		t.Errorf("Expected k8s flagged as unexpected shape, got %+v", h)
+	}
+}
+
+// failingUpstreams serves an error log line for every log query, so incidents
+// built from them are warnings, until the returned func makes them healthy
+func failingUpstreams(t *testing.T) (heal func()) {
+	t.Helper()
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "kubectl"), []byte("#!/bin/sh\necho '{\"items\":[]}'\n"), 0o755); err != nil {
+		t.Fatalf("failed to write fake kubectl: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var healthy atomic.Bool
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	t.Cleanup(prom.Close)
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		line := "error: db connection refused"
+		if healthy.Load() {
+			line = "request served"
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"checkout"},"values":[["1705284838000000000","` + line + `"]]}]}}`))
+	}))
+	t.Cleanup(loki.Close)
+	tempo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"traces":[]}`))
+	}))
+	t.Cleanup(tempo.Close)
+	services.SetUpstreams(prom.URL, loki.URL, tempo.URL)
+	t.Cleanup(func() { services.SetUpstreams("", "", "") })
+	return func() { healthy.Store(true) }
+}
+
+func TestBuildIncidentWindowedDoesNotPage(t *testing.T) {
+	failingUpstreams(t)
+	pages := make(chan correlation.Incident, 4)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var incident correlation.Incident
+		json.NewDecoder(r.Body).Decode(&incident)
+		pages <- incident
+	}))
+	defer webhook.Close()
+	Configure(config.Config{NotifyWebhookURL: webhook.URL, NotifyWarnings: true})
+	defer Configure(config.Config{})
+
+	past := correlation.TimeRange{Start: time.Now().Add(-7 * 24 * time.Hour), End: time.Now().Add(-7*24*time.Hour + time.Hour)}
+	if incident := buildIncident("checkout", upstreamSources{window: past}); incident.Severity != "warning" {
+		t.Fatalf("Expected a warning for the past window, got %s", incident.Severity)
+	}
+	select {
+	case incident := <-pages:
+		t.Fatalf("Expected no page for a past window, got one for %s", incident.ID)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	buildIncident("checkout", upstreamSources{})
+	select {
+	case <-pages:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a page for the live incident")
+	}
+}
+
+func TestBuildIncidentWindowedLeavesRecovery(t *testing.T) {
+	heal := failingUpstreams(t)
+	Configure(config.Config{RecoveryEvaluations: 2})
+	defer Configure(config.Config{})
+
+	if incident := buildIncident("checkout", upstreamSources{}); incident.Severity != "warning" {
+		t.Fatalf("Expected a live warning, got %s", incident.Severity)
+	}
+	heal()
+
+	past := correlation.TimeRange{Start: time.Now().Add(-7 * 24 * time.Hour), End: time.Now().Add(-7*24*time.Hour + time.Hour)}
+	if incident := buildIncident("checkout", upstreamSources{window: past}); incident.Severity != "healthy" {
+		t.Errorf("Expected the past window reported as it was, healthy, got %s", incident.Severity)
+	}
+	if incident := buildIncident("checkout", upstreamSources{}); incident.Severity != correlation.SeverityRecovering {
+		t.Errorf("Expected the first live healthy build still recovering, got %s", incident.Severity)
+	}
+}
+
+func TestBuildIncidentWindowedNotStored(t *testing.T) {
+	failingUpstreams(t)
+	Configure(config.Config{})
+	defer Configure(config.Config{})
+	sub := incidents.Subscribe()
+	defer incidents.Unsubscribe(sub)
+
+	past := correlation.TimeRange{Start: time.Now().Add(-7 * 24 * time.Hour), End: time.Now().Add(-7*24*time.Hour + time.Hour)}
+	if incident := buildIncident("checkout", upstreamSources{window: past}); incident.FirstSeen != nil {
+		t.Errorf("Expected no first_seen for a past window, got %s", incident.FirstSeen)
+	}
+	if records := history.List("checkout"); len(records) != 0 {
+		t.Errorf("Expected a past window kept out of history, got %d records", len(records))
+	}
+	if active := history.Active(); len(active) != 0 {
+		t.Errorf("Expected no active incidents from a past window, got %d", len(active))
+	}
+	select {
+	case event := <-sub.Events():
+		t.Errorf("Expected a past window not streamed, got %+v", event)
+	default:
+	}
+
+	if incident := buildIncident("checkout", upstreamSources{}); incident.FirstSeen == nil || len(history.List("checkout")) != 1 {
+		t.Errorf("Expected the live incident stored with a first_seen, got %+v", incident)
+	}
+}
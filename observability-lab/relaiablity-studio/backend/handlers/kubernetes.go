@@ -8,7 +8,11 @@ import (
 )
 
 func GetK8sStatus(w http.ResponseWriter, r *http.Request) {
-	data := services.GetCluster()
+	data, err := services.GetCluster()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
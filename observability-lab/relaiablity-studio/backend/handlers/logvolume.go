@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+// logVolumeLookback is the span estimated for incidents about "now"
+const logVolumeLookback = time.Hour
+
+// checkLogVolume rejects a service's log query when Loki estimates it matches
+// more than cfg.MaxLogVolume lines. The estimate is best-effort: if Loki can't
+// provide one, the query runs as usual.
+func checkLogVolume(ctx context.Context, service string, window correlation.TimeRange) error {
+	if cfg.MaxLogVolume <= 0 {
+		return nil
+	}
+	queries, err := cfg.QueryTemplates.For(service)
+	if err != nil {
+		return nil // Reported as a logs source error when the incident is built
+	}
+
+	start, end := window.Start, window.End
+	if start.IsZero() {
+		end = time.Now()
+		start = end.Add(-logVolumeLookback)
+	}
+	entries, err := services.LogVolume(ctx, queries.Log, start, end)
+	if err != nil {
+		log.Printf("Warning: Could not estimate log volume for %s: %v", service, err)
+		return nil
+	}
+	if entries > int64(cfg.MaxLogVolume) {
+		return fmt.Errorf("log query for %s matches about %d lines between %s and %s, over the limit of %d; "+
+			"narrow its stream selector in QUERY_TEMPLATES or request a shorter ?start=&end= window",
+			service, entries, start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339), cfg.MaxLogVolume)
+	}
+	return nil
+}
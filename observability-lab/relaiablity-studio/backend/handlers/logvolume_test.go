@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+func TestGetServiceIncidentRejectsHighLogVolume(t *testing.T) {
+	var mu sync.Mutex
+	var selector string
+	pulled := false
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/loki/api/v1/index/stats" {
+			selector = r.URL.Query().Get("query")
+			w.Write([]byte(`{"streams":120,"chunks":9000,"entries":25000000,"bytes":4000000000}`))
+			return
+		}
+		pulled = true
+		w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[]}}`))
+	}))
+	defer loki.Close()
+	services.SetUpstreams("", loki.URL, "")
+	defer services.SetUpstreams("", "", "")
+
+	Configure(config.Config{MaxLogVolume: 1000000})
+	defer Configure(config.Config{})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/incident/{service}", GetServiceIncident)
+	req := httptest.NewRequest("GET", "/api/incident/checkout?start=2024-01-15T02:09:00Z&end=2024-01-15T02:19:00Z", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected status 413, got %d: %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), "25000000 lines") || !strings.Contains(rec.Body.String(), "narrow its stream selector") {
+		t.Errorf("Expected the estimate and guidance in the response, got %q", rec.Body)
+	}
+	if selector != `{app="checkout"}` {
+		t.Errorf("Expected only the stream selector estimated, got %q", selector)
+	}
+	if pulled {
+		t.Error("Expected the log lines not to be pulled")
+	}
+}
+
+func TestCheckLogVolumeAllowsLowEstimate(t *testing.T) {
+	Configure(config.Config{MaxLogVolume: 10, QueryTemplates: config.QueryTemplates{
+		"checkout": {LogQuery: `sum(count_over_time({app="{{.Service}}", msg=~"x}y"} |= "error" [5m]))`},
+	}})
+	defer Configure(config.Config{})
+
+	var selector string
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		selector = r.URL.Query().Get("query")
+		w.Write([]byte(`{"entries":5}`))
+	}))
+	defer loki.Close()
+	services.SetUpstreams("", loki.URL, "")
+	defer services.SetUpstreams("", "", "")
+
+	if err := checkLogVolume(context.Background(), "checkout", correlation.TimeRange{}); err != nil {
+		t.Errorf("Expected a low estimate to pass, got %v", err)
+	}
+	if want := `{app="checkout", msg=~"x}y"}`; selector != want {
+		t.Errorf("Expected selector %q, got %q", want, selector)
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// NDJSONContentType is the media type of newline-delimited JSON
+const NDJSONContentType = "application/x-ndjson"
+
+// WantsNDJSON reports whether the client's Accept header asks for NDJSON
+func WantsNDJSON(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept)); err == nil && mediaType == NDJSONContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// RespondList writes items as a JSON array, or as NDJSON when the client
+// accepts it: one item per line, flushed as it is written so large lists can
+// be processed while they stream
+func RespondList[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	if !WantsNDJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(items)
+		return
+	}
+
+	w.Header().Set("Content-Type", NDJSONContentType)
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return // Client went away
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
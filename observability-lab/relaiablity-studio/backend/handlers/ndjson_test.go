@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+func TestRespondListNDJSON(t *testing.T) {
+	timeline := []correlation.Event{
+		{Time: "2024-01-15T02:13:58Z", Source: "logs", Type: correlation.EventErrorLog, Message: "DB connection timeout"},
+		{Time: "2024-01-15T02:14:00Z", Source: "kubernetes", Type: correlation.EventPodFailure, Message: "Pod failed"},
+		{Time: "2024-01-15T02:14:05Z", Source: "traces", Type: correlation.EventTraceFailure, Message: "Trace failure"},
+	}
+
+	req := httptest.NewRequest("GET", "/api/incidents/1/timeline", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	RespondList(rec, req, timeline)
+
+	if ct := rec.Header().Get("Content-Type"); ct != NDJSONContentType {
+		t.Errorf("Expected Content-Type %s, got %s", NDJSONContentType, ct)
+	}
+	if !rec.Flushed {
+		t.Error("Expected NDJSON output to be flushed")
+	}
+
+	scanner := bufio.NewScanner(rec.Body)
+	lines := 0
+	for scanner.Scan() {
+		var e correlation.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("Line %d is not a JSON object: %q (%v)", lines+1, scanner.Text(), err)
+		}
+		if e.Message != timeline[lines].Message || e.Type != timeline[lines].Type {
+			t.Errorf("Expected line %d to be %+v, got %+v", lines+1, timeline[lines], e)
+		}
+		lines++
+	}
+	if lines != len(timeline) {
+		t.Errorf("Expected %d lines, got %d", len(timeline), lines)
+	}
+}
+
+func TestRespondListDefaultsToJSONArray(t *testing.T) {
+	for _, accept := range []string{"", "application/json", "text/html, */*"} {
+		req := httptest.NewRequest("GET", "/api/incidents/1/timeline", nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		RespondList(rec, req, []correlation.Event{{Source: "logs"}, {Source: "traces"}})
+
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Accept %q: expected application/json, got %s", accept, ct)
+		}
+		var events []correlation.Event
+		if err := json.Unmarshal([]byte(strings.TrimSpace(rec.Body.String())), &events); err != nil || len(events) != 2 {
+			t.Errorf("Accept %q: expected a JSON array of 2 events, got %q", accept, rec.Body)
+		}
+	}
+}
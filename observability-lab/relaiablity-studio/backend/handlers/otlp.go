@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+// Trace sources selectable in config
+const (
+	TraceSourceTempo = "tempo"
+	TraceSourceOTLP  = "otlp"
+)
+
+// DefaultOTLPTraceWindow is how long pushed traces count towards incidents
+const DefaultOTLPTraceWindow = 15 * time.Minute
+
+// maxOTLPBody bounds a single export request
+const maxOTLPBody = 16 << 20
+
+// pushed holds the trace failures received over OTLP
+var pushed = newTraceBuffer(DefaultOTLPTraceWindow)
+
+// ReceiveTraces is an OTLP/HTTP trace receiver (POST /v1/traces). Exports in
+// protobuf or JSON encoding are analyzed on arrival; with TRACE_SOURCE=otlp
+// incidents read trace failures from them instead of searching Tempo.
+func ReceiveTraces(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxOTLPBody))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	var res analysis.TraceResult
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-protobuf":
+		res, err = analysis.AnalyzeOTLPProto(body)
+	case "application/json":
+		res, err = analysis.AnalyzeOTLPJSON(string(body))
+	default:
+		http.Error(w, "Content-Type must be application/x-protobuf or application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pushed.add(res, time.Now())
+
+	// An empty ExportTraceServiceResponse signals full success
+	w.Header().Set("Content-Type", mediaType)
+	if mediaType == "application/json" {
+		w.Write([]byte("{}"))
+	}
+}
+
+// traceBuffer keeps pushed trace results for a sliding window
+type traceBuffer struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	batches []traceBatch
+	// keptFrom is when the batches kept start: older ones have been dropped
+	keptFrom time.Time
+}
+
+type traceBatch struct {
+	at     time.Time
+	result analysis.TraceResult
+}
+
+// newTraceBuffer creates a buffer. window <= 0 uses DefaultOTLPTraceWindow.
+func newTraceBuffer(window time.Duration) *traceBuffer {
+	if window <= 0 {
+		window = DefaultOTLPTraceWindow
+	}
+	return &traceBuffer{window: window}
+}
+
+// add records a result received at now, dropping batches older than the
+// window. Clean batches are kept too: their traces count towards the total
+// the failure rate is taken over.
+func (b *traceBuffer) add(res analysis.TraceResult, now time.Time) {
+	if res.Total == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cutoff := now.Add(-b.window)
+	b.keptFrom = cutoff
+	kept := b.batches[:0]
+	for _, batch := range b.batches {
+		if batch.at.After(cutoff) {
+			kept = append(kept, batch)
+		}
+	}
+	b.batches = append(kept, traceBatch{at: now, result: res})
+}
+
+// between merges the results received from start to end. A window starting
+// before the batches kept is reported as truncated, along with what is left.
+func (b *traceBuffer) between(start, end time.Time) (analysis.TraceResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var results []analysis.TraceResult
+	for _, batch := range b.batches {
+		if !batch.at.Before(start) && !batch.at.After(end) {
+			results = append(results, batch.result)
+		}
+	}
+	var err error
+	if start.Before(b.keptFrom) {
+		err = fmt.Errorf("pushed traces are only kept for %s, so those before %s are missing from the window",
+			b.window, b.keptFrom.UTC().Format(time.RFC3339))
+	}
+	return analysis.MergeTraces(results...), err
+}
+
+// recent merges the results received within the window before now
+func (b *traceBuffer) recent(now time.Time) (analysis.TraceResult, error) {
+	return b.between(now.Add(-b.window), now)
+}
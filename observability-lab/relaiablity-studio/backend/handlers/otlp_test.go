@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+)
+
+func TestReceiveTraces(t *testing.T) {
+	Configure(config.Config{TraceSource: TraceSourceOTLP})
+	defer Configure(config.Config{})
+
+	export := `{"resourceSpans":[{
+		"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},
+		"scopeSpans":[{"spans":[
+			{"traceId":"5b8efff798038103d269b633813fc60c","spanId":"eee19b7ec3c1b174","name":"POST /pay","startTimeUnixNano":"1705284840000000000","status":{"code":2,"message":"card declined"}},
+			{"traceId":"5b8efff798038103d269b633813fc60d","spanId":"eee19b7ec3c1b175","name":"GET /cart","startTimeUnixNano":"1705284841000000000","status":{}}
+		]}]
+	}]}`
+
+	req := httptest.NewRequest("POST", "/v1/traces", strings.NewReader(export))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	ReceiveTraces(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d (%s)", rec.Code, rec.Body)
+	}
+
+	traces, err := upstreamSources{}.Traces(context.Background(), "checkout")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if traces.Failures != 1 || traces.FailuresFor("checkout") != 1 {
+		t.Errorf("Expected one failure attributed to checkout, got %+v", traces)
+	}
+	if len(traces.Events) != 1 || traces.Events[0].Time != "2024-01-15T02:14:00Z" {
+		t.Errorf("Expected a trace failure event, got %+v", traces.Events)
+	}
+
+	// A clean export adds to the traces the failure rate is taken over
+	clean := `{"resourceSpans":[{
+		"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},
+		"scopeSpans":[{"spans":[
+			{"traceId":"5b8efff798038103d269b633813fc60e","spanId":"eee19b7ec3c1b176","startTimeUnixNano":"1705284842000000000","status":{}},
+			{"traceId":"5b8efff798038103d269b633813fc60f","spanId":"eee19b7ec3c1b177","startTimeUnixNano":"1705284843000000000","status":{}}
+		]}]
+	}]}`
+	req = httptest.NewRequest("POST", "/v1/traces", strings.NewReader(clean))
+	req.Header.Set("Content-Type", "application/json")
+	ReceiveTraces(httptest.NewRecorder(), req)
+	if traces, _ = (upstreamSources{}).Traces(context.Background(), "checkout"); traces.Total != 4 || traces.FailureRate("checkout") != 25 {
+		t.Errorf("Expected 1 failure in 4 traces (25%%), got %d in %d", traces.Failures, traces.Total)
+	}
+
+	for _, tc := range []struct {
+		contentType string
+		body        string
+		status      int
+	}{
+		{"application/json", `{"resourceSpans":`, http.StatusBadRequest},
+		{"text/plain", export, http.StatusUnsupportedMediaType},
+	} {
+		req := httptest.NewRequest("POST", "/v1/traces", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", tc.contentType)
+		rec := httptest.NewRecorder()
+		ReceiveTraces(rec, req)
+		if rec.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.contentType, tc.status, rec.Code)
+		}
+	}
+}
+
+func TestTraceBufferReportsTruncatedWindow(t *testing.T) {
+	buf := newTraceBuffer(15 * time.Minute)
+	now := time.Date(2024, 1, 15, 3, 0, 0, 0, time.UTC)
+	buf.add(analysis.TraceResult{Total: 2, Failures: 1}, now.Add(-20*time.Minute))
+	buf.add(analysis.TraceResult{Total: 3}, now)
+
+	if res, err := buf.recent(now); err != nil || res.Total != 3 {
+		t.Errorf("Expected the recent window complete with 3 traces, got %d (%v)", res.Total, err)
+	}
+	res, err := buf.between(now.Add(-time.Hour), now)
+	if err == nil {
+		t.Error("Expected a window older than the buffer reported as truncated")
+	}
+	if res.Total != 3 {
+		t.Errorf("Expected the traces still kept, got %d", res.Total)
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+// queryValidation is the result of ValidateQuery
+type queryValidation struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"` // The upstream's parse error
+}
+
+// ValidateQuery checks a PromQL or LogQL expression by running it against the
+// upstream over a tiny range. An unreachable upstream is a 502, not an
+// invalid query.
+func ValidateQuery(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Type  string `json:"type"`
+		Query string `json:"query"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		http.Error(w, "query is required", http.StatusBadRequest)
+		return
+	}
+
+	var body string
+	var err error
+	switch req.Type {
+	case "promql":
+		body, err = services.ValidatePromQL(r.Context(), req.Query)
+	case "logql":
+		body, err = services.ValidateLogQL(r.Context(), req.Query)
+	default:
+		http.Error(w, `type must be "promql" or "logql"`, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(parseValidation(body))
+}
+
+// parseValidation reads a Prometheus/Loki API response: {"status":"success"}
+// is valid, {"status":"error","error":...} carries the parse error, and any
+// other body (Loki's plain-text errors) is the error itself.
+func parseValidation(body string) queryValidation {
+	var resp struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return queryValidation{Error: strings.TrimSpace(body)}
+	}
+	if resp.Status == "success" {
+		return queryValidation{Valid: true}
+	}
+	return queryValidation{Error: resp.Error}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+func TestValidateQuery(t *testing.T) {
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("query") == "up" {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"1:4: parse error: unexpected end of input"}`))
+	}))
+	defer prom.Close()
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") != "1" {
+			t.Errorf("Expected validation to ask Loki for one line, got %s", r.URL.RawQuery)
+		}
+		if strings.HasPrefix(r.URL.Query().Get("query"), "{") {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[]}}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("parse error at line 1, col 1: syntax error: unexpected IDENTIFIER\n"))
+	}))
+	defer loki.Close()
+
+	services.SetUpstreams(prom.URL, loki.URL, "")
+	defer services.SetUpstreams("", "", "")
+
+	testCases := []struct {
+		body   string
+		status int
+		valid  bool
+		errMsg string
+	}{
+		{`{"type":"promql","query":"up"}`, http.StatusOK, true, ""},
+		{`{"type":"promql","query":"up{"}`, http.StatusOK, false, "1:4: parse error: unexpected end of input"},
+		{`{"type":"logql","query":"{app=\"checkout\"}"}`, http.StatusOK, true, ""},
+		{`{"type":"logql","query":"app=checkout"}`, http.StatusOK, false, "parse error at line 1, col 1: syntax error: unexpected IDENTIFIER"},
+		{`{"type":"sql","query":"select 1"}`, http.StatusBadRequest, false, ""},
+		{`{"type":"promql"}`, http.StatusBadRequest, false, ""},
+	}
+
+	for _, tc := range testCases {
+		rec := httptest.NewRecorder()
+		ValidateQuery(rec, httptest.NewRequest("POST", "/api/query/validate", strings.NewReader(tc.body)))
+
+		if rec.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.body, tc.status, rec.Code)
+			continue
+		}
+		if tc.status != http.StatusOK {
+			continue
+		}
+		var got queryValidation
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if got.Valid != tc.valid || got.Error != tc.errMsg {
+			t.Errorf("%s: expected valid=%v error=%q, got %+v", tc.body, tc.valid, tc.errMsg, got)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+// latest caches each service's most recent live incident for the incident
+// route's cache max-age, so reads clients could have cached don't recompute it
+var latest = newIncidentCache(0)
+
+// RefreshIncident evicts a service's cached incident and rebuilds it
+// synchronously (POST /api/incident/{service}/refresh), for operators who
+// just fixed something and don't want to wait for the cache to expire. Like
+// GetServiceIncident it accepts ?tz= and ?fields=.
+func RefreshIncident(w http.ResponseWriter, r *http.Request) {
+	service := mux.Vars(r)["service"]
+
+	fields, err := parseFields[correlation.Incident](r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	loc, err := timelineLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	latest.evict(service)
+	if err := checkLogVolume(r.Context(), service, correlation.TimeRange{}); err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	incident := buildIncident(service, upstreamSources{templates: cfg.QueryTemplates})
+	latest.put(service, incident, time.Now())
+	incident.Timeline = correlation.InLocation(incident.Timeline, loc)
+	incident = withDashboards(incident, correlation.TimeRange{})
+
+	respondFields(w, incident.WithAge(time.Now()), fields)
+}
+
+// incidentCache holds one incident per service for ttl. A ttl <= 0 disables it.
+type incidentCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedIncident
+}
+
+type cachedIncident struct {
+	incident correlation.Incident
+	builtAt  time.Time
+}
+
+func newIncidentCache(ttl time.Duration) *incidentCache {
+	return &incidentCache{ttl: ttl, entries: make(map[string]cachedIncident)}
+}
+
+// get returns the service's incident if it was built within ttl of now
+func (c *incidentCache) get(service string, now time.Time) (correlation.Incident, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[service]
+	if !ok || now.Sub(entry.builtAt) >= c.ttl {
+		return correlation.Incident{}, false
+	}
+	return entry.incident, true
+}
+
+func (c *incidentCache) put(service string, incident correlation.Incident, now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	c.entries[service] = cachedIncident{incident: incident, builtAt: now}
+	c.mu.Unlock()
+}
+
+func (c *incidentCache) evict(service string) {
+	c.mu.Lock()
+	delete(c.entries, service)
+	c.mu.Unlock()
+}
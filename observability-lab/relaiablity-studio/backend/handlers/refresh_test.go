@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+func TestRefreshIncidentReplacesCachedIncident(t *testing.T) {
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1705284840,"0"]}]}}`))
+	}))
+	defer prom.Close()
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[]}}`))
+	}))
+	defer loki.Close()
+	tempo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"traces":[]}`))
+	}))
+	defer tempo.Close()
+	services.SetUpstreams(prom.URL, loki.URL, tempo.URL)
+	defer services.SetUpstreams("", "", "")
+
+	Configure(config.Config{CacheMaxAge: time.Minute})
+	defer Configure(config.Config{})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/incident/{service}", GetServiceIncident).Methods("GET")
+	router.HandleFunc("/api/incident/{service}/refresh", RefreshIncident).Methods("POST")
+	serve := func(method, path string) correlation.Incident {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s %s: expected status 200, got %d: %s", method, path, rec.Code, rec.Body)
+		}
+		var incident correlation.Incident
+		if err := json.NewDecoder(rec.Body).Decode(&incident); err != nil {
+			t.Fatalf("failed to decode incident: %v", err)
+		}
+		return incident
+	}
+
+	// The service was critical when last built; it has since been fixed
+	stale := correlation.Incident{ID: "checkout-stale", Service: "checkout", Severity: "critical"}
+	latest.put("checkout", stale, time.Now())
+
+	if got := serve("GET", "/api/incident/checkout"); got.ID != stale.ID {
+		t.Fatalf("Expected the cached incident before refresh, got %s", got.ID)
+	}
+
+	fresh := serve("POST", "/api/incident/checkout/refresh")
+	if fresh.ID == stale.ID || fresh.Severity == "critical" {
+		t.Errorf("Expected a freshly built incident, got %s (%s)", fresh.ID, fresh.Severity)
+	}
+	if got := serve("GET", "/api/incident/checkout"); got.ID != fresh.ID {
+		t.Errorf("Expected reads to return the refreshed incident %s, got %s", fresh.ID, got.ID)
+	}
+}
+
+func TestIncidentDataAge(t *testing.T) {
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1705284840,"0"]}]}}`))
+	}))
+	defer prom.Close()
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[]}}`))
+	}))
+	defer loki.Close()
+	tempo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"traces":[]}`))
+	}))
+	defer tempo.Close()
+	services.SetUpstreams(prom.URL, loki.URL, tempo.URL)
+	defer services.SetUpstreams("", "", "")
+
+	Configure(config.Config{CacheMaxAge: time.Minute})
+	defer Configure(config.Config{})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/incident/{service}", GetServiceIncident).Methods("GET")
+	get := func(path string) correlation.Incident {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected status 200, got %d: %s", path, rec.Code, rec.Body)
+		}
+		var incident correlation.Incident
+		if err := json.NewDecoder(rec.Body).Decode(&incident); err != nil {
+			t.Fatalf("failed to decode incident: %v", err)
+		}
+		return incident
+	}
+
+	fresh := get("/api/incident/payments")
+	if fresh.ComputedAt.IsZero() || fresh.DataAgeSeconds > 1 {
+		t.Errorf("Expected a fresh incident to be about 0s old, got %vs (computed at %s)", fresh.DataAgeSeconds, fresh.ComputedAt)
+	}
+
+	// Built 30s ago and still within the cache max-age
+	computed := time.Now().Add(-30 * time.Second)
+	latest.put("checkout", correlation.Incident{ID: "checkout-cached", Service: "checkout", ComputedAt: computed}, computed)
+	cached := get("/api/incident/checkout")
+	if cached.ID != "checkout-cached" {
+		t.Fatalf("Expected the cached incident, got %s", cached.ID)
+	}
+	if cached.DataAgeSeconds < 30 || cached.DataAgeSeconds > 31 {
+		t.Errorf("Expected the cached incident to be about 30s old, got %vs", cached.DataAgeSeconds)
+	}
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+)
+
+// NewServer builds the API server with the configured timeouts, so a client
+// trickling its headers (slowloris) or a stuck connection can't hold a
+// goroutine forever. Their defaults are set by config.Load; 0 disables a
+// timeout. The incident stream outlives WriteTimeout; see stream.Broker.
+func NewServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+		ReadTimeout:       cfg.ServerReadTimeout,
+		WriteTimeout:      cfg.ServerWriteTimeout,
+		IdleTimeout:       cfg.ServerIdleTimeout,
+	}
+}
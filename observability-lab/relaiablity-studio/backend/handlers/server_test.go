@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/stream"
+)
+
+// serve runs NewServer on a local port until the test ends
+func serve(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	srv := NewServer(ln.Addr().String(), handler)
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+	return ln.Addr().String()
+}
+
+func TestNewServerZeroTimeoutsDisabled(t *testing.T) {
+	Configure(config.Config{ServerReadTimeout: time.Second})
+	defer Configure(config.Config{})
+
+	srv := NewServer(":0", http.NotFoundHandler())
+	if srv.ReadTimeout != time.Second {
+		t.Errorf("Expected ReadTimeout 1s, got %s", srv.ReadTimeout)
+	}
+	if srv.ReadHeaderTimeout != 0 || srv.WriteTimeout != 0 || srv.IdleTimeout != 0 {
+		t.Errorf("Expected unset timeouts to stay 0 (disabled), got %s, %s, %s", srv.ReadHeaderTimeout, srv.WriteTimeout, srv.IdleTimeout)
+	}
+	if incidents.WriteTimeout != 0 {
+		t.Errorf("Expected no stream write timeout, got %s", incidents.WriteTimeout)
+	}
+}
+
+func TestServerDropsSlowHeaders(t *testing.T) {
+	Configure(config.Config{ServerReadHeaderTimeout: 100 * time.Millisecond})
+	defer Configure(config.Config{})
+
+	addr := serve(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	// A client sending its headers in full is served
+	resp, err := http.Get("http://" + addr + "/health")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	// One that never finishes them is disconnected
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("GET /health HTTP/1.1\r\nHost: localhost\r\n")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	start := time.Now()
+	conn.SetReadDeadline(start.Add(2 * time.Second))
+	_, err = io.ReadAll(conn)
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		t.Fatal("Expected the server to drop the slow client, but the connection stayed open")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the slow client dropped after about 100ms, took %s", elapsed)
+	}
+}
+
+func TestIncidentStreamOutlivesServerTimeouts(t *testing.T) {
+	Configure(config.Config{ServerReadTimeout: 100 * time.Millisecond, ServerWriteTimeout: 100 * time.Millisecond})
+	defer Configure(config.Config{})
+
+	addr := serve(t, http.HandlerFunc(StreamIncidents))
+	resp, err := http.Get("http://" + addr + "/api/incidents/stream")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Past both timeouts, the stream still delivers updates
+	time.Sleep(300 * time.Millisecond)
+	incidents.Publish(stream.Event{Name: "incident", Data: map[string]string{"service": "checkout"}})
+
+	lines := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		lines <- line
+	}()
+	select {
+	case line := <-lines:
+		if !strings.HasPrefix(line, "event: incident") {
+			t.Errorf("Expected an incident event, got %q", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the incident event, got nothing")
+	}
+}
@@ -2,17 +2,61 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
 	"github.com/sarikasharma2428-web/reliability-studio/services"
 )
 
+// GetSLOStatus returns the current value of an SLI. ?query= may name a
+// Prometheus recording rule (e.g. job:http_error_rate:ratio) to read a
+// precomputed SLI instead of the default request rate. A failed or errored
+// Prometheus query is a 502, and no_data tells an unmatched rule from a zero.
 func GetSLOStatus(w http.ResponseWriter, r *http.Request) {
-	query := `rate(http_requests_total[1m])`
-	data := services.QueryMetrics(query)
+	q := r.URL.Query().Get("query")
+	if q == "" {
+		data, err := services.QueryMetrics(r.Context(), `rate(http_requests_total[1m])`)
+		if err == nil {
+			_, err = analysis.AnalyzeMetrics(data)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
 
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", ETagFor(data))
+		json.NewEncoder(w).Encode(map[string]string{
+			"raw": data,
+		})
+		return
+	}
+
+	if !services.IsRecordingRule(q) {
+		http.Error(w, fmt.Sprintf("query %q is not a recording-rule name", q), http.StatusBadRequest)
+		return
+	}
+	data, err := services.QueryMetrics(r.Context(), q)
+	var res analysis.MetricResult
+	if err == nil {
+		res, err = analysis.AnalyzeMetrics(data)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	// computed_at changes on every request; the result is what matters
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"raw": data,
+	w.Header().Set("ETag", ETagFor(q, data))
+	json.NewEncoder(w).Encode(map[string]any{
+		"query":            q,
+		"value":            res.Latency,
+		"no_data":          res.NoData,
+		"raw":              data,
+		"computed_at":      time.Now().UTC(),
+		"data_age_seconds": 0,
 	})
 }
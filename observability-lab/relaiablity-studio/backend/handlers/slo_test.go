@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+func TestGetSLOStatusRecordingRule(t *testing.T) {
+	var gotPath, gotQuery string
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery = r.URL.Path, r.URL.Query().Get("query")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"__name__":"job:http_error_rate:ratio","job":"checkout"},"value":[1705284840,"0.0042"]}
+		]}}`))
+	}))
+	defer prom.Close()
+
+	services.SetUpstreams(prom.URL, "", "")
+	defer services.SetUpstreams("", "", "")
+
+	rec := httptest.NewRecorder()
+	GetSLOStatus(rec, httptest.NewRequest("GET", "/api/slo/status?query=job:http_error_rate:ratio", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if gotPath != "/api/v1/query" || gotQuery != "job:http_error_rate:ratio" {
+		t.Errorf("Expected instant query for the rule, got %s?query=%s", gotPath, gotQuery)
+	}
+
+	var body struct {
+		Value float64 `json:"value"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Value != 0.0042 {
+		t.Errorf("Expected vector value 0.0042, got %v", body.Value)
+	}
+}
+
+func TestGetSLOStatusRejectsExpressions(t *testing.T) {
+	rec := httptest.NewRecorder()
+	GetSLOStatus(rec, httptest.NewRequest("GET", "/api/slo/status?query=sum(up)", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a raw expression, got %d", rec.Code)
+	}
+}
+
+func TestGetSLOStatusUpstreamErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{"error body", `{"status":"error","errorType":"bad_data","error":"unknown metric"}`},
+		{"not json", `upstream connect error`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tc.body))
+			}))
+			defer prom.Close()
+			services.SetUpstreams(prom.URL, "", "")
+			defer services.SetUpstreams("", "", "")
+
+			for _, target := range []string{"/api/slo/status?query=job:http_error_rate:ratio", "/api/slo/status"} {
+				rec := httptest.NewRecorder()
+				GetSLOStatus(rec, httptest.NewRequest("GET", target, nil))
+				if rec.Code != http.StatusBadGateway {
+					t.Errorf("Expected 502 for %s, got %d: %s", target, rec.Code, rec.Body.String())
+				}
+			}
+		})
+	}
+
+	services.SetUpstreams("http://127.0.0.1:1", "", "")
+	defer services.SetUpstreams("", "", "")
+	rec := httptest.NewRecorder()
+	GetSLOStatus(rec, httptest.NewRequest("GET", "/api/slo/status?query=job:http_error_rate:ratio", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected 502 with Prometheus down, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
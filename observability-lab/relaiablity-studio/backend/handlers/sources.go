@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+// upstreamSources fetches each signal from the live Prometheus, Loki, Tempo and k8s.
+// When window is set every source is bounded to it, with metrics sampled every
+// step; otherwise sources report "now".
+type upstreamSources struct {
+	templates config.QueryTemplates
+	window    correlation.TimeRange
+	step      time.Duration
+}
+
+func (s upstreamSources) Logs(ctx context.Context, service string) (analysis.LogResult, error) {
+	queries, err := s.templates.For(service)
+	if err != nil {
+		return analysis.LogResult{}, err
+	}
+	var body string
+	if s.windowed() {
+		if cfg.LogStreamLimit > 0 {
+			return s.streamLogs(ctx, service, queries.Log)
+		}
+		body, err = services.QueryLogsRange(ctx, queries.Log, s.window.Start, s.window.End)
+	} else {
+		body, err = services.QueryLogs(ctx, queries.Log)
+	}
+	if err != nil {
+		return analysis.LogResult{}, err
+	}
+	return analysis.AnalyzeLogs(service, body)
+}
+
+// streamLogs analyzes a windowed log query as Loki sends it, for windows
+// with more lines than fit comfortably in memory
+func (s upstreamSources) streamLogs(ctx context.Context, service, query string) (analysis.LogResult, error) {
+	body, err := services.StreamLogsRange(ctx, query, s.window.Start, s.window.End, cfg.LogStreamLimit)
+	if err != nil {
+		return analysis.LogResult{}, err
+	}
+	defer body.Close()
+	return analysis.AnalyzeLogStream(service, body)
+}
+
+func (s upstreamSources) Metrics(ctx context.Context, service string) (analysis.MetricResult, error) {
+	queries, err := s.templates.For(service)
+	if err != nil {
+		return analysis.MetricResult{}, err
+	}
+	errorRate, err := s.metrics(ctx, queries.Error, analysis.AnalyzeErrorRate)
+	if err != nil {
+		return analysis.MetricResult{}, fmt.Errorf("error rate: %w", err)
+	}
+	latency, err := s.metrics(ctx, queries.Latency, analysis.AnalyzeMetrics)
+	if err != nil {
+		return analysis.MetricResult{}, fmt.Errorf("latency: %w", err)
+	}
+	res := analysis.MetricResult{
+		ErrorRate: errorRate.ErrorRate,
+		Latency:   latency.Latency,
+		Series:    latency.Series,
+		NoData:    errorRate.NoData && latency.NoData,
+	}
+	// Exemplars only add trace links to latency spikes, which need the
+	// windowed series, and a Prometheus without exemplar storage doesn't fail
+	// the metrics source
+	if s.windowed() {
+		exemplars, err := services.QueryExemplars(ctx, queries.Latency, s.window.Start, s.window.End)
+		if err == nil {
+			res.Exemplars, _ = analysis.AnalyzeExemplars(exemplars)
+		}
+	}
+	// A failed breakdown is reported without losing the service-level rates
+	if queries.ErrorBreakdown != "" {
+		body, err := s.queryMetrics(ctx, queries.ErrorBreakdown)
+		if err == nil {
+			res.Outliers, err = analysis.AnalyzeInstanceErrors(body, queries.BreakdownBy)
+		}
+		if err != nil {
+			return res, fmt.Errorf("error rate by %s: %w", queries.BreakdownBy, err)
+		}
+	}
+	return res, nil
+}
+
+// metrics runs a metric query and analyzes it with analyze
+func (s upstreamSources) metrics(ctx context.Context, query string, analyze func(string) (analysis.MetricResult, error)) (analysis.MetricResult, error) {
+	body, err := s.queryMetrics(ctx, query)
+	if err != nil {
+		return analysis.MetricResult{}, err
+	}
+	return analyze(body)
+}
+
+func (s upstreamSources) Traces(ctx context.Context, service string) (analysis.TraceResult, error) {
+	if cfg.TraceSource == TraceSourceOTLP {
+		if s.windowed() {
+			return pushed.between(s.window.Start, s.window.End)
+		}
+		return pushed.recent(time.Now())
+	}
+	queries, err := s.templates.For(service)
+	if err != nil {
+		return analysis.TraceResult{}, err
+	}
+	if queries.Trace != "" {
+		return s.searchTraces(ctx, queries)
+	}
+	var body string
+	if s.windowed() {
+		body, err = services.GetTracesRange(ctx, s.window.Start, s.window.End)
+	} else {
+		body, err = services.GetTraces(ctx)
+	}
+	if err != nil {
+		return analysis.TraceResult{}, err
+	}
+	return analysis.AnalyzeTraces(body)
+}
+
+// searchTraces runs the service's TraceQL query. Its Total is only the
+// traces the query matched, which for a query selecting failures is every
+// one of them, so the denominator comes from the total query instead, and
+// is 0 (leaving the failure rate ungraded) without one.
+func (s upstreamSources) searchTraces(ctx context.Context, queries config.Queries) (analysis.TraceResult, error) {
+	res, err := s.traceQL(ctx, queries.Trace)
+	if err != nil {
+		return res, err
+	}
+	res.Total, res.ServiceTotals = 0, nil
+	if queries.TraceTotal != "" {
+		total, err := s.traceQL(ctx, queries.TraceTotal)
+		if err != nil {
+			return res, fmt.Errorf("trace total: %w", err)
+		}
+		res.Total = max(total.Total, res.Failures)
+	}
+	return res, nil
+}
+
+// traceQL runs and analyzes a TraceQL search over the window
+func (s upstreamSources) traceQL(ctx context.Context, query string) (analysis.TraceResult, error) {
+	body, err := services.SearchTraceQL(ctx, query, s.window.Start, s.window.End)
+	if err != nil {
+		return analysis.TraceResult{}, err
+	}
+	return analysis.AnalyzeTraces(body)
+}
+
+func (s upstreamSources) K8s(ctx context.Context, service string) (analysis.K8sResult, error) {
+	namespace, err := services.NamespaceFor(service)
+	if err != nil {
+		return analysis.K8sResult{}, err
+	}
+
+	// A cluster that can't be read is reported, but doesn't hide the others
+	var results []analysis.K8sResult
+	var errs []error
+	for _, state := range services.GetClusters(ctx, namespace) {
+		err := state.Err
+		var k8s analysis.K8sResult
+		if err == nil {
+			k8s, err = analysis.AnalyzeK8sCluster(state.Cluster, service, state.Raw, s.window.End)
+		}
+		if err != nil {
+			if state.Cluster != "" {
+				err = fmt.Errorf("cluster %s: %w", state.Cluster, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		results = append(results, k8s)
+	}
+
+	k8s := analysis.MergeK8s(results...)
+	if s.windowed() {
+		k8s = k8sInWindow(k8s, s.window)
+	}
+	return k8s, errors.Join(errs...)
+}
+
+func (s upstreamSources) windowed() bool {
+	return !s.window.Start.IsZero()
+}
+
+func (s upstreamSources) queryMetrics(ctx context.Context, query string) (string, error) {
+	if !s.windowed() {
+		return services.QueryMetrics(ctx, query)
+	}
+	if services.IsRecordingRule(query) {
+		return services.QueryMetricsAt(ctx, query, s.window.End)
+	}
+	return services.QueryMetricsRange(ctx, query, s.window.Start, s.window.End, s.step)
+}
+
+// k8sInWindow keeps only pod failures and rollouts that happened inside the
+// window. kubectl only reports current state, so it can't be filtered upstream.
+func k8sInWindow(k8s analysis.K8sResult, window correlation.TimeRange) analysis.K8sResult {
+	var filtered analysis.K8sResult
+	for _, e := range k8s.Events {
+		t, err := time.Parse(time.RFC3339, e.Time)
+		if err != nil || t.Before(window.Start) || t.After(window.End) {
+			continue
+		}
+		filtered.Events = append(filtered.Events, e)
+		switch e.Kind {
+		case analysis.K8sPodEvent:
+			filtered.BadPods++
+		case analysis.K8sNotReadyEvent:
+			filtered.DegradedPods++
+		}
+	}
+	for _, r := range k8s.Rollouts {
+		if !r.StartedAt.Before(window.Start) && !r.StartedAt.After(window.End) {
+			filtered.Rollouts = append(filtered.Rollouts, r)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+// fakeKubectl puts a kubectl on PATH that answers for two clusters: us-east
+// by context and eu-west by kubeconfig, each with one failed checkout pod
+func fakeKubectl(t *testing.T) {
+	t.Helper()
+
+	bin := t.TempDir()
+	script := `#!/bin/sh
+case "$*" in
+*"--context us-east"*) start=2024-01-15T02:10:00Z ;;
+*"--kubeconfig /etc/kube/eu-west.yaml"*) start=2024-01-15T02:12:00Z ;;
+*) exit 1 ;;
+esac
+cat <<JSON
+{"items":[{"kind":"Pod","metadata":{"name":"checkout-1"},"status":{"phase":"Failed","startTime":"$start"}}]}
+JSON
+`
+	if err := os.WriteFile(filepath.Join(bin, "kubectl"), []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake kubectl: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestUpstreamSourcesK8sMultiCluster(t *testing.T) {
+	fakeKubectl(t)
+	services.SetClusters("us-east, eu-west=/etc/kube/eu-west.yaml")
+	defer services.SetClusters("")
+
+	k8s, err := upstreamSources{}.K8s(context.Background(), "checkout")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if k8s.BadPods != 2 {
+		t.Errorf("Expected failed pods merged across clusters, got %d", k8s.BadPods)
+	}
+
+	clusters := map[string]string{}
+	for _, e := range k8s.Events {
+		clusters[e.Cluster] = e.Time
+	}
+	if clusters["us-east"] != "2024-01-15T02:10:00Z" || clusters["eu-west"] != "2024-01-15T02:12:00Z" {
+		t.Errorf("Expected one event per cluster, got %+v", k8s.Events)
+	}
+}
+
+func TestUpstreamSourcesK8sUnreachableCluster(t *testing.T) {
+	fakeKubectl(t)
+	services.SetClusters("us-east,ap-south")
+	defer services.SetClusters("")
+
+	k8s, err := upstreamSources{}.K8s(context.Background(), "checkout")
+	if err == nil {
+		t.Error("Expected an error for the unreachable cluster")
+	}
+	if k8s.BadPods != 1 || len(k8s.Events) != 1 || k8s.Events[0].Cluster != "us-east" {
+		t.Errorf("Expected the reachable cluster still reported, got %+v", k8s)
+	}
+}
+
+func TestUpstreamSourcesK8sNamespace(t *testing.T) {
+	// The fake kubectl records its arguments so the scope can be checked
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	script := `#!/bin/sh
+echo "$*" > ` + argsFile + `
+echo '{"items":[]}'
+`
+	if err := os.WriteFile(filepath.Join(bin, "kubectl"), []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake kubectl: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	services.SetNamespaces(config.K8sNamespaces{
+		Default:  "shop",
+		Allowed:  []string{"shop", "billing"},
+		Services: map[string]string{"payments": "billing", "search": "kube-system"},
+	})
+	defer services.SetNamespaces(config.K8sNamespaces{})
+
+	tests := []struct {
+		service string
+		want    string
+	}{
+		{"checkout", "-n shop"},
+		{"payments", "-n billing"},
+	}
+	for _, tt := range tests {
+		if _, err := (upstreamSources{}).K8s(context.Background(), tt.service); err != nil {
+			t.Fatalf("Unexpected error for %s: %v", tt.service, err)
+		}
+		args, _ := os.ReadFile(argsFile)
+		if !strings.Contains(string(args), tt.want) || strings.Contains(string(args), "-A") {
+			t.Errorf("Expected %s scoped with %q, got kubectl %s", tt.service, tt.want, args)
+		}
+	}
+
+	os.Remove(argsFile)
+	if _, err := (upstreamSources{}).K8s(context.Background(), "search"); err == nil {
+		t.Error("Expected a namespace outside the allowlist to be refused")
+	}
+	if _, err := os.Stat(argsFile); err == nil {
+		t.Error("Expected kubectl not to run for a refused namespace")
+	}
+}
+
+func TestUpstreamSourcesTraceQueryRate(t *testing.T) {
+	tempo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Query().Get("q"), "status = error") {
+			w.Write([]byte(`{"traces":[{"traceID":"a","status":"error","rootServiceName":"checkout"}]}`))
+			return
+		}
+		w.Write([]byte(`{"traces":[{"traceID":"a","status":"error"},{"traceID":"b","status":"ok"},{"traceID":"c","status":"ok"},{"traceID":"d","status":"ok"}]}`))
+	}))
+	defer tempo.Close()
+	services.SetUpstreams("", "", tempo.URL)
+	defer services.SetUpstreams("", "", "")
+
+	failed := config.QueryTemplate{TraceQuery: `{ resource.service.name = "{{.Service}}" && status = error }`}
+	withTotal := failed
+	withTotal.TraceTotalQuery = `{ resource.service.name = "{{.Service}}" }`
+
+	testCases := []struct {
+		name     string
+		template config.QueryTemplate
+		total    int
+		rate     float64
+	}{
+		{"failures only", failed, 0, 0},
+		{"with total query", withTotal, 4, 25},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sources := upstreamSources{templates: config.QueryTemplates{"checkout": tc.template}}
+			traces, err := sources.Traces(context.Background(), "checkout")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if traces.Failures != 1 || traces.Total != tc.total || traces.FailureRate("checkout") != tc.rate {
+				t.Errorf("Expected 1 failure of %d traces (%v%%), got %d of %d (%v%%)",
+					tc.total, tc.rate, traces.Failures, traces.Total, traces.FailureRate("checkout"))
+			}
+		})
+	}
+}
+
+func TestUpstreamSourcesUnreachable(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	services.SetUpstreams(down.URL, down.URL, down.URL)
+	defer services.SetUpstreams("", "", "")
+
+	src := upstreamSources{}
+	_, logsErr := src.Logs(context.Background(), "checkout")
+	_, metricsErr := src.Metrics(context.Background(), "checkout")
+	_, tracesErr := src.Traces(context.Background(), "checkout")
+	for name, err := range map[string]error{"logs": logsErr, "metrics": metricsErr, "traces": tracesErr} {
+		var apiErr *analysis.APIError
+		if err == nil || errors.As(err, &apiErr) || errors.Is(err, analysis.ErrInvalidResponse) {
+			t.Errorf("Expected a transport error for unreachable %s, got %v", name, err)
+		}
+	}
+}
+
+func TestUpstreamSourcesLatencySeriesWindowedOnly(t *testing.T) {
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/query_range":
+			w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{},"values":[[1705284600,"0.2"],[1705284615,"1.5"]]}]}}`))
+		case "/api/v1/query":
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{},"value":[1705284615,"1.5"]}]}}`))
+		default:
+			w.Write([]byte(`{"status":"success","data":[]}`))
+		}
+	}))
+	defer prom.Close()
+	services.SetUpstreams(prom.URL, "", "")
+	defer services.SetUpstreams("", "", "")
+
+	live, err := upstreamSources{}.Metrics(context.Background(), "checkout")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(live.Series) != 0 || live.Latency != 1.5 {
+		t.Errorf("Expected a live instant latency of 1.5 with no series, got %v and %d points", live.Latency, len(live.Series))
+	}
+
+	window := correlation.TimeRange{Start: time.Unix(1705284600, 0), End: time.Unix(1705284615, 0)}
+	windowed, err := upstreamSources{window: window, step: 15 * time.Second}.Metrics(context.Background(), "checkout")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(windowed.Series) != 2 {
+		t.Errorf("Expected the windowed latency series, got %d points", len(windowed.Series))
+	}
+}
+
+func TestUpstreamSourcesExemplarsWindowedOnly(t *testing.T) {
+	exemplarQueries := 0
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/query_exemplars" {
+			exemplarQueries++
+			w.Write([]byte(`{"status":"success","data":[]}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer prom.Close()
+	services.SetUpstreams(prom.URL, "", "")
+	defer services.SetUpstreams("", "", "")
+
+	upstreamSources{}.Metrics(context.Background(), "checkout")
+	if exemplarQueries != 0 {
+		t.Errorf("Expected no exemplar query for a live build, got %d", exemplarQueries)
+	}
+	window := correlation.TimeRange{Start: time.Unix(1705284600, 0), End: time.Unix(1705284615, 0)}
+	upstreamSources{window: window, step: 15 * time.Second}.Metrics(context.Background(), "checkout")
+	if exemplarQueries != 1 {
+		t.Errorf("Expected one exemplar query for a windowed build, got %d", exemplarQueries)
+	}
+}
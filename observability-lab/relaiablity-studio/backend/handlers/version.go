@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+
+	"github.com/sarikasharma2428-web/reliability-studio/version"
+)
+
+// GetVersion reports which build is running
+func GetVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"version":    version.Version,
+		"commit":     version.Commit,
+		"build_time": version.BuildTime,
+		"go_version": runtime.Version(),
+	})
+}
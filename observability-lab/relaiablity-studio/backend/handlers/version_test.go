@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestGetVersionDefaults(t *testing.T) {
+	rec := httptest.NewRecorder()
+	GetVersion(rec, httptest.NewRequest("GET", "/api/version", nil))
+
+	var body map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	for _, field := range []string{"version", "commit", "build_time"} {
+		if body[field] != "dev" {
+			t.Errorf("Expected %s to default to \"dev\", got %q", field, body[field])
+		}
+	}
+	if body["go_version"] != runtime.Version() {
+		t.Errorf("Expected go_version %s, got %q", runtime.Version(), body["go_version"])
+	}
+}
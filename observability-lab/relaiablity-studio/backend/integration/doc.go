@@ -0,0 +1,6 @@
+// Package integration drives the live-correlation API end to end against mock
+// Prometheus, Loki, Tempo and kubectl that return realistic payloads from
+// testdata/. The tests are build-tagged so they stay out of the default run:
+//
+//	go test -tags integration ./integration/
+package integration
@@ -0,0 +1,135 @@
+//go:build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+	"github.com/sarikasharma2428-web/reliability-studio/handlers"
+	"github.com/sarikasharma2428-web/reliability-studio/services"
+)
+
+// fixture reads a recorded upstream payload from testdata/
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+// startUpstreams runs mock Prometheus, Loki and Tempo servers and a fake
+// kubectl on PATH, pointing the services package at them
+func startUpstreams(t *testing.T) {
+	t.Helper()
+
+	errorRate, latency := fixture(t, "prometheus_error_rate.json"), fixture(t, "prometheus_latency.json")
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/v1/query") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.URL.Query().Get("query"), "histogram_quantile") {
+			w.Write(latency)
+			return
+		}
+		w.Write(errorRate)
+	}))
+	t.Cleanup(prom.Close)
+
+	logs := fixture(t, "loki_query.json")
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/loki/api/v1/query") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(logs)
+	}))
+	t.Cleanup(loki.Close)
+
+	traces := fixture(t, "tempo_search.json")
+	tempo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/search" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(traces)
+	}))
+	t.Cleanup(tempo.Close)
+
+	services.SetUpstreams(prom.URL, loki.URL, tempo.URL)
+	t.Cleanup(func() { services.SetUpstreams("", "", "") })
+
+	bin := t.TempDir()
+	pods, err := filepath.Abs(filepath.Join("testdata", "kubectl_pods.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\ncat " + pods + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "kubectl"), []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake kubectl: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestServiceIncidentEndToEnd(t *testing.T) {
+	startUpstreams(t)
+	handlers.Configure(config.Config{Timezone: "UTC"})
+	t.Cleanup(func() { handlers.Configure(config.Config{}) })
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/incident/{service}", handlers.GetServiceIncident).Methods("GET")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/incident/checkout", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var incident correlation.Incident
+	if err := json.NewDecoder(rec.Body).Decode(&incident); err != nil {
+		t.Fatalf("failed to decode incident: %v", err)
+	}
+
+	if len(incident.SourceErrors) != 0 {
+		t.Fatalf("Expected every source to parse, got errors %v", incident.SourceErrors)
+	}
+	if incident.Severity != "critical" {
+		t.Errorf("Expected severity critical, got %s", incident.Severity)
+	}
+	if incident.Impact.ErrorRate != 4.2 || incident.Impact.BadPods != 1 || !incident.Impact.SLOAffected {
+		t.Errorf("Expected 4.2%% error rate and 1 failed pod, got %+v", incident.Impact)
+	}
+	// The failed trace and pod land within a minute of the first error log
+	if want := `level=error msg="DB connection timeout" db=orders-primary (confirmed by traces, kubernetes)`; incident.RootCause != want {
+		t.Errorf("Expected root cause %q, got %q", want, incident.RootCause)
+	}
+
+	// 2 error log lines, 1 failed trace, 1 failed pod
+	sources := map[string]int{}
+	for _, e := range incident.Timeline {
+		sources[e.Source]++
+	}
+	if sources["logs"] != 2 || sources["traces"] != 1 || sources["kubernetes"] != 1 {
+		t.Errorf("Expected timeline of 2 logs, 1 trace and 1 pod event, got %v", sources)
+	}
+	for _, e := range incident.Timeline {
+		if e.Source == "kubernetes" && e.Time != "2024-01-15T02:13:40Z" {
+			t.Errorf("Expected pod failure at 2024-01-15T02:13:40Z, got %s", e.Time)
+		}
+	}
+}
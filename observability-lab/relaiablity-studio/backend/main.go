@@ -18,11 +18,17 @@ import (
 	"github.com/rs/cors"
 	_ "net/http/pprof"
 
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
 	"github.com/sarikasharma2428-web/reliability-studio/clients"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
 	"github.com/sarikasharma2428-web/reliability-studio/correlation"
 	"github.com/sarikasharma2428-web/reliability-studio/database"
+	"github.com/sarikasharma2428-web/reliability-studio/handlers"
+	"github.com/sarikasharma2428-web/reliability-studio/metrics"
 	"github.com/sarikasharma2428-web/reliability-studio/middleware"
+	"github.com/sarikasharma2428-web/reliability-studio/notify"
 	"github.com/sarikasharma2428-web/reliability-studio/services"
+	"github.com/sarikasharma2428-web/reliability-studio/utils"
 )
 
 type Server struct {
@@ -37,11 +43,13 @@ type Server struct {
 
 func main() {
 	log.Println("🚀 Starting Reliability Studio Backend...")
+	middleware.LoadJWTSecret()
 
 	// Load configuration
 	dbConfig := database.LoadConfigFromEnv()
-	promURL := getEnv("PROMETHEUS_URL", "http://prometheus:9090")
-	lokiURL := getEnv("LOKI_URL", "http://loki:3100")
+	cfg := config.Load()
+	// Each URL may list comma-separated replicas; the DB-backed clients use the first
+	promURL, lokiURL, tempoURL := cfg.PrometheusURL, cfg.LokiURL, cfg.TempoURL
 
 	// Initialize database
 	log.Println("Connecting to database...")
@@ -63,8 +71,10 @@ func main() {
 
 	// Initialize clients
 	log.Println("🔌 Initializing clients...")
-	promClient := clients.NewPrometheusClient(promURL)
-	lokiClient := clients.NewLokiClient(lokiURL)
+	promClient := clients.NewPrometheusClient(strings.Split(promURL, ",")[0])
+	lokiClient := clients.NewLokiClient(strings.Split(lokiURL, ",")[0])
+	promClient.Templates = cfg.QueryTemplates
+	lokiClient.Templates = cfg.QueryTemplates
 
 	// Initialize K8s client - FIXED: Handle typed-nil issue for interfaces
 	var k8sInterface correlation.KubernetesClient
@@ -93,10 +103,82 @@ func main() {
 		correlationEngine: correlationEngine,
 	}
 
+	handlers.Configure(cfg)
+	services.SetUpstreamConcurrency(cfg.UpstreamConcurrency)
+	services.SetUpstreams(promURL, lokiURL, tempoURL)
+	if err := services.SetProxies(cfg.PrometheusProxy, cfg.LokiProxy, cfg.TempoProxy); err != nil {
+		log.Printf("Warning: Ignoring upstream proxies: %v", err)
+	}
+	services.SetKubeConfig(cfg.KubeConfig)
+	defer func() {
+		if err := services.RemoveInClusterKubeconfig(); err != nil {
+			log.Printf("Failed to remove the in-cluster kubeconfig: %v", err)
+		}
+	}()
+	services.SetClusters(cfg.KubeClusters)
+	services.SetNamespaces(cfg.K8sNamespaces)
+	services.SetResponseSizeWarning(cfg.UpstreamWarnBytes)
+	services.SetUserAgent(cfg.UserAgent)
+	services.SetQueryTimeout(cfg.QueryTimeout)
+	services.SetMetricsBackend(cfg.MetricsBackend)
+	services.SetObjectives(cfg.SLOObjectives)
+	services.SetQueryTemplates(cfg.QueryTemplates)
+	services.SetMinTraffic(cfg.SLOMinRequests, cfg.SLOTrafficQuery)
+	services.SetBurnRateWindows(cfg.SLOBurnRateWindows)
+	correlation.SetNoDataSeverity(cfg.NoDataSeverity)
+	correlation.SetLatencyThresholds(cfg.LatencyWarning, cfg.LatencyCritical)
+	correlation.SetTraceFailureThresholds(cfg.TraceFailureWarning, cfg.TraceFailureCritical)
+	correlation.SetRangeConcurrency(cfg.RangeConcurrency)
+	utils.SetPrecision(cfg.JSONPrecision)
+	correlation.SetNewIncidentWindow(cfg.NewIncidentWindow)
+	correlation.SetAgreementWindow(cfg.RootCauseWindow)
+	correlation.SetOOMLinkWindow(cfg.OOMLinkWindow)
+	correlation.SetAnchorWindow(cfg.AnchorBefore, cfg.AnchorAfter)
+	correlation.SetDependencyGraph(cfg.ServiceDependencies)
+	correlation.SetServiceTiers(cfg.ServiceTiers)
+	correlation.SetFingerprintFields(cfg.FingerprintFields)
+	analysis.SetNotReadyThreshold(cfg.PodNotReadyThreshold)
+	analysis.SetStatusFallbacks(cfg.StatusFallbacks)
+	if cfg.NotifyTemplate != "" {
+		tmpl, err := notify.ParseTemplate(cfg.NotifyTemplate)
+		if err != nil {
+			log.Fatalf("Failed to load notification template: %v", err)
+		}
+		notify.SetTemplate(tmpl)
+	}
+	analysis.SetMaxMessageLength(cfg.MaxMessageLength)
+	analysis.SetLogSampling(cfg.LogSampleThreshold, cfg.LogSampleEvery)
+	analysis.SetCorrelationFields(cfg.LogCorrelationFields)
+	analysis.SetStructuredMetadata(cfg.LogStructuredMetadata)
+	analysis.SetLogDedup(cfg.LogDedup)
+	correlation.SetSourceDeadlines(cfg.SourceDeadline, cfg.SourceDeadlines)
+	metrics.SetLabelPolicy(cfg.MetricLabelAllowlist, cfg.MetricHashedLabels)
+	analysis.SetRootCauseKeywords(cfg.RootCauseKeywords)
+	analysis.SetTraceSamplingRate(cfg.TraceSamplingRate)
+	if gen, ok := correlation.IDSchemes[cfg.IncidentIDScheme]; ok {
+		correlation.SetIDGenerator(gen)
+	} else {
+		log.Printf("Warning: Unknown INCIDENT_ID_SCHEME %q, using timestamp", cfg.IncidentIDScheme)
+	}
+	if strategy, ok := correlation.Strategies[cfg.CorrelationStrategy]; ok {
+		correlation.SetStrategy(strategy)
+	} else {
+		log.Printf("Warning: Unknown CORRELATION_STRATEGY %q, using default", cfg.CorrelationStrategy)
+	}
+	if cfg.StartupCheck != "off" {
+		checkCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := services.SelfCheck(checkCtx, cfg.RequiredUpstreams, cfg.StartupCheck == "fail")
+		cancel()
+		if err != nil {
+			log.Fatalf("Startup self-check failed: %v", err)
+		}
+	}
+
 	// Setup router
 	router := mux.NewRouter()
 
 	// Setup middleware - Security first
+	router.Use(metrics.InstrumentHTTP)
 	router.Use(middleware.Recovery)
 	router.Use(middleware.Logging)
 	router.Use(middleware.SecurityHeadersMiddleware)
@@ -104,21 +186,50 @@ func main() {
 
 	// Public routes
 	router.HandleFunc("/health", server.healthHandler).Methods("GET")
-	router.HandleFunc("/api/auth/login", middleware.LoginHandler(db)).Methods("POST")
-	router.HandleFunc("/api/auth/register", middleware.RegisterHandler(db)).Methods("POST")
-	router.HandleFunc("/api/auth/refresh", middleware.RefreshTokenHandler()).Methods("POST")
+	router.HandleFunc("/api/version", handlers.GetVersion).Methods("GET")
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
+
+	// Basic auth, when configured, replaces JWT and its login routes
+	auth := middleware.Auth
+	if cfg.BasicAuthUser != "" {
+		if cfg.BasicAuthPassword == "" && cfg.BasicAuthPasswordHash == "" {
+			log.Fatal("BASIC_AUTH_USER requires BASIC_AUTH_PASSWORD or BASIC_AUTH_PASSWORD_HASH")
+		}
+		auth = middleware.BasicAuth(cfg.BasicAuthUser, cfg.BasicAuthPassword, cfg.BasicAuthPasswordHash)
+		log.Printf("API protected by basic auth for user %s", cfg.BasicAuthUser)
+	} else {
+		router.HandleFunc("/api/auth/login", middleware.LoginHandler(db)).Methods("POST")
+		router.HandleFunc("/api/auth/register", middleware.RegisterHandler(db)).Methods("POST")
+		router.HandleFunc("/api/auth/refresh", middleware.RefreshTokenHandler()).Methods("POST")
+	}
+
+	// OTLP/HTTP trace receiver, at the path exporters default to
+	router.Handle("/v1/traces", auth(http.HandlerFunc(handlers.ReceiveTraces))).Methods("POST")
 
 	// Protected routes
 	api := router.PathPrefix("/api").Subrouter()
-	api.Use(middleware.Auth)
+	api.Use(auth)
 
 	// Incidents routes
 	api.HandleFunc("/incidents", server.getIncidentsHandler).Methods("GET")
+	api.HandleFunc("/incidents/stream", handlers.StreamIncidents).Methods("GET")
 	api.HandleFunc("/incidents", server.createIncidentHandler).Methods("POST")
+	api.HandleFunc("/incidents/bulk", handlers.BulkIncidents).Methods("POST")
+	api.HandleFunc("/incidents/active", handlers.GetActiveIncidents).Methods("GET")
 	api.HandleFunc("/incidents/{id}", server.getIncidentHandler).Methods("GET")
 	api.HandleFunc("/incidents/{id}", server.updateIncidentHandler).Methods("PATCH")
 	api.HandleFunc("/incidents/{id}/timeline", server.getIncidentTimelineHandler).Methods("GET")
 	api.HandleFunc("/incidents/{id}/correlations", server.getIncidentCorrelationsHandler).Methods("GET")
+	api.HandleFunc("/incident/{service}", handlers.Cached(handlers.GetServiceIncident)).Methods("GET")
+	api.HandleFunc("/incident/{service}/ack", handlers.AckIncident).Methods("POST")
+	api.HandleFunc("/incident/{service}/refresh", handlers.RefreshIncident).Methods("POST")
+	api.HandleFunc("/incident/{service}/history", handlers.GetIncidentHistory).Methods("GET")
+	api.HandleFunc("/incident/{service}/arc", handlers.GetIncidentArc).Methods("GET")
+	api.HandleFunc("/correlation/health", handlers.Cached(handlers.GetCorrelationHealth)).Methods("GET")
+	api.HandleFunc("/query/validate", handlers.ValidateQuery).Methods("POST")
+	api.HandleFunc("/alerts", handlers.ReceiveAlerts).Methods("POST")
+	api.HandleFunc("/debug/source", handlers.GetSourceDebug).Methods("GET")
+	api.HandleFunc("/debug/config", handlers.GetConfigDebug).Methods("GET")
 
 	// SLO routes
 	api.HandleFunc("/slos", server.getSLOsHandler).Methods("GET")
@@ -128,6 +239,9 @@ func main() {
 	api.HandleFunc("/slos/{id}", server.deleteSLOHandler).Methods("DELETE")
 	api.HandleFunc("/slos/{id}/calculate", server.calculateSLOHandler).Methods("POST")
 	api.HandleFunc("/slos/{id}/history", server.getSLOHistoryHandler).Methods("GET")
+	api.HandleFunc("/slos/{id}/burn-rate", server.getSLOBurnRateHandler).Methods("GET")
+	api.HandleFunc("/slo/budget", handlers.Cached(server.getSLOBudgetHandler)).Methods("GET")
+	api.HandleFunc("/slo/status", handlers.Cached(handlers.GetSLOStatus)).Methods("GET")
 
 	// Metrics routes
 	api.HandleFunc("/metrics/availability/{service}", server.getServiceAvailabilityHandler).Methods("GET")
@@ -181,16 +295,11 @@ func main() {
 	// Start background jobs with context
 	ctx, cancelBackgroundJobs := context.WithCancel(context.Background())
 	go server.startBackgroundJobs(ctx)
+	go handlers.History().Run(ctx, cfg.IncidentCleanupInterval)
 
 	// Start server
 	port := getEnv("PORT", "9000")
-	srv := &http.Server{
-		Addr:         ":" + port,
-		Handler:      corsHandler.Handler(router),
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
+	srv := handlers.NewServer(":"+port, corsHandler.Handler(router))
 
 	// Graceful shutdown
 	go func() {
@@ -467,7 +576,8 @@ func (s *Server) getIncidentTimelineHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	respondJSON(w, http.StatusOK, timeline)
+	// Accept: application/x-ndjson streams one event per line
+	handlers.RespondList(w, r, timeline)
 }
 
 func (s *Server) getIncidentCorrelationsHandler(w http.ResponseWriter, r *http.Request) {
@@ -493,6 +603,17 @@ func (s *Server) getSLOsHandler(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, slos)
 }
 
+func (s *Server) getSLOBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	budget, err := s.sloService.GetFleetBudget(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get error budgets")
+		return
+	}
+
+	w.Header().Set("ETag", handlers.ETagFor(budget.Version()))
+	respondJSON(w, http.StatusOK, budget)
+}
+
 func (s *Server) createSLOHandler(w http.ResponseWriter, r *http.Request) {
 	var slo services.SLO
 	if err := json.NewDecoder(r.Body).Decode(&slo); err != nil {
@@ -566,6 +687,28 @@ func (s *Server) calculateSLOHandler(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, slo)
 }
 
+func (s *Server) getSLOBurnRateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sloID := vars["id"]
+
+	alerts, err := s.sloService.CalculateBurnRate(r.Context(), sloID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to calculate burn rate")
+		return
+	}
+
+	firing := []string{}
+	for _, alert := range alerts {
+		if alert.Firing {
+			firing = append(firing, alert.Window)
+		}
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"windows": alerts,
+		"firing":  firing,
+	})
+}
+
 func (s *Server) getSLOHistoryHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	sloID := vars["id"]
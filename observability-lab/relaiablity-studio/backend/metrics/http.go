@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	httpRequests = NewCounter(
+		"rl_http_requests_total",
+		"HTTP requests served, by route template",
+		"route", "method", "status",
+	)
+	httpDuration = NewHistogram(
+		"rl_http_request_duration_seconds",
+		"Time to serve HTTP requests, by route template",
+		ExponentialBuckets(0.005, 2, 12), // 5ms to ~10s
+		"route",
+	)
+)
+
+// InstrumentHTTP is mux middleware recording request counts and durations
+// labelled with the matched route template (e.g. /api/incident/{service})
+// rather than the raw path, which would explode label cardinality
+func InstrumentHTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		route := RouteTemplate(r)
+		httpRequests.Inc(route, r.Method, strconv.Itoa(sw.status))
+		httpDuration.Observe(time.Since(start).Seconds(), route)
+	})
+}
+
+// RouteTemplate returns the matched mux route, e.g. /api/incident/{service}.
+// Router middleware only runs for matched routes; outside a router, e.g. a
+// handler called directly, it is the request path.
+func RouteTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			return tmpl
+		}
+	}
+	return r.URL.Path
+}
+
+// statusWriter records the response status
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush lets streaming handlers (SSE) flush through the wrapper
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to set
+// deadlines
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
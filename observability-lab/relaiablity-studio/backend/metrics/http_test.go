@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestInstrumentHTTPUsesRouteTemplate(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(InstrumentHTTP)
+	api := router.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/incident/{service}", func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["service"] == "ghost" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}).Methods("GET")
+
+	for _, path := range []string{"/api/incident/checkout", "/api/incident/payments", "/api/incident/ghost"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	route := "/api/incident/{service}"
+	if got := httpRequests.Value(route, "GET", "200"); got != 2 {
+		t.Errorf("Expected 2 successful requests under the route template, got %v", got)
+	}
+	if got := httpRequests.Value(route, "GET", "404"); got != 1 {
+		t.Errorf("Expected 1 not-found request under the route template, got %v", got)
+	}
+	if got := httpRequests.Value("/api/incident/checkout", "GET", "200"); got != 0 {
+		t.Errorf("Expected no series for the concrete path, got %v", got)
+	}
+	if count, _ := httpDuration.Count(route); count != 3 {
+		t.Errorf("Expected 3 duration observations, got %d", count)
+	}
+}
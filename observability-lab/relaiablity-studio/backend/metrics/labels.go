@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// DefaultLabelAllowlist are the labels emitted by default. Each has a small,
+// fixed set of values; anything else, like query strings or service names,
+// could add a series per distinct value.
+var DefaultLabelAllowlist = []string{"route", "method", "status", "target", "source"}
+
+var (
+	allowedLabels = labelSet(DefaultLabelAllowlist)
+	hashedLabels  = map[string]bool{}
+)
+
+// SetLabelPolicy sets which labels are attached to emitted metrics. Labels
+// outside allowed are dropped, merging the series that differ only in them;
+// hashed labels are emitted with a short hash of their value instead, which
+// keeps raw queries out of Prometheus but not their cardinality. An empty
+// allowed restores DefaultLabelAllowlist.
+func SetLabelPolicy(allowed, hashed []string) {
+	if len(allowed) == 0 {
+		allowed = DefaultLabelAllowlist
+	}
+	allowedLabels, hashedLabels = labelSet(allowed), labelSet(hashed)
+}
+
+// labelValue applies the policy to one label, reporting false if it is dropped
+func labelValue(label, value string) (string, bool) {
+	if hashedLabels[label] {
+		sum := sha256.Sum256([]byte(value))
+		return hex.EncodeToString(sum[:6]), true
+	}
+	return value, allowedLabels[label]
+}
+
+func labelSet(labels []string) map[string]bool {
+	set := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		set[l] = true
+	}
+	return set
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLabelPolicy(t *testing.T) {
+	defer SetLabelPolicy(nil, nil)
+	query := `sum(rate(http_requests_total{service="checkout",code=~"5.."}[5m]))`
+
+	tests := []struct {
+		name     string
+		hashed   []string
+		expected string
+	}{
+		{"dropped by default", nil, `rl_label_test_total{source="metrics"} 1`},
+		{"hashed", []string{"query"}, `rl_label_hashed_test_total{source="metrics",query="`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetLabelPolicy(nil, tt.hashed)
+			name := strings.SplitN(tt.expected, "{", 2)[0]
+			c := NewCounter(name, "Test counter", "source", "query")
+			c.Inc("metrics", query)
+
+			rec := httptest.NewRecorder()
+			Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+			body := rec.Body.String()
+			if !strings.Contains(body, tt.expected) {
+				t.Errorf("Expected exposition to contain %q, got:\n%s", tt.expected, body)
+			}
+			if strings.Contains(body, "sum(rate(") {
+				t.Errorf("Expected the raw query not to be emitted, got:\n%s", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHistogramExposition(t *testing.T) {
+	h := NewHistogram("rl_test_bytes", "Test sizes", []float64{10, 100}, "target")
+	h.Observe(5, "loki")
+	h.Observe(50, "loki")
+	h.Observe(500, "loki")
+
+	if count, sum := h.Count("loki"); count != 3 || sum != 555 {
+		t.Errorf("Expected count 3 sum 555, got %d %v", count, sum)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		"# TYPE rl_test_bytes histogram",
+		`rl_test_bytes_bucket{target="loki",le="10"} 1`,
+		`rl_test_bytes_bucket{target="loki",le="100"} 2`,
+		`rl_test_bytes_bucket{target="loki",le="+Inf"} 3`,
+		`rl_test_bytes_sum{target="loki"} 555`,
+		`rl_test_bytes_count{target="loki"} 3`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected exposition to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestCounter(t *testing.T) {
+	c := NewCounter("rl_test_total", "Test counter", "source", "status")
+	c.Inc("logs", "ok")
+	c.Add(2, "logs", "ok")
+	c.Inc("k8s", "error")
+
+	if got := c.Value("logs", "ok"); got != 3 {
+		t.Errorf("Expected 3, got %v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if want := `rl_test_total{source="k8s",status="error"} 1`; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("Expected exposition to contain %q, got:\n%s", want, rec.Body.String())
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BasicAuthRealm names the protected API in basic auth challenges
+const BasicAuthRealm = "reliability-studio"
+
+// BasicAuth protects a handler with HTTP basic auth for a single user, as a
+// lighter alternative to JWT. passwordHash, a bcrypt hash, is checked when
+// set; otherwise the password must match password. Missing or wrong
+// credentials get 401 with a WWW-Authenticate challenge. The user is the only
+// one, so it is put in the context as Claims with the admin role, which
+// RequireRole accepts.
+func BasicAuth(username, password, passwordHash string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, ok := r.BasicAuth()
+			if !ok || !basicAuthValid(user, pass, username, password, passwordHash) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="`+BasicAuthRealm+`", charset="UTF-8"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			claims := &Claims{Username: user, Roles: []string{"admin"}, TokenType: "access"}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), UserContext, claims)))
+		})
+	}
+}
+
+// basicAuthValid compares in constant time so timing doesn't reveal how much
+// of the credentials matched
+func basicAuthValid(user, pass, username, password, passwordHash string) bool {
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(username)) == 1
+	var passOK bool
+	if passwordHash != "" {
+		passOK = bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(pass)) == nil
+	} else {
+		passOK = subtle.ConstantTimeCompare([]byte(pass), []byte(password)) == 1
+	}
+	return userOK && passOK
+}
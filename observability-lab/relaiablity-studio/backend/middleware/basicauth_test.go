@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBasicAuth(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	for name, protected := range map[string]http.Handler{
+		"password": BasicAuth("ops", "s3cret", "")(ok),
+		"bcrypt":   BasicAuth("ops", "", string(hash))(ok),
+	} {
+		testCases := []struct {
+			name       string
+			user, pass string
+			send       bool
+			expected   int
+		}{
+			{"missing", "", "", false, http.StatusUnauthorized},
+			{"wrong password", "ops", "guess", true, http.StatusUnauthorized},
+			{"wrong user", "admin", "s3cret", true, http.StatusUnauthorized},
+			{"correct", "ops", "s3cret", true, http.StatusOK},
+		}
+		for _, tc := range testCases {
+			req := httptest.NewRequest("GET", "/api/services", nil)
+			if tc.send {
+				req.SetBasicAuth(tc.user, tc.pass)
+			}
+			rec := httptest.NewRecorder()
+			protected.ServeHTTP(rec, req)
+
+			if rec.Code != tc.expected {
+				t.Errorf("%s, %s: expected status %d, got %d", name, tc.name, tc.expected, rec.Code)
+			}
+			challenge := rec.Header().Get("WWW-Authenticate")
+			if tc.expected == http.StatusUnauthorized && challenge == "" {
+				t.Errorf("%s, %s: expected a WWW-Authenticate challenge", name, tc.name)
+			}
+			if tc.expected == http.StatusOK && challenge != "" {
+				t.Errorf("%s, %s: expected no challenge, got %q", name, tc.name, challenge)
+			}
+		}
+	}
+}
+
+func TestBasicAuthGrantsAdmin(t *testing.T) {
+	var user string
+	admin := BasicAuth("ops", "s3cret", "")(RequireRole("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = r.Context().Value(UserContext).(*Claims).Username
+	})))
+
+	req := httptest.NewRequest("GET", "/api/admin/services", nil)
+	req.SetBasicAuth("ops", "s3cret")
+	rec := httptest.NewRecorder()
+	admin.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || user != "ops" {
+		t.Errorf("Expected the basic auth user admitted to admin routes, got %d for %q", rec.Code, user)
+	}
+}
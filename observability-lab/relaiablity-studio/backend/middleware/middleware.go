@@ -15,8 +15,15 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-// JWT_SECRET must be strong and come from environment
-var JWT_SECRET = []byte(getEnvStrict("JWT_SECRET"))
+// JWT_SECRET must be strong and come from environment; see LoadJWTSecret
+var JWT_SECRET []byte
+
+// LoadJWTSecret reads JWT_SECRET from the environment, exiting if it isn't
+// set. main calls it before anything else, so no token is ever signed or
+// checked with an empty key.
+func LoadJWTSecret() {
+	JWT_SECRET = []byte(getEnvStrict("JWT_SECRET"))
+}
 
 // Token expiration times
 const (
@@ -640,3 +647,16 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Flush lets streaming handlers (SSE) flush through the wrapper
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to set
+// deadlines
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,227 @@
+// Package notify pages on-call for critical incidents. Pages can be
+// suppressed, e.g. while an incident is acknowledged or during maintenance.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+// PageSeverity is the incident severity that pages
+const PageSeverity = "critical"
+
+// WarnSeverity is the incident severity that notifies when warnings are enabled
+const WarnSeverity = "warning"
+
+// Notifier delivers a page for an incident
+type Notifier interface {
+	Notify(ctx context.Context, incident correlation.Incident) error
+}
+
+// Suppressor reports whether pages for a service are suppressed at now, and why
+type Suppressor interface {
+	Suppressed(service string, now time.Time) (reason string, suppressed bool)
+}
+
+// QuietPeriod reports whether only critical incidents should notify at now
+type QuietPeriod interface {
+	Active(now time.Time) bool
+}
+
+// Dispatcher sends pageable incidents to every notifier unless a suppressor
+// silences them
+type Dispatcher struct {
+	// MinConfidence is the correlation confidence (0-1) an incident needs to
+	// page; less certain incidents are still recorded but don't wake anyone
+	MinConfidence float64
+
+	// NotifyWarnings also sends warning incidents, except during QuietHours.
+	// Warnings raised in quiet hours are dropped, or with QueueQuiet held
+	// (the latest per service) and sent by the first Dispatch after them if
+	// they would still page then. A later incident for the service replaces
+	// its held warning.
+	NotifyWarnings bool
+	QuietHours     QuietPeriod
+	QueueQuiet     bool
+
+	// SeverityFloor is the lowest severity sent to any notifier, WarnSeverity
+	// or PageSeverity. It overrides NotifyWarnings; empty defers to it.
+	SeverityFloor string
+
+	notifiers   []Notifier
+	suppressors []Suppressor
+	now         func() time.Time
+
+	mu     sync.Mutex
+	queued map[string]correlation.Incident
+	paged  map[string]page // The last page sent per service, until it is healthy
+}
+
+// page is what was last sent for a service
+type page struct {
+	fingerprint string
+	severity    string
+}
+
+// NewDispatcher creates a dispatcher. With no notifiers it never pages.
+func NewDispatcher(suppressors []Suppressor, notifiers ...Notifier) *Dispatcher {
+	return &Dispatcher{notifiers: notifiers, suppressors: suppressors, now: time.Now}
+}
+
+// Suppressed reports whether any suppressor currently silences service
+func (d *Dispatcher) Suppressed(service string) (string, bool) {
+	now := d.now()
+	for _, s := range d.suppressors {
+		if reason, ok := s.Suppressed(service, now); ok {
+			return reason, true
+		}
+	}
+	return "", false
+}
+
+// floor returns the lowest severity that notifies
+func (d *Dispatcher) floor() string {
+	if d.SeverityFloor != "" {
+		return d.SeverityFloor
+	}
+	if d.NotifyWarnings {
+		return WarnSeverity
+	}
+	return PageSeverity
+}
+
+// Dispatch pages for incident if it is at or above the severity floor (warnings
+// only outside quiet hours), confident enough and not suppressed. An incident
+// with the fingerprint of the service's last page only pages again if it has
+// escalated from warning to critical, so every rebuild of an ongoing incident
+// doesn't page; a healthy incident ends it. It reports whether a page was
+// sent; notifier errors are logged.
+func (d *Dispatcher) Dispatch(ctx context.Context, incident correlation.Incident) bool {
+	if len(d.notifiers) == 0 {
+		return false
+	}
+	d.mu.Lock()
+	if incident.Severity == "healthy" {
+		delete(d.paged, incident.Service)
+	}
+	delete(d.queued, incident.Service)
+	d.mu.Unlock()
+	quiet := d.QuietHours != nil && d.QuietHours.Active(d.now())
+	if !quiet {
+		d.flushQueued(ctx)
+	}
+
+	if !d.pageable(incident) {
+		return false
+	}
+	if quiet && incident.Severity != PageSeverity {
+		if d.QueueQuiet {
+			d.mu.Lock()
+			if d.queued == nil {
+				d.queued = make(map[string]correlation.Incident)
+			}
+			d.queued[incident.Service] = incident
+			d.mu.Unlock()
+			log.Printf("Holding %s until quiet hours end", incident.ID)
+		} else {
+			log.Printf("Not paging for %s: %s during quiet hours", incident.ID, incident.Severity)
+		}
+		return false
+	}
+
+	if d.alreadyPaged(incident) {
+		return false
+	}
+	d.send(ctx, incident)
+	return true
+}
+
+// pageable reports whether incident is at or above the severity floor,
+// confident enough and not suppressed, logging why not
+func (d *Dispatcher) pageable(incident correlation.Incident) bool {
+	switch {
+	case incident.Severity == PageSeverity:
+	case incident.Severity == WarnSeverity && d.floor() == WarnSeverity:
+	default:
+		return false
+	}
+	if incident.Confidence < d.MinConfidence {
+		log.Printf("Not paging for %s: confidence %.2f is below %.2f", incident.ID, incident.Confidence, d.MinConfidence)
+		return false
+	}
+	if reason, ok := d.Suppressed(incident.Service); ok {
+		log.Printf("Not paging for %s: %s", incident.ID, reason)
+		return false
+	}
+	return true
+}
+
+// alreadyPaged reports whether incident repeats the service's last page
+func (d *Dispatcher) alreadyPaged(incident correlation.Incident) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	last, ok := d.paged[incident.Service]
+	return ok && incident.Fingerprint != "" && last.fingerprint == incident.Fingerprint &&
+		(last.severity == incident.Severity || incident.Severity != PageSeverity)
+}
+
+// flushQueued sends the warnings held over quiet hours that would still page:
+// one acknowledged or put in maintenance since is dropped
+func (d *Dispatcher) flushQueued(ctx context.Context) {
+	d.mu.Lock()
+	queued := d.queued
+	d.queued = nil
+	d.mu.Unlock()
+
+	for _, incident := range queued {
+		if d.pageable(incident) && !d.alreadyPaged(incident) {
+			d.send(ctx, incident)
+		}
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, incident correlation.Incident) {
+	d.mu.Lock()
+	if d.paged == nil {
+		d.paged = make(map[string]page)
+	}
+	d.paged[incident.Service] = page{fingerprint: incident.Fingerprint, severity: incident.Severity}
+	d.mu.Unlock()
+
+	for _, n := range d.notifiers {
+		if err := n.Notify(ctx, incident); err != nil {
+			log.Printf("Failed to page for %s: %v", incident.ID, err)
+		}
+	}
+}
+
+// Webhook posts incidents as JSON to a URL, with the page formatted by the
+// notification template in a "text" field (as chat webhooks expect)
+type Webhook struct {
+	URL     string
+	Retries int          // Times a failed delivery is retried
+	Client  *http.Client // nil uses a client with a 10s timeout
+}
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// Notify posts the incident, failing on a non-2xx response. Transport errors
+// and 5xx responses are retried.
+func (w Webhook) Notify(ctx context.Context, incident correlation.Incident) error {
+	body, err := json.Marshal(struct {
+		correlation.Incident
+		Text string `json:"text"`
+	}{incident, pageText(incident)})
+	if err != nil {
+		return err
+	}
+	return withRetry(ctx, w.Retries, func() error {
+		return post(ctx, w.Client, w.URL, body, nil)
+	})
+}
@@ -0,0 +1,250 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+	"github.com/sarikasharma2428-web/reliability-studio/store"
+)
+
+type recordingNotifier struct {
+	pages []string
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, incident correlation.Incident) error {
+	n.pages = append(n.pages, incident.ID)
+	return nil
+}
+
+func TestDispatchSuppressedWhileAcknowledged(t *testing.T) {
+	now := time.Date(2024, 1, 15, 2, 0, 0, 0, time.UTC)
+	history := store.New(store.Retention{})
+	history.Acknowledge("checkout", store.Ack{Author: "oncall", Reason: "known DB failover", Until: now.Add(time.Hour)})
+
+	notifier := &recordingNotifier{}
+	d := NewDispatcher([]Suppressor{history}, notifier)
+	incident := correlation.Incident{ID: "checkout-1", Service: "checkout", Severity: "critical"}
+
+	d.now = func() time.Time { return now.Add(30 * time.Minute) }
+	if d.Dispatch(context.Background(), incident) || len(notifier.pages) != 0 {
+		t.Errorf("Expected no page while acknowledged, got %v", notifier.pages)
+	}
+
+	other := correlation.Incident{ID: "payments-1", Service: "payments", Severity: "critical"}
+	if !d.Dispatch(context.Background(), other) {
+		t.Error("Expected other services to still page")
+	}
+
+	d.now = func() time.Time { return now.Add(time.Hour) }
+	if !d.Dispatch(context.Background(), incident) {
+		t.Error("Expected paging to resume once the acknowledgement expired")
+	}
+	if len(notifier.pages) != 2 || notifier.pages[1] != "checkout-1" {
+		t.Errorf("Expected pages for payments then checkout, got %v", notifier.pages)
+	}
+}
+
+func TestDispatchSuppressedDuringMaintenance(t *testing.T) {
+	now := time.Date(2024, 1, 15, 2, 30, 0, 0, time.UTC)
+	windows := config.MaintenanceWindows{
+		{Service: "checkout", Start: now.Add(-time.Hour), End: now.Add(time.Hour)},
+		{Service: "payments", Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)},
+	}
+
+	notifier := &recordingNotifier{}
+	d := NewDispatcher([]Suppressor{windows}, notifier)
+	d.now = func() time.Time { return now }
+
+	if _, ok := d.Suppressed("checkout"); !ok {
+		t.Error("Expected checkout suppressed by its active window")
+	}
+	if d.Dispatch(context.Background(), correlation.Incident{ID: "checkout-1", Service: "checkout", Severity: "critical"}) {
+		t.Error("Expected no page during an active maintenance window")
+	}
+
+	if _, ok := d.Suppressed("payments"); ok {
+		t.Error("Expected payments not suppressed before its window")
+	}
+	if !d.Dispatch(context.Background(), correlation.Incident{ID: "payments-1", Service: "payments", Severity: "critical"}) {
+		t.Error("Expected a page outside the maintenance window")
+	}
+	if len(notifier.pages) != 1 || notifier.pages[0] != "payments-1" {
+		t.Errorf("Expected only payments paged, got %v", notifier.pages)
+	}
+}
+
+func TestDispatchOnlyCritical(t *testing.T) {
+	notifier := &recordingNotifier{}
+	d := NewDispatcher(nil, notifier)
+
+	for _, severity := range []string{"warning", "healthy", "unknown"} {
+		if d.Dispatch(context.Background(), correlation.Incident{Service: "checkout", Severity: severity}) {
+			t.Errorf("Expected %s incident not to page", severity)
+		}
+	}
+}
+
+func TestDispatchSeverityFloor(t *testing.T) {
+	notifier := &recordingNotifier{}
+	d := NewDispatcher(nil, notifier)
+	d.NotifyWarnings = true
+	d.SeverityFloor = "critical"
+
+	if d.Dispatch(context.Background(), correlation.Incident{ID: "checkout-1", Service: "checkout", Severity: "warning"}) {
+		t.Error("Expected no notification for a warning below the critical floor")
+	}
+	if !d.Dispatch(context.Background(), correlation.Incident{ID: "checkout-2", Service: "checkout", Severity: "critical"}) {
+		t.Error("Expected a notification for a critical incident")
+	}
+	if len(notifier.pages) != 1 || notifier.pages[0] != "checkout-2" {
+		t.Errorf("Expected only the critical incident sent, got %v", notifier.pages)
+	}
+
+	d.NotifyWarnings, d.SeverityFloor = false, "warning"
+	if !d.Dispatch(context.Background(), correlation.Incident{ID: "checkout-3", Service: "checkout", Severity: "warning"}) {
+		t.Error("Expected a warning floor to send warnings")
+	}
+}
+
+func TestDispatchDedupesByFingerprint(t *testing.T) {
+	notifier := &recordingNotifier{}
+	d := NewDispatcher(nil, notifier)
+	d.NotifyWarnings = true
+
+	incident := func(id, severity, fingerprint string) correlation.Incident {
+		return correlation.Incident{ID: id, Service: "checkout", Severity: severity, Fingerprint: fingerprint}
+	}
+	steps := []struct {
+		incident correlation.Incident
+		paged    bool
+	}{
+		{incident("checkout-1", "warning", "db-down"), true},
+		{incident("checkout-2", "warning", "db-down"), false},   // Rebuilt while ongoing
+		{incident("checkout-3", "critical", "db-down"), true},   // Escalated
+		{incident("checkout-4", "warning", "db-down"), false},   // Calmer, still the same issue
+		{incident("checkout-5", "critical", "disk-full"), true}, // A different issue
+		{incident("checkout-6", "healthy", "ok"), false},
+		{incident("checkout-7", "critical", "disk-full"), true}, // Back after recovering
+	}
+	for _, step := range steps {
+		if got := d.Dispatch(context.Background(), step.incident); got != step.paged {
+			t.Errorf("%s (%s, %s): expected paged=%v, got %v", step.incident.ID, step.incident.Severity, step.incident.Fingerprint, step.paged, got)
+		}
+	}
+}
+
+func TestDispatchMinConfidence(t *testing.T) {
+	notifier := &recordingNotifier{}
+	d := NewDispatcher(nil, notifier)
+	d.MinConfidence = 0.5
+
+	low := correlation.Incident{ID: "checkout-1", Service: "checkout", Severity: "critical", Confidence: 0.3, LowConfidence: true}
+	if d.Dispatch(context.Background(), low) {
+		t.Error("Expected no page for a low-confidence incident")
+	}
+
+	high := correlation.Incident{ID: "checkout-2", Service: "checkout", Severity: "critical", Confidence: 0.8}
+	if !d.Dispatch(context.Background(), high) {
+		t.Error("Expected a page for a high-confidence incident")
+	}
+	if len(notifier.pages) != 1 || notifier.pages[0] != "checkout-2" {
+		t.Errorf("Expected only the confident incident paged, got %v", notifier.pages)
+	}
+}
+
+func TestWebhookNotify(t *testing.T) {
+	var got correlation.Incident
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	err := Webhook{URL: server.URL}.Notify(context.Background(), correlation.Incident{ID: "checkout-1", Severity: "critical"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.ID != "checkout-1" {
+		t.Errorf("Expected incident posted to webhook, got %+v", got)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := (Webhook{URL: failing.URL}).Notify(context.Background(), correlation.Incident{}); err == nil {
+		t.Error("Expected an error for a failing webhook")
+	}
+}
+
+func TestDispatchQuietHours(t *testing.T) {
+	night := time.Date(2024, 1, 15, 23, 0, 0, 0, time.UTC)
+	morning := time.Date(2024, 1, 16, 8, 0, 0, 0, time.UTC)
+	quiet := config.QuietHoursList{{Start: "22:00", End: "07:00"}}
+	warning := correlation.Incident{ID: "checkout-1", Service: "checkout", Severity: "warning"}
+	critical := correlation.Incident{ID: "payments-1", Service: "payments", Severity: "critical"}
+
+	notifier := &recordingNotifier{}
+	d := NewDispatcher(nil, notifier)
+	d.NotifyWarnings = true
+	d.QuietHours = quiet
+	d.now = func() time.Time { return night }
+
+	if d.Dispatch(context.Background(), warning) {
+		t.Error("Expected a warning to be suppressed during quiet hours")
+	}
+	if !d.Dispatch(context.Background(), critical) {
+		t.Error("Expected a critical incident to page during quiet hours")
+	}
+	if len(notifier.pages) != 1 || notifier.pages[0] != "payments-1" {
+		t.Errorf("Expected only the critical page, got %v", notifier.pages)
+	}
+
+	d.now = func() time.Time { return morning }
+	if !d.Dispatch(context.Background(), warning) {
+		t.Error("Expected warnings to notify outside quiet hours")
+	}
+
+	// Queued warnings go out with the first dispatch after quiet hours
+	queued := &recordingNotifier{}
+	d = NewDispatcher(nil, queued)
+	d.NotifyWarnings, d.QuietHours, d.QueueQuiet = true, quiet, true
+	d.now = func() time.Time { return night }
+	d.Dispatch(context.Background(), warning)
+	if len(queued.pages) != 0 {
+		t.Fatalf("Expected the warning held during quiet hours, got %v", queued.pages)
+	}
+	d.now = func() time.Time { return morning }
+	d.Dispatch(context.Background(), correlation.Incident{ID: "search-1", Service: "search", Severity: "healthy"})
+	if len(queued.pages) != 1 || queued.pages[0] != "checkout-1" {
+		t.Errorf("Expected the held warning sent after quiet hours, got %v", queued.pages)
+	}
+}
+
+func TestDispatchQueuedRechecked(t *testing.T) {
+	night := time.Date(2024, 1, 15, 23, 0, 0, 0, time.UTC)
+	morning := time.Date(2024, 1, 16, 8, 0, 0, 0, time.UTC)
+	history := store.New(store.Retention{})
+	notifier := &recordingNotifier{}
+	d := NewDispatcher([]Suppressor{history}, notifier)
+	d.NotifyWarnings, d.QuietHours, d.QueueQuiet = true, config.QuietHoursList{{Start: "22:00", End: "07:00"}}, true
+	d.now = func() time.Time { return night }
+
+	for _, service := range []string{"checkout", "payments", "search"} {
+		d.Dispatch(context.Background(), correlation.Incident{ID: service + "-1", Service: service, Severity: "warning"})
+	}
+	// Overnight checkout is acknowledged and payments recovers
+	history.Acknowledge("checkout", store.Ack{Author: "oncall", Reason: "known DB failover", Until: morning.Add(time.Hour)})
+	d.Dispatch(context.Background(), correlation.Incident{ID: "payments-2", Service: "payments", Severity: "healthy"})
+
+	d.now = func() time.Time { return morning }
+	d.Dispatch(context.Background(), correlation.Incident{ID: "inventory-1", Service: "inventory", Severity: "healthy"})
+	if len(notifier.pages) != 1 || notifier.pages[0] != "search-1" {
+		t.Errorf("Expected only the warning that still pages sent, got %v", notifier.pages)
+	}
+}
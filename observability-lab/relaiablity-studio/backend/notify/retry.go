@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// retryBackoff is the wait before the first retry; it doubles for each after
+var retryBackoff = time.Second
+
+// permanentError is a delivery failure that retrying won't fix, such as a
+// 4xx response to a malformed or unauthorized request
+type permanentError struct{ error }
+
+func (e permanentError) Unwrap() error { return e.error }
+
+// withRetry calls deliver until it succeeds, fails permanently or has been
+// retried retries times, backing off between attempts. It gives up early if
+// ctx ends.
+func withRetry(ctx context.Context, retries int, deliver func() error) error {
+	wait := retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := deliver()
+		var permanent permanentError
+		if err == nil || errors.As(err, &permanent) || attempt >= retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// post sends body as JSON with the given headers. 5xx and 429 responses and
+// transport errors can be retried; other non-2xx responses are permanent.
+func post(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	if client == nil {
+		client = webhookClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	err = fmt.Errorf("webhook returned %s", resp.Status)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusTooManyRequests:
+		return err
+	}
+	return permanentError{err}
+}
@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+// DefaultTemplate formats a page when no template is configured
+const DefaultTemplate = `[{{.Severity}}] {{.Summary}}{{if .RootCause}}
+Root cause: {{.RootCause}}{{end}}
+Incident: {{.ID}}`
+
+// Template formats an incident as the text of a page. It is a text/template
+// rendered against correlation.Incident, e.g. "{{.Service}} is {{.Severity}}".
+type Template struct {
+	tmpl *template.Template
+}
+
+var (
+	defaultTemplate = mustParseTemplate(DefaultTemplate)
+	pageTemplate    = defaultTemplate
+)
+
+// sampleIncident is rendered to validate templates. Its slices, maps and
+// pointers are populated so that templates ranging over or indexing into
+// them are checked too, not skipped as they would be on a zero incident.
+var sampleIncident = func() correlation.Incident {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	event := correlation.Event{
+		Time:      at.Format(time.RFC3339),
+		Source:    "kubernetes",
+		Type:      correlation.EventOOMKill,
+		Message:   "OOMKilled",
+		Cluster:   "default",
+		Labels:    map[string]string{"trace_id": "0af7651916cd43dd8448eb211c80319c"},
+		Links:     []correlation.EventLink{{Time: at.Format(time.RFC3339), Type: correlation.EventLatencySpike, Note: "memory pressure"}},
+		Metadata:  map[string]string{"level": "error"},
+		Count:     1,
+		Namespace: "default",
+		Instance:  "checkout-0",
+	}
+	return correlation.Incident{
+		ID:        "checkout-1704110400",
+		Service:   "checkout",
+		Severity:  "critical",
+		RootCause: "OOMKilled",
+		Summary:   "checkout is critical",
+		Impact: correlation.Impact{
+			SLOAffected:      true,
+			ErrorRate:        12.5,
+			BadPods:          1,
+			OutlierInstances: map[string]float64{"checkout-0": 40},
+			FailureMix:       map[string]int{"server_error": 3},
+			BlastRadius:      correlation.BlastRadius{Services: 1, Namespaces: 1, Instances: 1},
+		},
+		Timeline:           []correlation.Event{event},
+		Priority:           "P1",
+		Anchor:             &at,
+		Confidence:         0.9,
+		SourceErrors:       map[string]string{"traces": "timeout"},
+		Fingerprint:        "checkout:oom",
+		CausalChain:        []string{"checkout"},
+		ImpactedDownstream: []string{"frontend"},
+		ComputedAt:         at,
+		FirstSeen:          &at,
+		Duration:           60,
+		IsNew:              true,
+		Dashboards:         []correlation.DashboardLink{{Name: "Service", URL: "http://grafana/d/service"}},
+	}
+}()
+
+// ParseTemplate parses a notification template. Besides syntax errors it
+// rejects references to fields an incident doesn't have, by rendering a
+// populated sample incident, so mistakes surface at startup rather than
+// mid-page.
+func ParseTemplate(text string) (*Template, error) {
+	tmpl, err := template.New("notification").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification template: %w", err)
+	}
+	t := &Template{tmpl: tmpl}
+	if _, err := t.Render(sampleIncident); err != nil {
+		return nil, fmt.Errorf("invalid notification template: %w", err)
+	}
+	return t, nil
+}
+
+func mustParseTemplate(text string) *Template {
+	t, err := ParseTemplate(text)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Render formats incident with the template
+func (t *Template) Render(incident correlation.Incident) (string, error) {
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, incident); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// SetTemplate replaces the template pages are formatted with. nil restores
+// DefaultTemplate.
+func SetTemplate(t *Template) {
+	if t == nil {
+		t = defaultTemplate
+	}
+	pageTemplate = t
+}
+
+// pageText formats incident with the configured template, falling back to
+// DefaultTemplate if it fails so a page is never lost to formatting
+func pageText(incident correlation.Incident) string {
+	text, err := pageTemplate.Render(incident)
+	if err != nil {
+		log.Printf("Failed to render notification for %s, using the default template: %v", incident.ID, err)
+		text, _ = defaultTemplate.Render(incident)
+	}
+	return text
+}
@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+func TestTemplateRender(t *testing.T) {
+	tmpl, err := ParseTemplate(`{{.Service}} is {{.Severity}}{{if .RootCause}}: {{.RootCause}}{{end}}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text, err := tmpl.Render(correlation.Incident{Service: "checkout", Severity: "critical", RootCause: "db down"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "checkout is critical: db down"; text != want {
+		t.Errorf("Expected %q, got %q", want, text)
+	}
+}
+
+func TestParseTemplateRejectsBadTemplates(t *testing.T) {
+	for _, text := range []string{
+		`{{.Service`,          // Syntax error
+		`{{.Sevrity}}`,        // No such field
+		`{{.Impact.Regions}}`, // No such nested field
+		// Only reached with data, not on a zero incident
+		`{{range .Timeline}}{{.Sevrity}}{{end}}`,
+		`{{with .Anchor}}{{.Zone}}{{end}}`,
+		`{{range .Dashboards}}{{.Link}}{{end}}`,
+	} {
+		if _, err := ParseTemplate(text); err == nil {
+			t.Errorf("Expected %q to be rejected", text)
+		}
+	}
+}
+
+func TestParseTemplateAcceptsDataTemplates(t *testing.T) {
+	for _, text := range []string{
+		`{{range .Timeline}}{{.Type}}: {{.Message}}{{end}}`,
+		`{{with .FirstSeen}}since {{.Format "15:04"}}{{end}}`,
+		`{{range $name, $err := .SourceErrors}}{{$name}}{{end}}`,
+	} {
+		if _, err := ParseTemplate(text); err != nil {
+			t.Errorf("Expected %q to be accepted, got %v", text, err)
+		}
+	}
+}
+
+func TestWebhookUsesTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate(`PAGE {{.Service}}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	SetTemplate(tmpl)
+	defer SetTemplate(nil)
+
+	var got struct {
+		ID   string `json:"id"`
+		Text string `json:"text"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	if err := (Webhook{URL: server.URL}).Notify(context.Background(), correlation.Incident{ID: "checkout-1", Service: "checkout"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.ID != "checkout-1" || got.Text != "PAGE checkout" {
+		t.Errorf("Expected the incident with templated text, got %+v", got)
+	}
+}
@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of an IncidentWebhook body, as
+// "sha256=<hex>", so receivers can verify the sender
+const SignatureHeader = "X-Reliability-Signature"
+
+// IncidentWebhook posts the incident JSON as-is to a URL, for integrations
+// with in-house systems. With a Secret every request is signed.
+type IncidentWebhook struct {
+	URL     string
+	Headers map[string]string // Sent with every request, e.g. Authorization
+	Secret  string
+	Retries int          // Times a failed delivery is retried
+	Client  *http.Client // nil uses a client with a 10s timeout
+}
+
+// Notify posts the incident, retrying transport errors and 5xx responses
+func (w IncidentWebhook) Notify(ctx context.Context, incident correlation.Incident) error {
+	body, err := json.Marshal(incident)
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]string, len(w.Headers)+1)
+	for name, value := range w.Headers {
+		headers[name] = value
+	}
+	if w.Secret != "" {
+		headers[SignatureHeader] = Sign(w.Secret, body)
+	}
+	return withRetry(ctx, w.Retries, func() error {
+		return post(ctx, w.Client, w.URL, body, headers)
+	})
+}
+
+// Sign returns the SignatureHeader value for body: "sha256=" and the hex
+// HMAC-SHA256 of body keyed with secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
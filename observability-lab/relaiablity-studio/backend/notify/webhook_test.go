@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+func TestIncidentWebhookSigned(t *testing.T) {
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = time.Second }()
+
+	var attempts int
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+	}))
+	defer server.Close()
+
+	hook := IncidentWebhook{
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Secret:  "s3cret",
+		Retries: 2,
+	}
+	incident := correlation.Incident{ID: "checkout-1", Service: "checkout", Severity: "critical"}
+	if err := hook.Notify(context.Background(), incident); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected a retry after the 502, got %d attempts", attempts)
+	}
+
+	var got correlation.Incident
+	if err := json.Unmarshal(body, &got); err != nil || got.ID != "checkout-1" || got.Severity != "critical" {
+		t.Errorf("Expected the incident JSON, got %s (%v)", body, err)
+	}
+	if header.Get("Authorization") != "Bearer token" {
+		t.Errorf("Expected configured headers sent, got %q", header.Get("Authorization"))
+	}
+	// Receivers recompute the HMAC over the raw body
+	if sig := header.Get(SignatureHeader); !hmac.Equal([]byte(sig), []byte(Sign("s3cret", body))) {
+		t.Errorf("Expected signature %s, got %s", Sign("s3cret", body), sig)
+	}
+	if sig := Sign("other", body); sig == header.Get(SignatureHeader) {
+		t.Error("Expected the signature to depend on the secret")
+	}
+}
+
+func TestIncidentWebhookNoRetryOnClientError(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	err := IncidentWebhook{URL: server.URL, Retries: 3}.Notify(context.Background(), correlation.Incident{})
+	if err == nil {
+		t.Error("Expected an error for a rejected delivery")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected a 401 not to be retried, got %d attempts", attempts)
+	}
+}
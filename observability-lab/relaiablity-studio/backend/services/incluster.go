@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// serviceAccountDir holds the token and CA mounted into pods
+var serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+var kubeConfig string
+
+// SetKubeConfig sets the kubeconfig file for clusters that don't name their
+// own. Empty uses kubectl's KUBECONFIG if set, then the in-cluster service
+// account when running in a pod, then kubectl's default kubeconfig.
+func SetKubeConfig(path string) {
+	kubeConfig = path
+}
+
+// The kubeconfig written for the in-cluster service account, and what it holds
+var (
+	inClusterMu      sync.Mutex
+	inClusterFile    string
+	inClusterContent string
+)
+
+// inClusterKubeconfig returns a kubeconfig for the API server of the cluster
+// this process runs in, authenticating with the pod's service account. It is
+// empty outside a cluster, or when KUBECONFIG already configures kubectl.
+// The token is referenced by path rather than copied, so kubectl picks up
+// rotated tokens. One file is written per process and rewritten in place if
+// the API server's address changes, and removed on shutdown by
+// RemoveInClusterKubeconfig.
+func inClusterKubeconfig() (string, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	token := filepath.Join(serviceAccountDir, "token")
+	ca := filepath.Join(serviceAccountDir, "ca.crt")
+	if host == "" || port == "" || os.Getenv("KUBECONFIG") != "" {
+		return "", nil
+	}
+	for _, f := range []string{token, ca} {
+		if _, err := os.Stat(f); err != nil {
+			return "", nil
+		}
+	}
+
+	content := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: in-cluster
+  cluster:
+    server: https://%s
+    certificate-authority: %s
+users:
+- name: in-cluster
+  user:
+    tokenFile: %s
+contexts:
+- name: in-cluster
+  context:
+    cluster: in-cluster
+    user: in-cluster
+current-context: in-cluster
+`, net.JoinHostPort(host, port), ca, token)
+
+	inClusterMu.Lock()
+	defer inClusterMu.Unlock()
+	if inClusterFile != "" && inClusterContent == content {
+		return inClusterFile, nil
+	}
+	if inClusterFile == "" {
+		f, err := os.CreateTemp("", "reliability-studio-kubeconfig-*")
+		if err != nil {
+			return "", err
+		}
+		f.Close()
+		inClusterFile = f.Name()
+	}
+	if err := os.WriteFile(inClusterFile, []byte(content), 0o600); err != nil {
+		inClusterContent = ""
+		return "", err
+	}
+	inClusterContent = content
+	return inClusterFile, nil
+}
+
+// RemoveInClusterKubeconfig removes the kubeconfig written for the in-cluster
+// service account, if any. It is called on shutdown; a later kubectl call
+// writes a new one.
+func RemoveInClusterKubeconfig() error {
+	inClusterMu.Lock()
+	defer inClusterMu.Unlock()
+	if inClusterFile == "" {
+		return nil
+	}
+	err := os.Remove(inClusterFile)
+	inClusterFile, inClusterContent = "", ""
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
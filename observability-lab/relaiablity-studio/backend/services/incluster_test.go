@@ -0,0 +1,156 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetClusterInCluster(t *testing.T) {
+	// The fake kubectl records its arguments
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\necho '{\"items\":[]}'\n"
+	if err := os.WriteFile(filepath.Join(bin, "kubectl"), []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake kubectl: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("KUBECONFIG", "")
+
+	// A mocked service account mount
+	sa := t.TempDir()
+	for _, f := range []string{"token", "ca.crt"} {
+		if err := os.WriteFile(filepath.Join(sa, f), []byte("test"), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", f, err)
+		}
+	}
+	defer func(dir string) { serviceAccountDir = dir }(serviceAccountDir)
+	serviceAccountDir = sa
+	defer SetKubeConfig("")
+
+	tests := []struct {
+		name       string
+		host       string
+		kubeConfig string
+		inCluster  bool
+		want       string
+	}{
+		{"in cluster", "10.96.0.1", "", true, "--kubeconfig"},
+		{"kubeconfig set", "10.96.0.1", "/etc/kube/config.yaml", false, "--kubeconfig /etc/kube/config.yaml"},
+		{"outside a cluster", "", "", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KUBERNETES_SERVICE_HOST", tt.host)
+			t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+			SetKubeConfig(tt.kubeConfig)
+
+			GetCluster()
+			raw, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatalf("Expected kubectl to run: %v", err)
+			}
+			args := strings.TrimSpace(string(raw))
+			if tt.want == "" && strings.Contains(args, "--kubeconfig") {
+				t.Errorf("Expected kubectl's default config, got kubectl %s", args)
+			}
+			if !strings.Contains(args, tt.want) {
+				t.Errorf("Expected %q, got kubectl %s", tt.want, args)
+			}
+			if !tt.inCluster {
+				return
+			}
+
+			path := strings.Fields(args[strings.Index(args, "--kubeconfig"):])[1]
+			generated, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Expected the in-cluster kubeconfig to exist: %v", err)
+			}
+			for _, want := range []string{"server: https://10.96.0.1:443", "tokenFile: " + filepath.Join(sa, "token"), "certificate-authority: " + filepath.Join(sa, "ca.crt")} {
+				if !strings.Contains(string(generated), want) {
+					t.Errorf("Expected the in-cluster kubeconfig to contain %q, got:\n%s", want, generated)
+				}
+			}
+		})
+	}
+}
+
+func TestInClusterKubeconfigReused(t *testing.T) {
+	sa := t.TempDir()
+	for _, f := range []string{"token", "ca.crt"} {
+		if err := os.WriteFile(filepath.Join(sa, f), []byte("test"), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", f, err)
+		}
+	}
+	defer func(dir string) { serviceAccountDir = dir }(serviceAccountDir)
+	serviceAccountDir = sa
+	t.Setenv("KUBECONFIG", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.96.0.1")
+	first, err := inClusterKubeconfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer RemoveInClusterKubeconfig()
+	if again, _ := inClusterKubeconfig(); again != first {
+		t.Errorf("Expected the kubeconfig %s reused, got %s", first, again)
+	}
+
+	// A moved API server rewrites the same file
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.96.0.2")
+	moved, err := inClusterKubeconfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if moved != first {
+		t.Errorf("Expected the kubeconfig %s rewritten, got %s", first, moved)
+	}
+	generated, err := os.ReadFile(moved)
+	if err != nil {
+		t.Fatalf("Expected the kubeconfig to exist: %v", err)
+	}
+	if !strings.Contains(string(generated), "server: https://10.96.0.2:443") {
+		t.Errorf("Expected the new API server, got:\n%s", generated)
+	}
+}
+
+func TestRemoveInClusterKubeconfig(t *testing.T) {
+	sa := t.TempDir()
+	for _, f := range []string{"token", "ca.crt"} {
+		if err := os.WriteFile(filepath.Join(sa, f), []byte("test"), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", f, err)
+		}
+	}
+	defer func(dir string) { serviceAccountDir = dir }(serviceAccountDir)
+	serviceAccountDir = sa
+	t.Setenv("KUBECONFIG", "")
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.96.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+
+	path, err := inClusterKubeconfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := RemoveInClusterKubeconfig(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected %s removed, got %v", path, err)
+	}
+	if err := RemoveInClusterKubeconfig(); err != nil {
+		t.Errorf("Expected removing twice to succeed, got %v", err)
+	}
+
+	// Written again when next needed
+	again, err := inClusterKubeconfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer RemoveInClusterKubeconfig()
+	if _, err := os.Stat(again); err != nil {
+		t.Errorf("Expected the kubeconfig rewritten: %v", err)
+	}
+}
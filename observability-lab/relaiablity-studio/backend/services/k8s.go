@@ -1,10 +1,125 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/sarikasharma2428-web/reliability-studio/config"
 )
 
-func GetCluster() string {
-	out, _ := exec.Command("kubectl", "get", "pods", "-A", "-o", "json").Output()
-	return string(out)
+// Cluster is one Kubernetes cluster GetClusters queries
+type Cluster struct {
+	Name       string // Tags the cluster's events; also the kube context unless KubeConfig is set
+	KubeConfig string // Optional kubeconfig path, using its current context
+}
+
+// ClusterState is the raw kubectl output from one cluster, or the error
+// kubectl failed with
+type ClusterState struct {
+	Cluster string
+	Raw     string
+	Err     error
+}
+
+var clusters []Cluster
+
+var namespaces config.K8sNamespaces
+
+// SetNamespaces scopes Kubernetes queries. The zero value queries all namespaces.
+func SetNamespaces(n config.K8sNamespaces) {
+	namespaces = n
+}
+
+// NamespaceFor returns the namespace GetClusters should query for service
+func NamespaceFor(service string) (string, error) {
+	return namespaces.For(service)
+}
+
+// SetClusters configures the clusters to query from a comma-separated list of
+// "name" (a context in the default kubeconfig) or "name=/path/to/kubeconfig"
+// entries. Empty queries only kubectl's current context.
+func SetClusters(list string) {
+	var parsed []Cluster
+	for _, entry := range strings.Split(list, ",") {
+		name, path, _ := strings.Cut(strings.TrimSpace(entry), "=")
+		if name == "" {
+			continue
+		}
+		parsed = append(parsed, Cluster{Name: name, KubeConfig: path})
+	}
+	clusters = parsed
+}
+
+// GetCluster lists pods along with the deployments and replicasets needed to
+// spot rollouts, in the default namespace (all namespaces if none is set).
+// Without a kubeconfig set, a pod's service account is used when running in a
+// cluster.
+func GetCluster() (string, error) {
+	return getCluster(context.Background(), Cluster{}, namespaces.Default)
+}
+
+// GetClusters lists namespace (all namespaces if empty) in every configured
+// cluster concurrently. With no clusters configured it returns kubectl's
+// current context, unnamed.
+func GetClusters(ctx context.Context, namespace string) []ClusterState {
+	if len(clusters) == 0 {
+		raw, err := getCluster(ctx, Cluster{}, namespace)
+		return []ClusterState{{Raw: raw, Err: err}}
+	}
+
+	states := make([]ClusterState, len(clusters))
+	var wg sync.WaitGroup
+	for i, c := range clusters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			raw, err := getCluster(ctx, c, namespace)
+			states[i] = ClusterState{Cluster: c.Name, Raw: raw, Err: err}
+		}()
+	}
+	wg.Wait()
+	return states
+}
+
+func getCluster(ctx context.Context, c Cluster, namespace string) (string, error) {
+	args := []string{"get", "pods,deployments,replicasets", "-o", "json"}
+	if namespace == "" {
+		args = append(args, "-A")
+	} else {
+		args = append(args, "-n", namespace)
+	}
+	switch {
+	case c.KubeConfig != "":
+		args = append(args, "--kubeconfig", c.KubeConfig)
+	case c.Name != "":
+		if kubeConfig != "" {
+			args = append(args, "--kubeconfig", kubeConfig)
+		}
+		args = append(args, "--context", c.Name)
+	case kubeConfig != "":
+		args = append(args, "--kubeconfig", kubeConfig)
+	default:
+		path, err := inClusterKubeconfig()
+		if err != nil {
+			log.Printf("Warning: Failed to write in-cluster kubeconfig, using kubectl's default: %v", err)
+		}
+		if path != "" {
+			args = append(args, "--kubeconfig", path)
+		}
+	}
+	out, err := exec.CommandContext(ctx, "kubectl", args...).Output()
+	if err != nil {
+		// kubectl explains failures such as an unreachable cluster on stderr
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("kubectl: %w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("kubectl: %w", err)
+	}
+	return string(out), nil
 }
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetClusterKubectlError(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho 'Unable to connect to the server: dial tcp 10.0.0.1:443: i/o timeout' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(bin, "kubectl"), []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake kubectl: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	SetKubeConfig("/etc/kube/config.yaml")
+	defer SetKubeConfig("")
+
+	raw, err := GetCluster()
+	if err == nil || !strings.Contains(err.Error(), "Unable to connect to the server") {
+		t.Errorf("Expected kubectl's stderr in the error, got %v", err)
+	}
+	if raw != "" {
+		t.Errorf("Expected no output, got %q", raw)
+	}
+}
@@ -1,18 +1,109 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func QueryLogs(query string) string {
-	url := "http://loki:3100/loki/api/v1/query?query=" + url.QueryEscape(query)
-	resp, err := http.Get(url)
+// QueryLogs returns the log lines matching query
+func QueryLogs(ctx context.Context, query string) (string, error) {
+	body, err := fetch(ctx, lokiPool, "/loki/api/v1/query?query="+url.QueryEscape(query))
 	if err != nil {
-		return err.Error()
+		return "", err
 	}
-	defer resp.Body.Close()
-	body, _ := io.ReadAll(resp.Body)
-	return string(body)
+	return body, nil
+}
+
+// QueryLogsRange returns the log lines matching query between start and end
+func QueryLogsRange(ctx context.Context, query string, start, end time.Time) (string, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+
+	body, err := fetch(ctx, lokiPool, "/loki/api/v1/query_range?"+params.Encode())
+	if err != nil {
+		return "", err
+	}
+	return body, nil
+}
+
+// StreamLogsRange is QueryLogsRange for up to limit lines, returning the
+// body unread so large responses can be decoded as they arrive, e.g. by
+// analysis.AnalyzeLogStream. The caller must close it.
+func StreamLogsRange(ctx context.Context, query string, start, end time.Time, limit int) (io.ReadCloser, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	params.Set("limit", strconv.Itoa(limit))
+
+	return failover(ctx, lokiPool, func(baseURL string) (io.ReadCloser, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/loki/api/v1/query_range?"+params.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		return open(ctx, lokiPool.target, req)
+	})
+}
+
+// LogVolume estimates how many log lines query matches between start and end
+// from Loki's index stats, without pulling the lines. Only the query's stream
+// selector is used, so line filters aren't taken into account.
+func LogVolume(ctx context.Context, query string, start, end time.Time) (int64, error) {
+	selector, ok := streamSelector(query)
+	if !ok {
+		return 0, fmt.Errorf("no stream selector in %q", query)
+	}
+	params := url.Values{}
+	params.Set("query", selector)
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+
+	body, err := fetch(ctx, lokiPool, "/loki/api/v1/index/stats?"+params.Encode())
+	if err != nil {
+		return 0, err
+	}
+	var stats struct {
+		Entries *int64 `json:"entries"`
+	}
+	if err := json.Unmarshal([]byte(body), &stats); err != nil || stats.Entries == nil {
+		return 0, fmt.Errorf("unexpected index stats response")
+	}
+	return *stats.Entries, nil
+}
+
+// streamSelector returns the first {...} stream selector in a LogQL query.
+// Braces inside quoted label values don't end the selector.
+func streamSelector(query string) (string, bool) {
+	start := strings.Index(query, "{")
+	if start < 0 {
+		return "", false
+	}
+	var quote rune
+	escaped := false
+	for i, c := range query[start:] {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0 && c == '\\':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`':
+			quote = c
+		case c == '}':
+			return query[start : start+i+1], true
+		}
+	}
+	return "", false
 }
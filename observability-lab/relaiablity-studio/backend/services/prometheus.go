@@ -1,18 +1,119 @@
 package services
 
 import (
-	"io"
-	"net/http"
+	"context"
+	"fmt"
 	"net/url"
+	"regexp"
+	"strconv"
+	"time"
 )
 
-func QueryMetrics(query string) string {
-	url := "http://prometheus:9090/api/v1/query?query=" + url.QueryEscape(query)
-	resp, err := http.Get(url)
+// MaxRangePoints is the most points Prometheus returns per series before rejecting a range query
+const MaxRangePoints = 11000
+
+// DefaultQueryTimeout bounds server-side evaluation of every metric query. It
+// is shorter than the upstream client timeout so Prometheus gives up first.
+const DefaultQueryTimeout = 25 * time.Second
+
+var queryTimeout = DefaultQueryTimeout
+
+// SetQueryTimeout sets the timeout Prometheus is asked to enforce on query
+// evaluation. d <= 0 restores DefaultQueryTimeout.
+func SetQueryTimeout(d time.Duration) {
+	if d <= 0 {
+		d = DefaultQueryTimeout
+	}
+	queryTimeout = d
+}
+
+// queryParams starts the parameters shared by every metric query
+func queryParams(query string) url.Values {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("timeout", strconv.FormatFloat(queryTimeout.Seconds(), 'f', -1, 64))
+	return params
+}
+
+// ValidateRange rejects a step that doesn't fit the window, either because it
+// is not positive or because it would produce more points than Prometheus allows
+func ValidateRange(start, end time.Time, step time.Duration) error {
+	if step <= 0 {
+		return fmt.Errorf("step must be positive")
+	}
+	if points := int64(end.Sub(start)/step) + 1; points > MaxRangePoints {
+		return fmt.Errorf("step %s over %s yields %d points (max %d)", step, end.Sub(start), points, MaxRangePoints)
+	}
+	return nil
+}
+
+// recordingRulePattern matches the level:metric:operations naming convention
+var recordingRulePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*:[a-zA-Z0-9_:]+$`)
+
+// IsRecordingRule reports whether query is a bare recording-rule name such as
+// job:http_error_rate:ratio. Recording rules are precomputed, so they are
+// always read as an instant vector.
+func IsRecordingRule(query string) bool {
+	return recordingRulePattern.MatchString(query)
+}
+
+// QueryMetrics evaluates query as an instant vector now. Like the other query
+// functions, it returns the upstream body, which may report a failed query,
+// and an error only when no upstream answered.
+func QueryMetrics(ctx context.Context, query string) (string, error) {
+	if remoteRead {
+		return remoteReadInstant(ctx, query, time.Now())
+	}
+	body, err := fetch(ctx, prometheusPool, "/api/v1/query?"+queryParams(query).Encode())
+	if err != nil {
+		return "", err
+	}
+	return body, nil
+}
+
+// QueryMetricsAt evaluates query as an instant vector at t
+func QueryMetricsAt(ctx context.Context, query string, t time.Time) (string, error) {
+	if remoteRead {
+		return remoteReadInstant(ctx, query, t)
+	}
+	params := queryParams(query)
+	params.Set("time", strconv.FormatInt(t.Unix(), 10))
+
+	body, err := fetch(ctx, prometheusPool, "/api/v1/query?"+params.Encode())
+	if err != nil {
+		return "", err
+	}
+	return body, nil
+}
+
+// QueryMetricsRange evaluates query over [start, end] at the given step, returning a matrix
+func QueryMetricsRange(ctx context.Context, query string, start, end time.Time, step time.Duration) (string, error) {
+	if remoteRead {
+		return remoteReadRange(ctx, query, start, end, step)
+	}
+	params := queryParams(query)
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+
+	body, err := fetch(ctx, prometheusPool, "/api/v1/query_range?"+params.Encode())
+	if err != nil {
+		return "", err
+	}
+	return body, nil
+}
+
+// QueryExemplars returns the exemplars attached to the series query selects
+// over [start, end]. Exemplars link a sample to the trace that produced it.
+func QueryExemplars(ctx context.Context, query string, start, end time.Time) (string, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+
+	body, err := fetch(ctx, prometheusPool, "/api/v1/query_exemplars?"+params.Encode())
 	if err != nil {
-		return err.Error()
+		return "", err
 	}
-	defer resp.Body.Close()
-	body, _ := io.ReadAll(resp.Body)
-	return string(body)
+	return body, nil
 }
@@ -116,11 +116,15 @@ func (p *PrometheusClient) QueryRange(ctx context.Context, query string, start,
 
 // GetErrorRate calculates the error rate for a service
 func (p *PrometheusClient) GetErrorRate(ctx context.Context, service string) (float64, error) {
+	aggregation, err := templates.AggregationFor(service)
+	if err != nil {
+		return 0, err
+	}
 	query := fmt.Sprintf(`
-        sum(rate(http_requests_total{service="%s",status=~"5.."}[5m]))
+        %[1]s(rate(http_requests_total{service="%[2]s",status=~"5.."}[5m]))
         /
-        sum(rate(http_requests_total{service="%s"}[5m]))
-    `, service, service)
+        %[1]s(rate(http_requests_total{service="%[2]s"}[5m]))
+    `, aggregation, service)
 
 	resp, err := p.Query(ctx, query)
 	if err != nil {
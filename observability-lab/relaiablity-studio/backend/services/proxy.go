@@ -0,0 +1,42 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// proxyClients holds a client per target that goes through a configured
+// proxy. Other targets use upstreamClient, whose default transport honours
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+var proxyClients = map[string]*http.Client{}
+
+// SetProxies routes each upstream through the given HTTP proxy URL. An empty
+// value falls back to the proxy environment variables. On error no proxies
+// are changed.
+func SetProxies(prometheus, loki, tempo string) error {
+	clients := make(map[string]*http.Client)
+	for target, raw := range map[string]string{"prometheus": prometheus, "loki": loki, "tempo": tempo} {
+		if raw == "" {
+			continue
+		}
+		proxy, err := url.Parse(raw)
+		if err != nil || proxy.Host == "" {
+			return fmt.Errorf("invalid %s proxy URL %q", target, raw)
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxy)
+		clients[target] = &http.Client{Timeout: upstreamClient.Timeout, Transport: transport}
+	}
+	proxyClients = clients
+	return nil
+}
+
+// clientFor returns the HTTP client for an upstream target
+func clientFor(target string) *http.Client {
+	if c, ok := proxyClients[target]; ok {
+		return c
+	}
+	return upstreamClient
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Metric backends, selecting how QueryMetrics, QueryMetricsAt and
+// QueryMetricsRange reach Prometheus
+const (
+	MetricsBackendQuery      = "query"       // The HTTP query API
+	MetricsBackendRemoteRead = "remote_read" // The remote-read protocol, e.g. for Thanos or Cortex
+)
+
+// RemoteReadLookback is how old the latest sample of a series may be and
+// still count at an evaluation time, matching Prometheus' staleness
+const RemoteReadLookback = 5 * time.Minute
+
+var remoteRead bool
+
+// SetMetricsBackend selects how metric queries are run. Remote read only
+// returns raw samples, so queries must be series selectors (typically
+// recording rules); anything else is rejected as bad_data. An empty or
+// unknown backend restores MetricsBackendQuery.
+func SetMetricsBackend(backend string) {
+	remoteRead = backend == MetricsBackendRemoteRead
+}
+
+// remoteSeries is one series returned by remote read
+type remoteSeries struct {
+	labels  map[string]string
+	samples []remoteSample
+}
+
+type remoteSample struct {
+	t int64 // Unix milliseconds
+	v float64
+}
+
+// remoteReadInstant answers an instant query with the latest sample of each
+// series within the lookback of t, as /api/v1/query would. A query remote
+// read can't evaluate is answered with an error body; a failed read is an
+// error.
+func remoteReadInstant(ctx context.Context, query string, t time.Time) (string, error) {
+	matchers, err := parseSelector(query)
+	if err != nil {
+		return remoteReadError(err), nil
+	}
+	series, err := readSeries(ctx, matchers, t.Add(-RemoteReadLookback), t)
+	if err != nil {
+		return "", err
+	}
+	var result []map[string]any
+	for _, s := range series {
+		if sample, ok := s.at(t); ok {
+			result = append(result, map[string]any{"metric": s.labels, "value": promSample(sample.v, t)})
+		}
+	}
+	return remoteReadResponse("vector", result), nil
+}
+
+// remoteReadRange answers a range query with the latest sample of each
+// series at every step, as /api/v1/query_range would
+func remoteReadRange(ctx context.Context, query string, start, end time.Time, step time.Duration) (string, error) {
+	if err := ValidateRange(start, end, step); err != nil {
+		return remoteReadError(err), nil
+	}
+	matchers, err := parseSelector(query)
+	if err != nil {
+		return remoteReadError(err), nil
+	}
+	series, err := readSeries(ctx, matchers, start.Add(-RemoteReadLookback), end)
+	if err != nil {
+		return "", err
+	}
+	var result []map[string]any
+	for _, s := range series {
+		var values [][2]any
+		for t := start; !t.After(end); t = t.Add(step) {
+			if sample, ok := s.at(t); ok {
+				values = append(values, promSample(sample.v, t))
+			}
+		}
+		if len(values) > 0 {
+			result = append(result, map[string]any{"metric": s.labels, "values": values})
+		}
+	}
+	return remoteReadResponse("matrix", result), nil
+}
+
+// at returns the latest sample at or before t within the lookback
+func (s remoteSeries) at(t time.Time) (remoteSample, bool) {
+	ms := t.UnixMilli()
+	i := sort.Search(len(s.samples), func(i int) bool { return s.samples[i].t > ms }) - 1
+	if i < 0 || ms-s.samples[i].t > RemoteReadLookback.Milliseconds() {
+		return remoteSample{}, false
+	}
+	return s.samples[i], true
+}
+
+func promSample(v float64, t time.Time) [2]any {
+	return [2]any{float64(t.Unix()), strconv.FormatFloat(v, 'f', -1, 64)}
+}
+
+func remoteReadResponse(resultType string, result []map[string]any) string {
+	if result == nil {
+		result = []map[string]any{}
+	}
+	body, _ := json.Marshal(map[string]any{
+		"status": "success",
+		"data":   map[string]any{"resultType": resultType, "result": result},
+	})
+	return string(body)
+}
+
+// remoteReadError reports err the way the query API reports a failed query
+func remoteReadError(err error) string {
+	body, _ := json.Marshal(map[string]string{"status": "error", "errorType": "remote_read", "error": err.Error()})
+	return string(body)
+}
+
+// readSeries fetches the raw samples of the series matchers select over
+// [start, end] from /api/v1/read
+func readSeries(ctx context.Context, matchers []labelMatcher, start, end time.Time) ([]remoteSeries, error) {
+	req := snappyEncode(encodeReadRequest(start.UnixMilli(), end.UnixMilli(), matchers))
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	body, err := failover(ctx, prometheusPool, func(baseURL string) (string, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/api/v1/read", bytes.NewReader(req))
+		if err != nil {
+			return "", fmt.Errorf("failed to create request: %w", err)
+		}
+		httpReq.Header.Set("Content-Type", "application/x-protobuf")
+		httpReq.Header.Set("Content-Encoding", "snappy")
+		httpReq.Header.Set("X-Prometheus-Remote-Read-Version", "0.1.0")
+		return send(ctx, prometheusPool.target, httpReq)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := snappyDecode([]byte(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote read response: %s", strings.TrimSpace(truncate(body, 200)))
+	}
+	return decodeReadResponse(raw)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// Remote read label matcher types
+const (
+	matchEqual     = 0
+	matchNotEqual  = 1
+	matchRegexp    = 2
+	matchNotRegexp = 3
+)
+
+type labelMatcher struct {
+	typ         int
+	name, value string
+}
+
+var (
+	metricNamePattern   = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*`)
+	labelMatcherPattern = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*("(?:[^"\\]|\\.)*")\s*(,|$)`)
+)
+
+// parseSelector parses a series selector, name{label="value",...}, into
+// remote read matchers. Remote read can't evaluate functions or operators.
+func parseSelector(query string) ([]labelMatcher, error) {
+	rest := strings.TrimSpace(query)
+	var matchers []labelMatcher
+	if name := metricNamePattern.FindString(rest); name != "" {
+		matchers = append(matchers, labelMatcher{matchEqual, "__name__", name})
+		rest = strings.TrimSpace(rest[len(name):])
+	}
+	if strings.HasPrefix(rest, "{") && strings.HasSuffix(rest, "}") {
+		body := strings.TrimSpace(rest[1 : len(rest)-1])
+		for body != "" {
+			m := labelMatcherPattern.FindStringSubmatch(body)
+			if m == nil {
+				return nil, fmt.Errorf("remote read only supports series selectors, got %q", query)
+			}
+			value, err := strconv.Unquote(m[3])
+			if err != nil {
+				return nil, fmt.Errorf("invalid label value %s in %q", m[3], query)
+			}
+			typ := map[string]int{"=": matchEqual, "!=": matchNotEqual, "=~": matchRegexp, "!~": matchNotRegexp}[m[2]]
+			matchers = append(matchers, labelMatcher{typ, m[1], value})
+			body = body[len(m[0]):]
+		}
+		rest = ""
+	}
+	if rest != "" || len(matchers) == 0 {
+		return nil, fmt.Errorf("remote read only supports series selectors, got %q", query)
+	}
+	return matchers, nil
+}
+
+// encodeReadRequest builds a ReadRequest with a single query:
+// ReadRequest 1 = queries; Query 1 = start_timestamp_ms, 2 = end_timestamp_ms,
+// 3 = matchers; LabelMatcher 1 = type, 2 = name, 3 = value
+func encodeReadRequest(startMs, endMs int64, matchers []labelMatcher) []byte {
+	var q []byte
+	q = protowire.AppendTag(q, 1, protowire.VarintType)
+	q = protowire.AppendVarint(q, uint64(startMs))
+	q = protowire.AppendTag(q, 2, protowire.VarintType)
+	q = protowire.AppendVarint(q, uint64(endMs))
+	for _, m := range matchers {
+		var lm []byte
+		lm = protowire.AppendTag(lm, 1, protowire.VarintType)
+		lm = protowire.AppendVarint(lm, uint64(m.typ))
+		lm = protowire.AppendTag(lm, 2, protowire.BytesType)
+		lm = protowire.AppendString(lm, m.name)
+		lm = protowire.AppendTag(lm, 3, protowire.BytesType)
+		lm = protowire.AppendString(lm, m.value)
+
+		q = protowire.AppendTag(q, 3, protowire.BytesType)
+		q = protowire.AppendBytes(q, lm)
+	}
+	req := protowire.AppendTag(nil, 1, protowire.BytesType)
+	return protowire.AppendBytes(req, q)
+}
+
+// decodeReadResponse reads the series of a ReadResponse:
+// ReadResponse 1 = results; QueryResult 1 = timeseries; TimeSeries 1 = labels,
+// 2 = samples; Label 1 = name, 2 = value; Sample 1 = value, 2 = timestamp
+func decodeReadResponse(data []byte) ([]remoteSeries, error) {
+	var series []remoteSeries
+	err := protoFields(data, 1, func(result []byte) error {
+		return protoFields(result, 1, func(ts []byte) error {
+			s := remoteSeries{labels: make(map[string]string)}
+			err := protoFields(ts, 1, func(label []byte) error {
+				var name, value string
+				err := protoScalars(label, func(num protowire.Number, v []byte) {
+					if num == 1 {
+						name = string(v)
+					} else if num == 2 {
+						value = string(v)
+					}
+				})
+				s.labels[name] = value
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			err = protoFields(ts, 2, func(sample []byte) error {
+				var sm remoteSample
+				err := protoScalars(sample, func(num protowire.Number, v []byte) {
+					if num == 1 {
+						bits, _ := protowire.ConsumeFixed64(v)
+						sm.v = math.Float64frombits(bits)
+					} else if num == 2 {
+						t, _ := protowire.ConsumeVarint(v)
+						sm.t = int64(t)
+					}
+				})
+				s.samples = append(s.samples, sm)
+				return err
+			})
+			sort.Slice(s.samples, func(i, j int) bool { return s.samples[i].t < s.samples[j].t })
+			series = append(series, s)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote read response: %w", err)
+	}
+	return series, nil
+}
+
+// protoFields calls fn with the contents of every want field in a message
+func protoFields(data []byte, want protowire.Number, fn func([]byte) error) error {
+	var err error
+	scanErr := protoScalars(data, func(num protowire.Number, v []byte) {
+		if num == want && err == nil {
+			err = fn(v)
+		}
+	})
+	if scanErr != nil {
+		return scanErr
+	}
+	return err
+}
+
+// protoScalars calls fn with every field of a message: the contents of
+// length-delimited fields, the raw encoding of the others
+func protoScalars(data []byte, fn func(protowire.Number, []byte)) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		var value []byte
+		if typ == protowire.BytesType {
+			v, m := protowire.ConsumeBytes(data)
+			if m < 0 {
+				return protowire.ParseError(m)
+			}
+			value, n = v, m
+		} else {
+			if n = protowire.ConsumeFieldValue(num, typ, data); n < 0 {
+				return protowire.ParseError(n)
+			}
+			value = data[:n]
+		}
+		data = data[n:]
+		fn(num, value)
+	}
+	return nil
+}
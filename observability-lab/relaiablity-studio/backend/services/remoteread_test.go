@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// readResponse encodes a ReadResponse holding one series with the given samples
+func readResponse(labels map[string]string, samples []remoteSample) []byte {
+	var ts []byte
+	for name, value := range labels {
+		var l []byte
+		l = protowire.AppendTag(l, 1, protowire.BytesType)
+		l = protowire.AppendString(l, name)
+		l = protowire.AppendTag(l, 2, protowire.BytesType)
+		l = protowire.AppendString(l, value)
+		ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, l)
+	}
+	for _, s := range samples {
+		var sm []byte
+		sm = protowire.AppendTag(sm, 1, protowire.Fixed64Type)
+		sm = protowire.AppendFixed64(sm, math.Float64bits(s.v))
+		sm = protowire.AppendTag(sm, 2, protowire.VarintType)
+		sm = protowire.AppendVarint(sm, uint64(s.t))
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sm)
+	}
+	result := protowire.AppendTag(nil, 1, protowire.BytesType)
+	result = protowire.AppendBytes(result, ts)
+	resp := protowire.AppendTag(nil, 1, protowire.BytesType)
+	return protowire.AppendBytes(resp, result)
+}
+
+func TestRemoteReadBackend(t *testing.T) {
+	start := time.Unix(1705284000, 0)
+	var gotMatchers []labelMatcher
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/read" {
+			t.Errorf("Expected POST /api/v1/read, got %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Content-Encoding") != "snappy" {
+			t.Errorf("Expected a snappy-encoded request, got %q", r.Header.Get("Content-Encoding"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		req, err := snappyDecode(body)
+		if err != nil {
+			t.Fatalf("Unexpected error decoding request: %v", err)
+		}
+		gotMatchers = nil
+		protoFields(req, 1, func(query []byte) error {
+			return protoFields(query, 3, func(matcher []byte) error {
+				var m labelMatcher
+				protoScalars(matcher, func(num protowire.Number, v []byte) {
+					switch num {
+					case 1:
+						typ, _ := protowire.ConsumeVarint(v)
+						m.typ = int(typ)
+					case 2:
+						m.name = string(v)
+					case 3:
+						m.value = string(v)
+					}
+				})
+				gotMatchers = append(gotMatchers, m)
+				return nil
+			})
+		})
+
+		w.Write(snappyEncode(readResponse(
+			map[string]string{"__name__": "job:latency_p95:5m", "service": "checkout"},
+			[]remoteSample{
+				{t: start.UnixMilli(), v: 0.2},
+				{t: start.Add(30 * time.Second).UnixMilli(), v: 0.4},
+				{t: start.Add(60 * time.Second).UnixMilli(), v: 1.5},
+			},
+		)))
+	}))
+	defer server.Close()
+	SetUpstreams(server.URL, "", "")
+	defer SetUpstreams("", "", "")
+	SetMetricsBackend(MetricsBackendRemoteRead)
+	defer SetMetricsBackend("")
+
+	body, err := QueryMetricsRange(context.Background(), `job:latency_p95:5m{service="checkout"}`, start, start.Add(time.Minute), 30*time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	res, err := analysis.AnalyzeMetrics(body)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []labelMatcher{{matchEqual, "__name__", "job:latency_p95:5m"}, {matchEqual, "service", "checkout"}}
+	if len(gotMatchers) != len(expected) || gotMatchers[0] != expected[0] || gotMatchers[1] != expected[1] {
+		t.Errorf("Expected matchers %v, got %v", expected, gotMatchers)
+	}
+	if len(res.Series) != 3 || res.Series[1].Value != 0.4 || res.Latency != 1.5 {
+		t.Errorf("Expected the 3 samples as a series ending at 1.5, got %+v", res)
+	}
+
+	if body, err = QueryMetricsAt(context.Background(), "job:latency_p95:5m", start.Add(45*time.Second)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	res, err = analysis.AnalyzeMetrics(body)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.Latency != 0.4 {
+		t.Errorf("Expected the latest sample at the evaluation time, 0.4, got %v", res.Latency)
+	}
+
+	if body, err = QueryMetrics(context.Background(), `sum(rate(http_requests_total[5m]))`); err != nil {
+		t.Fatalf("Expected a query remote read can't evaluate answered with an error body, got %v", err)
+	}
+	_, err = analysis.AnalyzeMetrics(body)
+	var apiErr *analysis.APIError
+	if !errors.As(err, &apiErr) {
+		t.Errorf("Expected an API error for a query remote read can't evaluate, got %v", err)
+	}
+}
+
+func TestSnappyDecode(t *testing.T) {
+	// "a" as a literal, then a 15-byte copy at offset 1
+	got, err := snappyDecode([]byte{0x10, 0x00, 'a', 0x3a, 0x01, 0x00})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(got) != "aaaaaaaaaaaaaaaa" {
+		t.Errorf("Expected 16 a's, got %q", got)
+	}
+
+	long := make([]byte, 70000)
+	for i := range long {
+		long[i] = byte(i)
+	}
+	if got, err := snappyDecode(snappyEncode(long)); err != nil || string(got) != string(long) {
+		t.Errorf("Expected a round trip of %d bytes, got %d bytes, %v", len(long), len(got), err)
+	}
+	if _, err := snappyDecode([]byte{0x10, 0x00, 'a', 0x3a, 0x05, 0x00}); err == nil {
+		t.Error("Expected an error for a copy before the start of the output")
+	}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// replicaCooldown is how long a replica that failed is skipped
+const replicaCooldown = 30 * time.Second
+
+// replicaPool round-robins requests across the base URLs of one upstream,
+// failing over to the next replica and skipping dead ones for a cooldown
+type replicaPool struct {
+	target string
+	urls   []string
+
+	mu        sync.Mutex
+	next      int
+	deadUntil []time.Time
+}
+
+// newReplicaPool builds a pool from a comma-separated list of base URLs
+func newReplicaPool(target, baseURLs string) *replicaPool {
+	var urls []string
+	for _, u := range strings.Split(baseURLs, ",") {
+		if u = strings.TrimRight(strings.TrimSpace(u), "/"); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return &replicaPool{target: target, urls: urls, deadUntil: make([]time.Time, len(urls))}
+}
+
+// order returns replica indexes to try, starting at the next one in rotation.
+// Live replicas come first; dead ones are only tried if everything is dead.
+func (p *replicaPool) order() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.urls) == 0 {
+		return nil
+	}
+	now := time.Now()
+	var live, dead []int
+	for i := range p.urls {
+		idx := (p.next + i) % len(p.urls)
+		if now.Before(p.deadUntil[idx]) {
+			dead = append(dead, idx)
+		} else {
+			live = append(live, idx)
+		}
+	}
+	p.next = (p.next + 1) % len(p.urls)
+	return append(live, dead...)
+}
+
+func (p *replicaPool) markDead(idx int) {
+	p.mu.Lock()
+	p.deadUntil[idx] = time.Now().Add(replicaCooldown)
+	p.mu.Unlock()
+}
+
+func (p *replicaPool) markAlive(idx int) {
+	p.mu.Lock()
+	p.deadUntil[idx] = time.Time{}
+	p.mu.Unlock()
+}
+
+// fetch GETs path from the pool, failing over until a replica answers
+func fetch(ctx context.Context, pool *replicaPool, path string) (string, error) {
+	return failover(ctx, pool, func(baseURL string) (string, error) {
+		return get(ctx, pool.target, baseURL+path)
+	})
+}
+
+// failover calls request with each replica's base URL until one answers
+func failover[T any](ctx context.Context, pool *replicaPool, request func(baseURL string) (T, error)) (T, error) {
+	var zero T
+	lastErr := fmt.Errorf("no %s replicas configured", pool.target)
+	for _, idx := range pool.order() {
+		body, err := request(pool.urls[idx])
+		if err == nil {
+			pool.markAlive(idx)
+			return body, nil
+		}
+		if ctx.Err() != nil {
+			return zero, ctx.Err()
+		}
+		pool.markDead(idx)
+		lastErr = err
+	}
+	return zero, lastErr
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReplicaPoolDistributesAndSkipsDead(t *testing.T) {
+	var hitsA, hitsB int32
+	var failB atomic.Bool
+	a := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hitsA, 1)
+		w.Write([]byte("a"))
+	}))
+	defer a.Close()
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hitsB, 1)
+		if failB.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("b"))
+	}))
+	defer b.Close()
+
+	pool := newReplicaPool("prometheus", a.URL+", "+b.URL)
+
+	for i := 0; i < 4; i++ {
+		if _, err := fetch(context.Background(), pool, "/api/v1/query"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if hitsA != 2 || hitsB != 2 {
+		t.Errorf("Expected requests split 2/2, got a=%d b=%d", hitsA, hitsB)
+	}
+
+	failB.Store(true)
+	for i := 0; i < 4; i++ {
+		body, err := fetch(context.Background(), pool, "/api/v1/query")
+		if err != nil || body != "a" {
+			t.Fatalf("Expected failover to a, got %q (err=%v)", body, err)
+		}
+	}
+	// b fails once, then sits out its cooldown
+	if hitsB != 3 {
+		t.Errorf("Expected dead replica to be skipped after one failure, got %d extra hits", hitsB-2)
+	}
+	if hitsA != 6 {
+		t.Errorf("Expected a to serve every request while b is dead, got %d", hitsA)
+	}
+}
+
+func TestReplicaPoolAllDead(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	pool := newReplicaPool("loki", down.URL)
+	if _, err := fetch(context.Background(), pool, "/ready"); err == nil {
+		t.Error("Expected an error when every replica fails")
+	}
+	// A lone dead replica is still retried rather than giving up outright
+	if _, err := fetch(context.Background(), pool, "/ready"); err == nil {
+		t.Error("Expected an error on retry")
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// UpstreamCheck is the result of probing one upstream at startup
+type UpstreamCheck struct {
+	Target string
+	Err    error
+}
+
+// CheckUpstreams probes the readiness endpoint of Prometheus, Loki and Tempo
+// once each. A pool is reachable if any of its replicas answers.
+func CheckUpstreams(ctx context.Context) []UpstreamCheck {
+	probes := []struct {
+		pool *replicaPool
+		path string
+	}{
+		{prometheusPool, "/-/ready"},
+		{lokiPool, "/ready"},
+		{tempoPool, "/ready"},
+	}
+
+	checks := make([]UpstreamCheck, len(probes))
+	for i, p := range probes {
+		_, err := fetch(ctx, p.pool, p.path)
+		checks[i] = UpstreamCheck{Target: p.pool.target, Err: err}
+	}
+	return checks
+}
+
+// SelfCheck probes every upstream and logs the results. When failFast is
+// set it returns an error if any upstream named in required is unreachable.
+func SelfCheck(ctx context.Context, required []string, failFast bool) error {
+	var unreachable []string
+	for _, c := range CheckUpstreams(ctx) {
+		if c.Err == nil {
+			log.Printf("✅ %s is reachable", c.Target)
+			continue
+		}
+		log.Printf("Warning: %s is unreachable: %v", c.Target, c.Err)
+		for _, r := range required {
+			if strings.TrimSpace(r) == c.Target {
+				unreachable = append(unreachable, c.Target)
+			}
+		}
+	}
+
+	if failFast && len(unreachable) > 0 {
+		return fmt.Errorf("required upstreams unreachable: %s", strings.Join(unreachable, ", "))
+	}
+	return nil
+}
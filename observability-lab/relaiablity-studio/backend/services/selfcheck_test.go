@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSelfCheckFailFast(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ready"))
+	}))
+	defer up.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	SetUpstreams(up.URL, down.URL, up.URL)
+	defer SetUpstreams("", "", "")
+
+	checks := CheckUpstreams(context.Background())
+	for _, c := range checks {
+		if reachable := c.Err == nil; reachable != (c.Target != "loki") {
+			t.Errorf("Expected only loki unreachable, got %s: %v", c.Target, c.Err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		required []string
+		failFast bool
+		fails    bool
+	}{
+		{"required upstream down", []string{"prometheus", "loki"}, true, true},
+		{"optional upstream down", []string{"prometheus"}, true, false},
+		{"fail-fast disabled", []string{"loki"}, false, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := SelfCheck(context.Background(), tc.required, tc.failFast)
+			if (err != nil) != tc.fails {
+				t.Fatalf("Expected failure=%v, got %v", tc.fails, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), "loki") {
+				t.Errorf("Expected the error to name loki, got %v", err)
+			}
+		})
+	}
+}
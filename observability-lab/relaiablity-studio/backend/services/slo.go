@@ -1,8 +1,58 @@
 package services
 
+import "github.com/sarikasharma2428-web/reliability-studio/config"
+
+// SLOInsufficientTraffic is the status of an SLO that would be degraded but
+// saw fewer requests than the minimum in its window
+const SLOInsufficientTraffic = "insufficient_traffic"
+
+// DefaultTrafficQuery counts a service's requests over an SLO window.
+// ${SERVICE}, ${WINDOW} and ${AGGREGATION}, the aggregation function the
+// service's query template chooses, are substituted.
+const DefaultTrafficQuery = `${AGGREGATION}(increase(http_requests_total{service="${SERVICE}"}[${WINDOW}]))`
+
+var (
+	minTraffic   float64
+	trafficQuery = DefaultTrafficQuery
+	templates    config.QueryTemplates
+)
+
+// SetQueryTemplates sets the per-service query templates whose aggregation
+// function the SLO traffic and error-rate queries use. nil uses
+// config.DefaultAggregation for every service.
+func SetQueryTemplates(t config.QueryTemplates) {
+	templates = t
+}
+
+// SetMinTraffic sets the requests an SLO's service needs in the window before
+// the SLO can be warning or critical, counted by query (empty uses
+// DefaultTrafficQuery). Zero disables the check.
+func SetMinTraffic(requests float64, query string) {
+	if query == "" {
+		query = DefaultTrafficQuery
+	}
+	minTraffic, trafficQuery = requests, query
+}
+
+// CalculateSLO grades an error rate given in percent (0-100)
 func CalculateSLO(errorRate float64) string {
 	if errorRate > 1 {
 		return "degraded"
 	}
 	return "healthy"
 }
+
+// ObjectivePercent normalizes an SLO objective to percent. Objectives may be
+// written as a fraction (0.999) or a percentage (99.9); values up to 1 are
+// treated as fractions.
+func ObjectivePercent(objective float64) float64 {
+	if objective <= 1 {
+		return objective * 100
+	}
+	return objective
+}
+
+// ErrorBudgetPercent is the error rate, in percent, that an objective allows
+func ErrorBudgetPercent(objective float64) float64 {
+	return 100 - ObjectivePercent(objective)
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/utils"
+)
+
+// ServiceBudget is one row of the fleet-wide error budget table
+type ServiceBudget struct {
+	Service              string  `json:"service"`
+	SLO                  string  `json:"slo"`
+	TargetPercentage     float64 `json:"target_percentage"`
+	CurrentPercentage    float64 `json:"current_percentage"`
+	ErrorBudgetRemaining float64 `json:"error_budget_remaining"`
+	BurnRate             float64 `json:"burn_rate"`
+
+	// ComputedAt is when the SLO was last calculated and DataAgeSeconds how
+	// long ago that was; both are omitted if it never was
+	ComputedAt     *time.Time `json:"computed_at,omitempty"`
+	DataAgeSeconds float64    `json:"data_age_seconds,omitempty"`
+}
+
+// MarshalJSON rounds the percentages, budget and burn rate to the configured
+// precision
+func (b ServiceBudget) MarshalJSON() ([]byte, error) {
+	type plain ServiceBudget
+	p := plain(b)
+	p.CurrentPercentage = utils.Round(p.CurrentPercentage)
+	p.ErrorBudgetRemaining = utils.Round(p.ErrorBudgetRemaining)
+	p.BurnRate = utils.Round(p.BurnRate)
+	return json.Marshal(p)
+}
+
+// SkippedService is a monitored service left out of the budget table
+type SkippedService struct {
+	Service string `json:"service"`
+	Note    string `json:"note"`
+}
+
+// FleetBudget lists every service's remaining error budget, lowest first
+type FleetBudget struct {
+	Services []ServiceBudget  `json:"services"`
+	Skipped  []SkippedService `json:"skipped,omitempty"`
+}
+
+// Version identifies the budget's contents apart from the age of its data,
+// which changes every second
+func (f FleetBudget) Version() string {
+	stable := f
+	stable.Services = make([]ServiceBudget, len(f.Services))
+	for i, row := range f.Services {
+		row.DataAgeSeconds = 0
+		stable.Services[i] = row
+	}
+	encoded, _ := json.Marshal(stable)
+	return string(encoded)
+}
+
+// GetFleetBudget computes the error budget table for every monitored service
+func (s *SLOService) GetFleetBudget(ctx context.Context) (FleetBudget, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name FROM services ORDER BY name`)
+	if err != nil {
+		return FleetBudget{}, fmt.Errorf("failed to query services: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return FleetBudget{}, fmt.Errorf("failed to read services: %w", err)
+	}
+
+	slos, err := s.GetAllSLOs(ctx)
+	if err != nil {
+		return FleetBudget{}, err
+	}
+	return BuildFleetBudget(names, slos), nil
+}
+
+// BuildFleetBudget reports each service by its most-depleted SLO. Burn rate is
+// the observed error rate over the error rate the objective allows, so 1.0
+// spends the budget exactly over the SLO window. Rows carry the age of the
+// SLO's last calculation.
+func BuildFleetBudget(serviceNames []string, slos []SLO) FleetBudget {
+	now := time.Now()
+	worst := make(map[string]SLO)
+	for _, slo := range slos {
+		if current, ok := worst[slo.ServiceName]; !ok || slo.ErrorBudgetRemaining < current.ErrorBudgetRemaining {
+			worst[slo.ServiceName] = slo
+		}
+	}
+
+	fleet := FleetBudget{Services: []ServiceBudget{}}
+	for _, name := range serviceNames {
+		slo, ok := worst[name]
+		if !ok {
+			fleet.Skipped = append(fleet.Skipped, SkippedService{Service: name, Note: "no SLO configured"})
+			continue
+		}
+
+		burnRate := 0.0
+		if allowed := ErrorBudgetPercent(slo.TargetPercentage); allowed > 0 {
+			burnRate = (100 - slo.CurrentPercentage) / allowed
+		}
+
+		row := ServiceBudget{
+			Service:              name,
+			SLO:                  slo.Name,
+			TargetPercentage:     ObjectivePercent(slo.TargetPercentage),
+			CurrentPercentage:    slo.CurrentPercentage,
+			ErrorBudgetRemaining: slo.ErrorBudgetRemaining,
+			BurnRate:             burnRate,
+		}
+		if !slo.LastCalculatedAt.IsZero() {
+			computed := slo.LastCalculatedAt
+			row.ComputedAt = &computed
+			row.DataAgeSeconds = math.Round(now.Sub(computed).Seconds())
+		}
+		fleet.Services = append(fleet.Services, row)
+	}
+
+	sort.SliceStable(fleet.Services, func(i, j int) bool {
+		return fleet.Services[i].ErrorBudgetRemaining < fleet.Services[j].ErrorBudgetRemaining
+	})
+	return fleet
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/utils"
+)
+
+func TestBuildFleetBudgetSortsLowestFirst(t *testing.T) {
+	slos := []SLO{
+		{ServiceName: "catalog", Name: "availability", TargetPercentage: 99.9, CurrentPercentage: 99.95, ErrorBudgetRemaining: 50},
+		{ServiceName: "checkout", Name: "availability", TargetPercentage: 99.9, CurrentPercentage: 99.5, ErrorBudgetRemaining: -400},
+		{ServiceName: "checkout", Name: "latency", TargetPercentage: 99, CurrentPercentage: 99.5, ErrorBudgetRemaining: 50},
+	}
+
+	fleet := BuildFleetBudget([]string{"catalog", "checkout", "search"}, slos)
+
+	if len(fleet.Services) != 2 {
+		t.Fatalf("Expected 2 services with budgets, got %d", len(fleet.Services))
+	}
+	if fleet.Services[0].Service != "checkout" || fleet.Services[1].Service != "catalog" {
+		t.Errorf("Expected checkout (lowest budget) first, got %s then %s", fleet.Services[0].Service, fleet.Services[1].Service)
+	}
+	if fleet.Services[0].SLO != "availability" {
+		t.Errorf("Expected checkout reported by its most-depleted SLO, got %s", fleet.Services[0].SLO)
+	}
+	// 0.5% errors against a 0.1% allowance burns at 5x
+	if math.Abs(fleet.Services[0].BurnRate-5) > 1e-9 {
+		t.Errorf("Expected burn rate 5, got %v", fleet.Services[0].BurnRate)
+	}
+
+	if len(fleet.Skipped) != 1 || fleet.Skipped[0].Service != "search" {
+		t.Errorf("Expected search skipped for missing SLO, got %+v", fleet.Skipped)
+	}
+}
+
+func TestBudgetJSONPrecision(t *testing.T) {
+	defer utils.SetPrecision(utils.DefaultPrecision)
+
+	tests := []struct {
+		name      string
+		precision int
+		v         any
+		expected  []string
+	}{
+		{"budget row", 3, ServiceBudget{CurrentPercentage: 99.87654321, ErrorBudgetRemaining: -23.45678, BurnRate: 4.299999999998},
+			[]string{`"current_percentage":99.877`, `"error_budget_remaining":-23.457`, `"burn_rate":4.3`}},
+		{"SLO", 3, SLO{CurrentPercentage: 99.90000000001, ErrorBudgetRemaining: 12.3456},
+			[]string{`"current_percentage":99.9,`, `"error_budget_remaining":12.346`}},
+		{"burn rate", 1, SLOBurnRate{BurnRate: 14.44}, []string{`"burn_rate":14.4`}},
+		{"full precision", -1, SLOBurnRate{BurnRate: 4.299999999998}, []string{`"burn_rate":4.299999999998`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			utils.SetPrecision(tt.precision)
+			encoded, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(string(encoded), want) {
+					t.Errorf("Expected %s in %s", want, encoded)
+				}
+			}
+		})
+	}
+}
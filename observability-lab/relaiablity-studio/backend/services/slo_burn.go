@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+)
+
+var burnRateWindows = config.DefaultBurnRateWindows
+
+// SetBurnRateWindows sets the window pairs every SLO's burn rate is evaluated
+// over. An empty set restores config.DefaultBurnRateWindows.
+func SetBurnRateWindows(windows []config.BurnRateWindow) {
+	if len(windows) == 0 {
+		windows = config.DefaultBurnRateWindows
+	}
+	burnRateWindows = windows
+}
+
+// BurnRateAlert is a multi-window burn-rate alert, firing while both its
+// short and long window burn the error budget faster than the threshold
+type BurnRateAlert struct {
+	Window    string      `json:"window"` // e.g. 5m/1h
+	Short     SLOBurnRate `json:"short"`
+	Long      SLOBurnRate `json:"long"`
+	Threshold float64     `json:"threshold"`
+	Firing    bool        `json:"firing"`
+}
+
+// burnRates evaluates every burn-rate window pair for the SLO at end. A
+// window's burn rate is its error rate over the rate the objective allows,
+// so 1 spends exactly the budget over the SLO period.
+func (s *SLOService) burnRates(ctx context.Context, slo *SLO, end time.Time) ([]BurnRateAlert, error) {
+	allowed := ErrorBudgetPercent(slo.TargetPercentage)
+	if allowed <= 0 {
+		return nil, fmt.Errorf("SLO %s targets 100%% and has no error budget to burn", slo.Name)
+	}
+
+	// Pairs often share windows, so each is only queried once
+	rates := make(map[time.Duration]float64)
+	rate := func(window time.Duration, threshold float64) (SLOBurnRate, error) {
+		burn, ok := rates[window]
+		if !ok {
+			percentage, err := s.sli(ctx, slo, promDuration(window), end)
+			if err != nil {
+				return SLOBurnRate{}, fmt.Errorf("burn rate over %s: %w", promDuration(window), err)
+			}
+			burn = (100 - percentage) / allowed
+			rates[window] = burn
+		}
+		return SLOBurnRate{WindowSize: promDuration(window), BurnRate: burn, Threshold: threshold, Breached: burn > threshold}, nil
+	}
+
+	var alerts []BurnRateAlert
+	for _, w := range burnRateWindows {
+		short, err := rate(w.Short, w.Threshold)
+		if err != nil {
+			return nil, err
+		}
+		long, err := rate(w.Long, w.Threshold)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, BurnRateAlert{
+			Window:    short.WindowSize + "/" + long.WindowSize,
+			Short:     short,
+			Long:      long,
+			Threshold: w.Threshold,
+			Firing:    short.Breached && long.Breached,
+		})
+	}
+	return alerts, nil
+}
+
+// promDuration formats d as a PromQL range in its largest whole unit, e.g. 3d or 90m
+func promDuration(d time.Duration) string {
+	for _, unit := range []struct {
+		size time.Duration
+		name string
+	}{{24 * time.Hour, "d"}, {time.Hour, "h"}, {time.Minute, "m"}} {
+		if d%unit.size == 0 {
+			return fmt.Sprintf("%d%s", d/unit.size, unit.name)
+		}
+	}
+	return fmt.Sprintf("%ds", d/time.Second)
+}
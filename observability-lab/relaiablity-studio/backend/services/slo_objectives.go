@@ -0,0 +1,26 @@
+package services
+
+import "github.com/sarikasharma2428-web/reliability-studio/config"
+
+var objectives config.SLOObjectives
+
+// SetObjectives sets the objectives applied to SLOs stored without a target.
+// The zero value still applies config.DefaultObjectives.
+func SetObjectives(o config.SLOObjectives) {
+	objectives = o
+}
+
+// applyObjective fills in an SLO's target and latency threshold from the
+// configured objectives when the SLO doesn't set them itself
+func applyObjective(slo *SLO) {
+	obj, ok := objectives.For(slo.ServiceName, slo.SLIType)
+	if !ok {
+		return
+	}
+	if slo.TargetPercentage == 0 {
+		slo.TargetPercentage = obj.Target
+	}
+	if slo.ThresholdMs == 0 {
+		slo.ThresholdMs = obj.ThresholdMs
+	}
+}
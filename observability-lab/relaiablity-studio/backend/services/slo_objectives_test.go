@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/sarikasharma2428-web/reliability-studio/config"
+)
+
+func TestApplyObjective(t *testing.T) {
+	SetObjectives(config.SLOObjectives{
+		Overrides: map[string]map[string]config.Objective{
+			"payments": {config.SLOAvailability: {Target: 99.99}, config.SLOLatency: {Target: 99, ThresholdMs: 200}},
+		},
+	})
+	defer SetObjectives(config.SLOObjectives{})
+
+	testCases := []struct {
+		name      string
+		slo       SLO
+		target    float64
+		threshold float64
+	}{
+		{"default availability", SLO{ServiceName: "checkout", SLIType: "availability"}, 99.9, 0},
+		{"default latency", SLO{ServiceName: "checkout", SLIType: "latency"}, 95, 300},
+		{"override availability", SLO{ServiceName: "payments", SLIType: "availability"}, 99.99, 0},
+		{"override latency", SLO{ServiceName: "payments", SLIType: "latency"}, 99, 200},
+		{"explicit target kept", SLO{ServiceName: "payments", SLIType: "availability", TargetPercentage: 99.5}, 99.5, 0},
+		{"unknown type", SLO{ServiceName: "checkout", SLIType: "freshness"}, 0, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			slo := tc.slo
+			applyObjective(&slo)
+			if slo.TargetPercentage != tc.target {
+				t.Errorf("Expected target %v, got %v", tc.target, slo.TargetPercentage)
+			}
+			if slo.ThresholdMs != tc.threshold {
+				t.Errorf("Expected threshold %vms, got %v", tc.threshold, slo.ThresholdMs)
+			}
+		})
+	}
+}
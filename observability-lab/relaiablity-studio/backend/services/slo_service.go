@@ -4,8 +4,12 @@ package services
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"github.com/sarikasharma2428-web/reliability-studio/clients"
+	"github.com/sarikasharma2428-web/reliability-studio/utils"
+	"log"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -27,6 +31,7 @@ type SLO struct {
 	Name                 string    `json:"name"`
 	Description          string    `json:"description"`
 	TargetPercentage     float64   `json:"target_percentage"`
+	ThresholdMs          float64   `json:"threshold_ms,omitempty"`
 	WindowDays           int       `json:"window_days"`
 	SLIType              string    `json:"sli_type"`
 	Query                string    `json:"query"`
@@ -37,6 +42,15 @@ type SLO struct {
 	CreatedAt            time.Time `json:"created_at"`
 }
 
+// MarshalJSON rounds the percentages and budget to the configured precision
+func (s SLO) MarshalJSON() ([]byte, error) {
+	type plain SLO
+	p := plain(s)
+	p.CurrentPercentage = utils.Round(p.CurrentPercentage)
+	p.ErrorBudgetRemaining = utils.Round(p.ErrorBudgetRemaining)
+	return json.Marshal(p)
+}
+
 type SLOBurnRate struct {
 	WindowSize string  `json:"window"`
 	BurnRate   float64 `json:"burn_rate"`
@@ -44,6 +58,14 @@ type SLOBurnRate struct {
 	Breached   bool    `json:"breached"`
 }
 
+// MarshalJSON rounds the burn rate to the configured precision
+func (b SLOBurnRate) MarshalJSON() ([]byte, error) {
+	type plain SLOBurnRate
+	p := plain(b)
+	p.BurnRate = utils.Round(p.BurnRate)
+	return json.Marshal(p)
+}
+
 // NewSLOService creates a new SLO service
 func NewSLOService(db *sql.DB, promClient PrometheusQueryClient) *SLOService {
 	return &SLOService{
@@ -60,37 +82,49 @@ func (s *SLOService) CalculateSLO(ctx context.Context, sloID string) (*SLO, erro
 		return nil, fmt.Errorf("failed to get SLO: %w", err)
 	}
 
-	// Calculate time window
-	end := time.Now()
+	currentPercentage, errorBudgetRemaining, status, err := s.evaluate(ctx, slo, time.Now())
+	if err != nil {
+		return nil, err
+	}
 
-	// FIXED: Replace ${WINDOW} placeholder with the SLO window (e.g. 30d)
-	// This ensures the query respects the WindowDays set in the database.
-	window := fmt.Sprintf("%dd", slo.WindowDays)
-	query := strings.ReplaceAll(slo.Query, "${WINDOW}", window)
+	// Update SLO in database
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE slos 
+		SET current_percentage = $1,
+		    error_budget_remaining = $2,
+		    status = $3,
+		    last_calculated_at = $4,
+		    updated_at = $4
+		WHERE id = $5
+	`, currentPercentage, errorBudgetRemaining, status, time.Now(), sloID)
 
-	// Execute Prometheus query
-	result, err := s.promClient.Query(ctx, query, end)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute SLO query: %w", err)
+		return nil, fmt.Errorf("failed to update SLO: %w", err)
 	}
 
-	// Parse result
-	if len(result.Data.Result) == 0 {
-		return nil, fmt.Errorf("no data returned from SLO query")
-	}
+	// Return updated SLO
+	slo.CurrentPercentage = currentPercentage
+	slo.ErrorBudgetRemaining = errorBudgetRemaining
+	slo.Status = status
+	slo.LastCalculatedAt = time.Now()
 
-	valueStr, ok := result.Data.Result[0].Value[1].(string)
-	if !ok {
-		return nil, fmt.Errorf("invalid value type in result")
-	}
+	return slo, nil
+}
 
-	var currentPercentage float64
-	if _, err := fmt.Sscanf(valueStr, "%f", &currentPercentage); err != nil {
-		return nil, fmt.Errorf("failed to parse SLO value: %w", err)
+// evaluate runs the SLO's query at end and grades the result against its
+// target, returning the current percentage, remaining error budget and status.
+// Degradation needs at least the minimum traffic in the window.
+func (s *SLOService) evaluate(ctx context.Context, slo *SLO, end time.Time) (float64, float64, string, error) {
+	// FIXED: Replace ${WINDOW} placeholder with the SLO window (e.g. 30d)
+	// This ensures the query respects the WindowDays set in the database.
+	window := fmt.Sprintf("%dd", slo.WindowDays)
+	currentPercentage, err := s.sli(ctx, slo, window, end)
+	if err != nil {
+		return 0, 0, "", err
 	}
 
 	// Calculate error budget - FIXED: Robust calculation with overspend tracking
-	errorBudgetAllowed := 100.0 - slo.TargetPercentage
+	errorBudgetAllowed := ErrorBudgetPercent(slo.TargetPercentage)
 	errorsObserved := 100.0 - currentPercentage
 
 	var errorBudgetRemaining float64
@@ -109,28 +143,78 @@ func (s *SLOService) CalculateSLO(ctx context.Context, sloID string) (*SLO, erro
 		status = "warning"
 	}
 
-	// Update SLO in database
-	_, err = s.db.ExecContext(ctx, `
-		UPDATE slos 
-		SET current_percentage = $1,
-		    error_budget_remaining = $2,
-		    status = $3,
-		    last_calculated_at = $4,
-		    updated_at = $4
-		WHERE id = $5
-	`, currentPercentage, errorBudgetRemaining, status, time.Now(), sloID)
+	// A handful of requests makes any error rate look dramatic. Unknown
+	// traffic, e.g. when the traffic metric doesn't exist, keeps the status
+	// rather than masking a breach.
+	if status != "healthy" && minTraffic > 0 {
+		requests, known, err := s.traffic(ctx, slo, window, end)
+		if err != nil {
+			return 0, 0, "", err
+		}
+		if !known {
+			log.Printf("Warning: no traffic data for SLO %s of %s, keeping status %s", slo.Name, slo.ServiceName, status)
+		} else if requests < minTraffic {
+			status = SLOInsufficientTraffic
+		}
+	}
+
+	return currentPercentage, errorBudgetRemaining, status, nil
+}
 
+// sli runs the SLO's query over window at end, returning the percentage of
+// good requests
+func (s *SLOService) sli(ctx context.Context, slo *SLO, window string, end time.Time) (float64, error) {
+	query := strings.ReplaceAll(slo.Query, "${WINDOW}", window)
+	// Latency SLO queries may reference ${THRESHOLD}, in seconds
+	query = strings.ReplaceAll(query, "${THRESHOLD}", strconv.FormatFloat(slo.ThresholdMs/1000, 'f', -1, 64))
+
+	// Execute Prometheus query
+	result, err := s.promClient.Query(ctx, query, end)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update SLO: %w", err)
+		return 0, fmt.Errorf("failed to execute SLO query: %w", err)
 	}
 
-	// Return updated SLO
-	slo.CurrentPercentage = currentPercentage
-	slo.ErrorBudgetRemaining = errorBudgetRemaining
-	slo.Status = status
-	slo.LastCalculatedAt = time.Now()
+	// Parse result
+	if len(result.Data.Result) == 0 {
+		return 0, fmt.Errorf("no data returned from SLO query")
+	}
 
-	return slo, nil
+	valueStr, ok := result.Data.Result[0].Value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid value type in result")
+	}
+
+	var percentage float64
+	if _, err := fmt.Sscanf(valueStr, "%f", &percentage); err != nil {
+		return 0, fmt.Errorf("failed to parse SLO value: %w", err)
+	}
+	return percentage, nil
+}
+
+// traffic returns the service's request count over window, and whether the
+// traffic query returned one at all
+func (s *SLOService) traffic(ctx context.Context, slo *SLO, window string, end time.Time) (float64, bool, error) {
+	aggregation, err := templates.AggregationFor(slo.ServiceName)
+	if err != nil {
+		return 0, false, err
+	}
+	query := strings.ReplaceAll(trafficQuery, "${SERVICE}", slo.ServiceName)
+	query = strings.ReplaceAll(query, "${WINDOW}", window)
+	query = strings.ReplaceAll(query, "${AGGREGATION}", aggregation)
+
+	result, err := s.promClient.Query(ctx, query, end)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to execute SLO traffic query: %w", err)
+	}
+	if len(result.Data.Result) == 0 || len(result.Data.Result[0].Value) < 2 {
+		return 0, false, nil
+	}
+	valueStr, _ := result.Data.Result[0].Value[1].(string)
+	requests, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return 0, false, nil
+	}
+	return requests, true, nil
 }
 
 // CalculateAllSLOs calculates all SLOs for all services
@@ -227,6 +311,7 @@ func (s *SLOService) GetSLO(ctx context.Context, sloID string) (*SLO, error) {
 		return nil, fmt.Errorf("failed to query SLO: %w", err)
 	}
 
+	applyObjective(&slo)
 	return &slo, nil
 }
 
@@ -261,6 +346,7 @@ func (s *SLOService) GetSLOsByService(ctx context.Context, serviceID string) ([]
 		if err != nil {
 			continue
 		}
+		applyObjective(&slo)
 		slos = append(slos, slo)
 	}
 
@@ -297,69 +383,21 @@ func (s *SLOService) GetAllSLOs(ctx context.Context) ([]SLO, error) {
 		if err != nil {
 			continue
 		}
+		applyObjective(&slo)
 		slos = append(slos, slo)
 	}
 
 	return slos, nil
 }
 
-// CalculateBurnRate calculates error budget burn rate
-func (s *SLOService) CalculateBurnRate(ctx context.Context, sloID string) ([]SLOBurnRate, error) {
+// CalculateBurnRate evaluates the SLO's multi-window burn-rate alerts now;
+// see SetBurnRateWindows
+func (s *SLOService) CalculateBurnRate(ctx context.Context, sloID string) ([]BurnRateAlert, error) {
 	slo, err := s.GetSLO(ctx, sloID)
 	if err != nil {
 		return nil, err
 	}
-
-	windows := []struct {
-		name      string
-		duration  time.Duration
-		threshold float64
-	}{
-		{"1h", time.Hour, 14.4},      // 1 hour window
-		{"6h", 6 * time.Hour, 6.0},   // 6 hour window
-		{"24h", 24 * time.Hour, 3.0}, // 1 day window
-		{"3d", 72 * time.Hour, 1.0},  // 3 day window
-	}
-
-	var burnRates []SLOBurnRate
-	end := time.Now()
-
-	for _, window := range windows {
-		_ = end.Add(-window.duration) // start variable unused
-
-		// Query error budget consumption rate
-		query := fmt.Sprintf(`
-			(1 - (%s)) / (1 - (%f / 100))
-		`, slo.Query, slo.TargetPercentage)
-
-		result, err := s.promClient.Query(ctx, query, end)
-		if err != nil {
-			continue
-		}
-
-		if len(result.Data.Result) == 0 {
-			continue
-		}
-
-		valueStr, ok := result.Data.Result[0].Value[1].(string)
-		if !ok {
-			continue
-		}
-
-		var burnRate float64
-		if _, err := fmt.Sscanf(valueStr, "%f", &burnRate); err != nil {
-			continue
-		}
-
-		burnRates = append(burnRates, SLOBurnRate{
-			WindowSize: window.name,
-			BurnRate:   burnRate,
-			Threshold:  window.threshold,
-			Breached:   burnRate > window.threshold,
-		})
-	}
-
-	return burnRates, nil
+	return s.burnRates(ctx, slo, time.Now())
 }
 
 // CreateSLO creates a new SLO
@@ -3,10 +3,12 @@ package services
 import (
 	"context"
 	"math"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/sarikasharma2428-web/reliability-studio/clients"
+	"github.com/sarikasharma2428-web/reliability-studio/config"
 )
 
 // MockPrometheusClient implements PrometheusQueryClient
@@ -71,3 +73,125 @@ func TestCalculateSLOLogic(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluateSLOMinTraffic(t *testing.T) {
+	SetMinTraffic(100, "")
+	defer SetMinTraffic(0, "")
+
+	// 1 error in 3 requests: 66.7% availability against a 99.9% target
+	var requests string
+	prom := &MockPrometheusClient{QueryFunc: func(ctx context.Context, query string, timestamp time.Time) (*clients.PrometheusResponse, error) {
+		value := "66.67"
+		if query == `sum(increase(http_requests_total{service="checkout"}[30d]))` {
+			value = requests
+		}
+		resp := &clients.PrometheusResponse{}
+		if value != "" {
+			resp.Data.Result = []clients.PrometheusResult{{Value: []interface{}{float64(timestamp.Unix()), value}}}
+		}
+		return resp, nil
+	}}
+	s := NewSLOService(nil, prom)
+	slo := &SLO{ServiceName: "checkout", TargetPercentage: 99.9, WindowDays: 30, Query: "availability"}
+
+	testCases := []struct {
+		requests string
+		status   string
+	}{
+		{"3", SLOInsufficientTraffic},
+		{"99", SLOInsufficientTraffic},
+		{"100", "critical"},
+		{"25000", "critical"},
+		{"", "critical"}, // The traffic metric doesn't exist
+	}
+	for _, tc := range testCases {
+		requests = tc.requests
+		_, _, status, err := s.evaluate(context.Background(), slo, time.Now())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if status != tc.status {
+			t.Errorf("Expected %s with %q requests, got %s", tc.status, tc.requests, status)
+		}
+	}
+
+	SetMinTraffic(0, "")
+	requests = "3"
+	if _, _, status, _ := s.evaluate(context.Background(), slo, time.Now()); status != "critical" {
+		t.Errorf("Expected no traffic check when disabled, got %s", status)
+	}
+}
+
+func TestTrafficQueryAggregation(t *testing.T) {
+	SetQueryTemplates(config.QueryTemplates{"checkout": {Aggregation: "max"}})
+	defer SetQueryTemplates(nil)
+
+	var queries []string
+	prom := &MockPrometheusClient{QueryFunc: func(ctx context.Context, query string, timestamp time.Time) (*clients.PrometheusResponse, error) {
+		queries = append(queries, query)
+		resp := &clients.PrometheusResponse{}
+		resp.Data.Result = []clients.PrometheusResult{{Value: []interface{}{float64(timestamp.Unix()), "10"}}}
+		return resp, nil
+	}}
+	s := NewSLOService(nil, prom)
+	for _, service := range []string{"checkout", "payments"} {
+		if _, _, err := s.traffic(context.Background(), &SLO{ServiceName: service}, "30d", time.Now()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	want := []string{
+		`max(increase(http_requests_total{service="checkout"}[30d]))`,
+		`sum(increase(http_requests_total{service="payments"}[30d]))`,
+	}
+	if strings.Join(queries, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected traffic queries %q, got %q", want, queries)
+	}
+}
+
+func TestBurnRateWindows(t *testing.T) {
+	SetBurnRateWindows([]config.BurnRateWindow{
+		{Short: 5 * time.Minute, Long: time.Hour, Threshold: 14.4},
+		{Short: 30 * time.Minute, Long: 6 * time.Hour, Threshold: 6},
+	})
+	defer SetBurnRateWindows(nil)
+
+	// A sharp outage in the last hour: 2% errors over 5m and 1.5% over 1h
+	// burn a 99.9% objective's budget 20x and 15x, but the longer windows
+	// have barely been touched
+	availability := map[string]string{"5m": "98", "1h": "98.5", "30m": "99.2", "6h": "99.7"}
+	var queried []string
+	prom := &MockPrometheusClient{QueryFunc: func(ctx context.Context, query string, timestamp time.Time) (*clients.PrometheusResponse, error) {
+		window := strings.TrimSuffix(strings.TrimPrefix(query, "availability["), "]")
+		queried = append(queried, window)
+		resp := &clients.PrometheusResponse{}
+		resp.Data.Result = []clients.PrometheusResult{{Value: []interface{}{float64(timestamp.Unix()), availability[window]}}}
+		return resp, nil
+	}}
+	s := NewSLOService(nil, prom)
+	slo := &SLO{Name: "checkout availability", TargetPercentage: 99.9, Query: "availability[${WINDOW}]"}
+
+	alerts, err := s.burnRates(context.Background(), slo, time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(alerts) != 2 {
+		t.Fatalf("Expected 2 window pairs, got %+v", alerts)
+	}
+
+	fast, slow := alerts[0], alerts[1]
+	if fast.Window != "5m/1h" || !fast.Firing {
+		t.Errorf("Expected the 5m/1h fast-burn pair firing, got %+v", fast)
+	}
+	if math.Abs(fast.Short.BurnRate-20) > 1e-6 || math.Abs(fast.Long.BurnRate-15) > 1e-6 {
+		t.Errorf("Expected burn rates 20 and 15, got %v and %v", fast.Short.BurnRate, fast.Long.BurnRate)
+	}
+	if slow.Window != "30m/6h" || slow.Firing {
+		t.Errorf("Expected the 30m/6h slow-burn pair not firing, got %+v", slow)
+	}
+	if !slow.Short.Breached || slow.Long.Breached {
+		t.Errorf("Expected only the slow pair's short window over its threshold, got %+v", slow)
+	}
+	if len(queried) != 4 {
+		t.Errorf("Expected each window queried once, got %v", queried)
+	}
+}
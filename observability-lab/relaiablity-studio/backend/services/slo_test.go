@@ -0,0 +1,45 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestObjectivePercent(t *testing.T) {
+	testCases := []struct {
+		name      string
+		objective float64
+		percent   float64
+		budget    float64
+	}{
+		{"Fraction", 0.999, 99.9, 0.1},
+		{"Percent", 99.9, 99.9, 0.1},
+		{"Perfect fraction", 1, 100, 0},
+		{"Percent with decimals", 99.95, 99.95, 0.05},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ObjectivePercent(tc.objective); math.Abs(got-tc.percent) > 1e-9 {
+				t.Errorf("Expected %v%%, got %v", tc.percent, got)
+			}
+			if got := ErrorBudgetPercent(tc.objective); math.Abs(got-tc.budget) > 1e-9 {
+				t.Errorf("Expected budget %v%%, got %v", tc.budget, got)
+			}
+		})
+	}
+}
+
+func TestErrorRateUnitsMatchSLOBudget(t *testing.T) {
+	// A 0.5% error rate blows a 0.999 (0.1%) budget, and both must be compared in percent
+	errorRate := 0.5
+	if errorRate <= ErrorBudgetPercent(0.999) {
+		t.Errorf("Expected %v%% error rate to exceed a 0.999 objective's budget", errorRate)
+	}
+	if CalculateSLO(errorRate) != "healthy" {
+		t.Errorf("Expected %v%% to stay under the 1%% degraded threshold", errorRate)
+	}
+	if CalculateSLO(4.2) != "degraded" {
+		t.Error("Expected 4.2% error rate to be degraded")
+	}
+}
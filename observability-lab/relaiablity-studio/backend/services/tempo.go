@@ -1,16 +1,48 @@
 package services
 
 import (
-	"io"
-	"net/http"
+	"context"
+	"net/url"
+	"strconv"
+	"time"
 )
 
-func GetTraces() string {
-	resp, err := http.Get("http://tempo:3200/api/search")
+// GetTraces searches Tempo's recent traces
+func GetTraces(ctx context.Context) (string, error) {
+	body, err := fetch(ctx, tempoPool, "/api/search")
 	if err != nil {
-		return err.Error()
+		return "", err
 	}
-	defer resp.Body.Close()
-	body, _ := io.ReadAll(resp.Body)
-	return string(body)
+	return body, nil
+}
+
+// GetTracesRange searches for traces that started between start and end
+func GetTracesRange(ctx context.Context, start, end time.Time) (string, error) {
+	params := url.Values{}
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+
+	body, err := fetch(ctx, tempoPool, "/api/search?"+params.Encode())
+	if err != nil {
+		return "", err
+	}
+	return body, nil
+}
+
+// SearchTraceQL searches for traces matching a TraceQL query, e.g.
+// { resource.service.name = "checkout" && status = error }. A zero start and
+// end search Tempo's default recent window.
+func SearchTraceQL(ctx context.Context, query string, start, end time.Time) (string, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	if !start.IsZero() {
+		params.Set("start", strconv.FormatInt(start.Unix(), 10))
+		params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	}
+
+	body, err := fetch(ctx, tempoPool, "/api/search?"+params.Encode())
+	if err != nil {
+		return "", err
+	}
+	return body, nil
 }
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/analysis"
+)
+
+func TestSearchTraceQL(t *testing.T) {
+	const query = `{ resource.service.name = "checkout" && status = error }`
+	var gotQuery, gotStart string
+	tempo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/search" {
+			t.Errorf("Expected /api/search, got %s", r.URL.Path)
+		}
+		gotQuery = r.URL.Query().Get("q")
+		gotStart = r.URL.Query().Get("start")
+		w.Write([]byte(`{"traces":[
+			{"traceID":"a1","rootServiceName":"checkout","startTimeUnixNano":"1705284839000000000","status":"error"},
+			{"traceID":"b2","rootServiceName":"checkout","startTimeUnixNano":"1705284840000000000","status":"error"}
+		]}`))
+	}))
+	defer tempo.Close()
+	SetUpstreams("", "", tempo.URL)
+	defer SetUpstreams("", "", "")
+
+	start := time.Unix(1705284000, 0)
+	body, err := SearchTraceQL(context.Background(), query, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	res, err := analysis.AnalyzeTraces(body)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotQuery != query {
+		t.Errorf("Expected TraceQL %q, got %q", query, gotQuery)
+	}
+	if gotStart != "1705284000" {
+		t.Errorf("Expected start 1705284000, got %q", gotStart)
+	}
+	if res.Failures != 2 || res.FailuresFor("checkout") != 2 {
+		t.Errorf("Expected 2 failed checkout traces, got %+v", res)
+	}
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/metrics"
+	"github.com/sarikasharma2428-web/reliability-studio/version"
+)
+
+// DefaultUpstreamConcurrency bounds simultaneous requests to Prometheus, Loki and Tempo
+const DefaultUpstreamConcurrency = 20
+
+// DefaultResponseWarnBytes is the response size above which a warning is logged
+const DefaultResponseWarnBytes = 10 << 20
+
+// Default upstream base URLs, matching the docker-compose service names
+const (
+	DefaultPrometheusURL = "http://prometheus:9090"
+	DefaultLokiURL       = "http://loki:3100"
+	DefaultTempoURL      = "http://tempo:3200"
+)
+
+var (
+	upstreamSlots  = make(chan struct{}, DefaultUpstreamConcurrency)
+	upstreamClient = &http.Client{Timeout: 30 * time.Second}
+
+	prometheusPool = newReplicaPool("prometheus", DefaultPrometheusURL)
+	lokiPool       = newReplicaPool("loki", DefaultLokiURL)
+	tempoPool      = newReplicaPool("tempo", DefaultTempoURL)
+
+	responseWarnBytes = DefaultResponseWarnBytes
+	userAgent         = DefaultUserAgent()
+
+	upstreamResponseBytes = metrics.NewHistogram(
+		"rl_upstream_response_bytes",
+		"Size of upstream response bodies in bytes",
+		metrics.ExponentialBuckets(1024, 4, 9), // 1KiB to 64MiB
+		"target",
+	)
+)
+
+// SetResponseSizeWarning sets the size above which a single upstream
+// response is logged as suspicious. n <= 0 restores the default.
+func SetResponseSizeWarning(n int) {
+	if n <= 0 {
+		n = DefaultResponseWarnBytes
+	}
+	responseWarnBytes = n
+}
+
+// DefaultUserAgent identifies this build to upstream admins
+func DefaultUserAgent() string {
+	return "reliability-studio/" + version.Version
+}
+
+// SetUserAgent sets the User-Agent sent on every upstream request.
+// An empty value restores DefaultUserAgent.
+func SetUserAgent(ua string) {
+	if ua == "" {
+		ua = DefaultUserAgent()
+	}
+	userAgent = ua
+}
+
+// SetUpstreams points the raw fetchers at the given base URLs. Each value may
+// be a comma-separated list of replicas; an empty value restores the default.
+func SetUpstreams(prometheus, loki, tempo string) {
+	prometheusPool = newReplicaPool("prometheus", orDefault(prometheus, DefaultPrometheusURL))
+	lokiPool = newReplicaPool("loki", orDefault(loki, DefaultLokiURL))
+	tempoPool = newReplicaPool("tempo", orDefault(tempo, DefaultTempoURL))
+}
+
+func orDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// SetUpstreamConcurrency resizes the global upstream request limit.
+// It must be called before any upstream requests are made.
+func SetUpstreamConcurrency(n int) {
+	if n <= 0 {
+		n = DefaultUpstreamConcurrency
+	}
+	upstreamSlots = make(chan struct{}, n)
+}
+
+// get performs a GET against an upstream and returns the body. Every caller
+// shares the same pool of slots, so fan-out throttles itself instead of
+// opening hundreds of connections to a single backend. target names the
+// upstream (prometheus, loki, tempo) in metrics and logs.
+func get(ctx context.Context, target, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	return send(ctx, target, req)
+}
+
+// send performs req against an upstream once a slot is free, see get
+func send(ctx context.Context, target string, req *http.Request) (string, error) {
+	body, err := open(ctx, target, req)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(data) > responseWarnBytes {
+		log.Printf("Warning: %s returned %d bytes for %s (limit %d)", target, len(data), req.URL.Path, responseWarnBytes)
+	}
+	return string(data), nil
+}
+
+// maxErrorBody bounds how much of a 5xx response is read for an error envelope
+const maxErrorBody = 64 << 10
+
+// open performs req like send, but returns the body unread. It holds the
+// upstream slot until the caller closes it. A 5xx response is an error unless
+// its body is a JSON error envelope ({"status":"error",...}, as Prometheus and
+// Loki send for timeouts and failed queries), which is returned for the
+// caller to report.
+func open(ctx context.Context, target string, req *http.Request) (io.ReadCloser, error) {
+	slots := upstreamSlots
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := clientFor(target).Do(req)
+	if err != nil {
+		<-slots
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		resp.Body.Close()
+		<-slots
+		if !isErrorEnvelope(data) {
+			return nil, fmt.Errorf("%s returned status %d", target, resp.StatusCode)
+		}
+		upstreamResponseBytes.Observe(float64(len(data)), target)
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return &upstreamBody{ReadCloser: resp.Body, target: target, slots: slots}, nil
+}
+
+// isErrorEnvelope reports whether body is a Prometheus/Loki API error
+func isErrorEnvelope(body []byte) bool {
+	var envelope struct {
+		Status string `json:"status"`
+	}
+	return json.Unmarshal(body, &envelope) == nil && envelope.Status == "error"
+}
+
+// upstreamBody records the size of a response and frees its slot on Close
+type upstreamBody struct {
+	io.ReadCloser
+	target string
+	slots  chan struct{}
+	n      int
+	closed bool
+}
+
+func (b *upstreamBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += n
+	return n, err
+}
+
+func (b *upstreamBody) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	upstreamResponseBytes.Observe(float64(b.n), b.target)
+	<-b.slots
+	return b.ReadCloser.Close()
+}
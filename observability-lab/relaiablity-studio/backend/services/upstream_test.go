@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUpstreamConcurrencyLimit(t *testing.T) {
+	const limit = 5
+	SetUpstreamConcurrency(limit)
+	defer SetUpstreamConcurrency(DefaultUpstreamConcurrency)
+
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := get(context.Background(), "test", server.URL); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight > limit {
+		t.Errorf("Expected at most %d in-flight requests, observed %d", limit, maxInFlight)
+	}
+	if maxInFlight == 0 {
+		t.Error("Expected requests to reach the upstream")
+	}
+}
+
+func TestUpstreamResponseBytesMetric(t *testing.T) {
+	body := strings.Repeat("x", 3<<20)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	SetResponseSizeWarning(1 << 20)
+	defer SetResponseSizeWarning(0)
+
+	beforeCount, beforeSum := upstreamResponseBytes.Count("loki")
+	got, err := get(context.Background(), "loki", server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != len(body) {
+		t.Fatalf("Expected %d byte body, got %d", len(body), len(got))
+	}
+
+	count, sum := upstreamResponseBytes.Count("loki")
+	if count != beforeCount+1 {
+		t.Errorf("Expected one new observation, got %d", count-beforeCount)
+	}
+	if sum-beforeSum != float64(len(body)) {
+		t.Errorf("Expected observed size %d, got %v", len(body), sum-beforeSum)
+	}
+}
+
+func TestUpstreamServerErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		envelope bool
+	}{
+		{"error envelope", `{"status":"error","errorType":"timeout","error":"query timed out in expression evaluation"}`, true},
+		{"plain text", "upstream connect error or disconnect/reset before headers", false},
+		{"other json", `{"message":"internal error"}`, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			got, err := get(context.Background(), "prometheus", server.URL)
+			if tc.envelope {
+				if err != nil || got != tc.body {
+					t.Errorf("Expected the error envelope returned, got %q, %v", got, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Errorf("Expected an error for a 503 without an error envelope, got %q", got)
+			}
+		})
+	}
+}
+
+func TestUpstreamUserAgent(t *testing.T) {
+	var mu sync.Mutex
+	agents := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents[r.URL.Path] = r.UserAgent()
+		mu.Unlock()
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	SetUpstreams(server.URL, server.URL, server.URL)
+	defer SetUpstreams("", "", "")
+
+	QueryMetrics(context.Background(), "up")
+	QueryLogs(context.Background(), `{app="checkout"}`)
+	GetTraces(context.Background())
+
+	want := "reliability-studio/dev"
+	for _, path := range []string{"/api/v1/query", "/loki/api/v1/query", "/api/search"} {
+		if agents[path] != want {
+			t.Errorf("Expected User-Agent %q on %s, got %q", want, path, agents[path])
+		}
+	}
+
+	SetUserAgent("sre-bot/1.0")
+	defer SetUserAgent("")
+	QueryMetrics(context.Background(), "up")
+	if agents["/api/v1/query"] != "sre-bot/1.0" {
+		t.Errorf("Expected configured User-Agent, got %q", agents["/api/v1/query"])
+	}
+}
+
+func TestPrometheusQueryTimeout(t *testing.T) {
+	var mu sync.Mutex
+	var timeouts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		timeouts = append(timeouts, r.URL.Query().Get("timeout"))
+		mu.Unlock()
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	SetUpstreams(server.URL, "", "")
+	defer SetUpstreams("", "", "")
+	SetQueryTimeout(90 * time.Second)
+	defer SetQueryTimeout(0)
+
+	end := time.Now()
+	QueryMetrics(context.Background(), "up")
+	QueryMetricsAt(context.Background(), "job:up:sum", end)
+	QueryMetricsRange(context.Background(), "up", end.Add(-time.Hour), end, time.Minute)
+
+	if len(timeouts) != 3 {
+		t.Fatalf("Expected 3 queries, got %d", len(timeouts))
+	}
+	for i, got := range timeouts {
+		if got != "90" {
+			t.Errorf("Expected query %d to carry timeout=90, got %q", i, got)
+		}
+	}
+}
+
+func TestUpstreamProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.Host+r.URL.Path)
+		w.Write([]byte(`{}`))
+	}))
+	defer proxy.Close()
+
+	// Only reachable through the proxy
+	SetUpstreams("http://prometheus.internal:9090", "http://loki.internal:3100", "")
+	defer SetUpstreams("", "", "")
+	if err := SetProxies(proxy.URL, "", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer SetProxies("", "", "")
+
+	if body, err := QueryMetrics(context.Background(), "up"); err != nil || body != `{}` {
+		t.Errorf("Expected the proxy's response, got %q, %v", body, err)
+	}
+	if len(proxied) != 1 || proxied[0] != "prometheus.internal:9090/api/v1/query" {
+		t.Errorf("Expected the Prometheus query routed through the proxy, got %v", proxied)
+	}
+	if clientFor("loki") != upstreamClient {
+		t.Error("Expected Loki without a proxy to use the default client")
+	}
+
+	if err := SetProxies("://bad", "", ""); err == nil {
+		t.Error("Expected an error for an invalid proxy URL")
+	}
+}
+
+func TestIsRecordingRule(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected bool
+	}{
+		{"job:http_error_rate:ratio", true},
+		{"instance:node_cpu:rate5m", true},
+		{"http_requests_total", false},
+		{`job:http_error_rate:ratio{job="checkout"}`, false},
+		{"rate(http_requests_total[5m])", false},
+		{":leading_colon", false},
+	}
+
+	for _, tc := range testCases {
+		if got := IsRecordingRule(tc.query); got != tc.expected {
+			t.Errorf("IsRecordingRule(%q): expected %v, got %v", tc.query, tc.expected, got)
+		}
+	}
+}
+
+func TestQueryMetricsRangeStep(t *testing.T) {
+	var steps []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		steps = append(steps, r.URL.Query().Get("step"))
+		w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+	}))
+	defer server.Close()
+
+	SetUpstreams(server.URL, server.URL, server.URL)
+	defer SetUpstreams("", "", "")
+
+	end := time.Date(2024, 1, 15, 2, 15, 0, 0, time.UTC)
+	for _, step := range []time.Duration{time.Minute, 500 * time.Millisecond, 1500 * time.Millisecond} {
+		if _, err := QueryMetricsRange(context.Background(), "up", end.Add(-time.Minute), end, step); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if want := []string{"60", "0.5", "1.5"}; strings.Join(steps, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected steps %v, got %v", want, steps)
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// validationRange is how much data a validation query evaluates. It only has
+// to be long enough for the upstream to parse and plan the query.
+const validationRange = time.Minute
+
+// ValidatePromQL runs query over a tiny range so Prometheus parses it. The
+// body is returned as-is: a parse error is a 400 with a JSON error, not a
+// transport error.
+func ValidatePromQL(ctx context.Context, query string) (string, error) {
+	end := time.Now()
+	params := queryParams(query)
+	params.Set("start", strconv.FormatInt(end.Add(-validationRange).Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	params.Set("step", strconv.FormatInt(int64(validationRange.Seconds()), 10))
+
+	return fetch(ctx, prometheusPool, "/api/v1/query_range?"+params.Encode())
+}
+
+// ValidateLogQL runs query over a tiny range, returning at most one line, so
+// Loki parses it. Loki reports parse errors as a plain-text 400 body.
+func ValidateLogQL(ctx context.Context, query string) (string, error) {
+	end := time.Now()
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(end.Add(-validationRange).UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	params.Set("limit", "1")
+
+	return fetch(ctx, lokiPool, "/loki/api/v1/query_range?"+params.Encode())
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// Ack is an operator's acknowledgement of a service's incident, silencing
+// pages until it expires
+type Ack struct {
+	Author  string    `json:"author"`
+	Reason  string    `json:"reason"`
+	Until   time.Time `json:"until"`
+	AckedAt time.Time `json:"acked_at"`
+}
+
+// Acknowledge records ack for service, replacing any earlier one
+func (s *Store) Acknowledge(service string, ack Ack) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.acks[service] = ack
+}
+
+// Acknowledgement returns the service's acknowledgement if it is active at now
+func (s *Store) Acknowledgement(service string, now time.Time) (Ack, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ack, ok := s.acks[service]
+	if !ok || !now.Before(ack.Until) {
+		return Ack{}, false
+	}
+	return ack, true
+}
+
+// Suppressed silences pages for acknowledged services, so a Store can be
+// used as a notify.Suppressor
+func (s *Store) Suppressed(service string, now time.Time) (string, bool) {
+	ack, ok := s.Acknowledgement(service, now)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("acknowledged by %s until %s: %s", ack.Author, ack.Until.Format(time.RFC3339), ack.Reason), true
+}
@@ -0,0 +1,224 @@
+// Package store keeps the incidents built by the correlation pipeline, and
+// operators' acknowledgements of them, in memory. A retention policy bounds
+// how much history is kept per service; it is applied by Prune, which Run
+// calls periodically.
+package store
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+// DefaultCleanupInterval is how often Run prunes when no interval is given
+const DefaultCleanupInterval = 5 * time.Minute
+
+// Retention limits how long and how many incidents are kept
+type Retention struct {
+	MaxAge        time.Duration // Incidents stored longer ago are pruned; 0 keeps them forever
+	MaxPerService int           // Only the newest N per service are kept; 0 keeps any number
+}
+
+// Record is a stored incident
+type Record struct {
+	Incident correlation.Incident `json:"incident"`
+	StoredAt time.Time            `json:"stored_at"`
+}
+
+// Store is an in-memory incident history, safe for concurrent use
+type Store struct {
+	retention Retention
+
+	mu        sync.Mutex
+	byService map[string][]Record // oldest first
+	acks      map[string]Ack
+	firstSeen map[string]time.Time // Start of each service's current non-healthy run
+}
+
+// New creates an empty store with the given retention policy
+func New(retention Retention) *Store {
+	return &Store{
+		retention: retention,
+		byService: make(map[string][]Record),
+		acks:      make(map[string]Ack),
+		firstSeen: make(map[string]time.Time),
+	}
+}
+
+// Add stores an incident under its service, returning it with FirstSeen set
+// if it is ongoing. A warning, critical or recovering incident continues the
+// service's current run, or starts one; a healthy one ends it.
+func (s *Store) Add(incident correlation.Incident) correlation.Incident {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	switch incident.Severity {
+	case "healthy":
+		delete(s.firstSeen, incident.Service)
+	case "warning", "critical", correlation.SeverityRecovering:
+		first, ok := s.firstSeen[incident.Service]
+		if !ok {
+			first = now
+			s.firstSeen[incident.Service] = first
+		}
+		incident.FirstSeen = &first
+	}
+
+	s.byService[incident.Service] = append(s.byService[incident.Service], Record{Incident: incident, StoredAt: now})
+	return incident
+}
+
+// List returns a service's stored incidents, newest first
+func (s *Store) List(service string) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := append([]Record(nil), s.byService[service]...)
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	return records
+}
+
+// severityOrder ranks severities for Active, most urgent first; any other
+// non-healthy severity (unknown, no data) follows them
+var severityOrder = map[string]int{"critical": 0, "warning": 1, correlation.SeverityRecovering: 2}
+
+// Active returns the latest incident of every service that isn't currently
+// healthy, by severity (critical first) and then newest first
+func (s *Store) Active() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var active []Record
+	for _, records := range s.byService {
+		if latest := records[len(records)-1]; latest.Incident.Severity != "healthy" {
+			active = append(active, latest)
+		}
+	}
+
+	rank := func(severity string) int {
+		if r, ok := severityOrder[severity]; ok {
+			return r
+		}
+		return len(severityOrder)
+	}
+	sort.Slice(active, func(i, j int) bool {
+		if ri, rj := rank(active[i].Incident.Severity), rank(active[j].Incident.Severity); ri != rj {
+			return ri < rj
+		}
+		return active[i].StoredAt.After(active[j].StoredAt)
+	})
+	return active
+}
+
+// Prune removes incidents outside the retention policy as of now and
+// returns how many were removed
+func (s *Store) Prune(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for service, records := range s.byService {
+		keep := records
+		if s.retention.MaxAge > 0 {
+			cutoff := now.Add(-s.retention.MaxAge)
+			i := sort.Search(len(keep), func(i int) bool { return keep[i].StoredAt.After(cutoff) })
+			keep = keep[i:]
+		}
+		if n := s.retention.MaxPerService; n > 0 && len(keep) > n {
+			keep = keep[len(keep)-n:]
+		}
+
+		removed += len(records) - len(keep)
+		if len(keep) == 0 {
+			delete(s.byService, service)
+		} else if len(keep) < len(records) {
+			// Copy so the pruned records can be garbage collected
+			s.byService[service] = append([]Record(nil), keep...)
+		}
+	}
+	for service, ack := range s.acks {
+		if !now.Before(ack.Until) {
+			delete(s.acks, service)
+		}
+	}
+	return removed
+}
+
+// Run prunes the store every interval until ctx is cancelled.
+// interval <= 0 uses DefaultCleanupInterval.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultCleanupInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if n := s.Prune(now); n > 0 {
+				log.Printf("Pruned %d incidents past retention", n)
+			}
+		}
+	}
+}
+
+// Recurrence is a group of stored incidents with the same fingerprint. Count
+// is how many separate runs of non-healthy incidents it occurred in, not how
+// many times it was built.
+type Recurrence struct {
+	Fingerprint string               `json:"fingerprint"`
+	Count       int                  `json:"count"`
+	FirstSeen   time.Time            `json:"first_seen"`
+	LastSeen    time.Time            `json:"last_seen"`
+	Latest      correlation.Incident `json:"latest"`
+}
+
+// Recurrences groups a service's incidents stored since the given time by
+// fingerprint, most frequent first. Only incidents in a run (see Add) count:
+// healthy ones, and those with unknown or no data, aren't occurrences of an
+// issue. A run rebuilt on every poll counts once.
+func (s *Store) Recurrences(service string, since time.Time) []Recurrence {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var groups []Recurrence
+	index := make(map[string]int)
+	runs := make(map[string]map[int64]bool)
+	for _, r := range s.byService[service] {
+		if r.StoredAt.Before(since) || r.Incident.FirstSeen == nil {
+			continue
+		}
+		fp, run := r.Incident.Fingerprint, r.Incident.FirstSeen.UnixNano()
+		i, ok := index[fp]
+		if !ok {
+			i = len(groups)
+			index[fp] = i
+			groups = append(groups, Recurrence{Fingerprint: fp, FirstSeen: r.StoredAt})
+			runs[fp] = make(map[int64]bool)
+		}
+		if !runs[fp][run] {
+			runs[fp][run] = true
+			groups[i].Count++
+		}
+		groups[i].LastSeen = r.StoredAt
+		groups[i].Latest = r.Incident
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].LastSeen.After(groups[j].LastSeen)
+	})
+	return groups
+}
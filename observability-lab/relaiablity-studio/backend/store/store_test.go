@@ -0,0 +1,118 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sarikasharma2428-web/reliability-studio/correlation"
+)
+
+func TestPruneMaxPerService(t *testing.T) {
+	s := New(Retention{MaxPerService: 3})
+	for i := 0; i < 5; i++ {
+		s.Add(correlation.Incident{ID: fmt.Sprintf("checkout-%d", i), Service: "checkout"})
+	}
+	s.Add(correlation.Incident{ID: "payments-0", Service: "payments"})
+
+	if removed := s.Prune(time.Now()); removed != 2 {
+		t.Errorf("Expected 2 incidents pruned, got %d", removed)
+	}
+
+	records := s.List("checkout")
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 checkout incidents kept, got %d", len(records))
+	}
+	for i, want := range []string{"checkout-4", "checkout-3", "checkout-2"} {
+		if records[i].Incident.ID != want {
+			t.Errorf("Expected record %d to be %s, got %s", i, want, records[i].Incident.ID)
+		}
+	}
+	if len(s.List("payments")) != 1 {
+		t.Errorf("Expected other services unaffected")
+	}
+}
+
+func TestPruneMaxAge(t *testing.T) {
+	s := New(Retention{MaxAge: time.Hour})
+	s.Add(correlation.Incident{ID: "checkout-old", Service: "checkout"})
+
+	if removed := s.Prune(time.Now().Add(30 * time.Minute)); removed != 0 {
+		t.Errorf("Expected nothing pruned within max age, got %d", removed)
+	}
+	if removed := s.Prune(time.Now().Add(2 * time.Hour)); removed != 1 {
+		t.Errorf("Expected expired incident pruned, got %d", removed)
+	}
+	if records := s.List("checkout"); len(records) != 0 {
+		t.Errorf("Expected no incidents left, got %+v", records)
+	}
+}
+
+func TestPruneNoRetention(t *testing.T) {
+	s := New(Retention{})
+	for i := 0; i < 10; i++ {
+		s.Add(correlation.Incident{Service: "checkout"})
+	}
+	if removed := s.Prune(time.Now().Add(365 * 24 * time.Hour)); removed != 0 {
+		t.Errorf("Expected zero retention to keep everything, got %d pruned", removed)
+	}
+}
+
+func TestRecurrences(t *testing.T) {
+	s := New(Retention{})
+	// Three runs of db-timeout, each built on several polls, and one of oom
+	for _, run := range [][]string{{"db-timeout", "db-timeout"}, {"oom"}, {"db-timeout"}, {"db-timeout", "db-timeout", "db-timeout"}} {
+		for _, fp := range run {
+			s.Add(correlation.Incident{Service: "checkout", Severity: "critical", Fingerprint: fp})
+		}
+		s.Add(correlation.Incident{Service: "checkout", Severity: "healthy", Fingerprint: "healthy"})
+	}
+	s.Add(correlation.Incident{Service: "checkout", Severity: "unknown", Fingerprint: "unknown"})
+	s.Add(correlation.Incident{Service: "payments", Severity: "critical", Fingerprint: "db-timeout"})
+
+	groups := s.Recurrences("checkout", time.Now().Add(-time.Hour))
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 recurring issues, got %+v", groups)
+	}
+	if groups[0].Fingerprint != "db-timeout" || groups[0].Count != 3 {
+		t.Errorf("Expected db-timeout 3 times first, got %s %d times", groups[0].Fingerprint, groups[0].Count)
+	}
+	if groups[1].Fingerprint != "oom" || groups[1].Count != 1 {
+		t.Errorf("Expected oom once, got %s %d times", groups[1].Fingerprint, groups[1].Count)
+	}
+	if got := s.Recurrences("checkout", time.Now().Add(time.Minute)); len(got) != 0 {
+		t.Errorf("Expected nothing since a future time, got %+v", got)
+	}
+}
+
+func TestFirstSeen(t *testing.T) {
+	s := New(Retention{})
+
+	first := s.Add(correlation.Incident{Service: "checkout", Severity: "critical"})
+	if first.FirstSeen == nil {
+		t.Fatal("Expected a critical incident to be first seen now")
+	}
+	if got := first.WithAge(*first.FirstSeen); !got.IsNew || got.Duration != 0 {
+		t.Errorf("Expected a fresh incident to be new, got is_new=%t duration=%v", got.IsNew, got.Duration)
+	}
+
+	// The next evaluation continues the run, and an hour on it is ongoing
+	later := s.Add(correlation.Incident{Service: "checkout", Severity: "warning"})
+	if later.FirstSeen == nil || !later.FirstSeen.Equal(*first.FirstSeen) {
+		t.Fatalf("Expected the run's first seen %v, got %v", first.FirstSeen, later.FirstSeen)
+	}
+	if got := later.WithAge(first.FirstSeen.Add(time.Hour)); got.IsNew || got.Duration != 3600 {
+		t.Errorf("Expected an hour-old incident to be ongoing for 3600s, got is_new=%t duration=%v", got.IsNew, got.Duration)
+	}
+
+	// Recovering keeps the run; healthy ends it
+	if got := s.Add(correlation.Incident{Service: "checkout", Severity: correlation.SeverityRecovering}); got.FirstSeen == nil {
+		t.Error("Expected a recovering incident to stay in the run")
+	}
+	if got := s.Add(correlation.Incident{Service: "checkout", Severity: "healthy"}); got.FirstSeen != nil {
+		t.Errorf("Expected a healthy incident to have no first seen, got %v", got.FirstSeen)
+	}
+	if got := s.Add(correlation.Incident{Service: "checkout", Severity: "critical"}); !got.FirstSeen.After(*first.FirstSeen) {
+		t.Errorf("Expected a new run after recovery, got first seen %v", got.FirstSeen)
+	}
+}
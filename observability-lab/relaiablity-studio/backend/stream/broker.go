@@ -0,0 +1,121 @@
+// Package stream fans incident updates out to Server-Sent Events clients.
+// Each client has a bounded buffer; a client that can't keep up loses its
+// oldest updates and is told how many it skipped, so a slow reader never
+// blocks the producer or grows memory without bound.
+package stream
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultClientBuffer is how many undelivered updates each client may hold
+const DefaultClientBuffer = 16
+
+// Event is a single SSE message
+type Event struct {
+	Name string // SSE event name, e.g. "incident"
+	Data any    // JSON-encoded into the data field
+}
+
+// Broker distributes published events to every subscriber
+type Broker struct {
+	// MaxSubscribers caps concurrent clients of Handler; 0 allows any number
+	MaxSubscribers int
+	// WriteTimeout bounds how long Handler may take to write each event to a
+	// client, in place of the server's write timeout for the whole response;
+	// 0 leaves writes unbounded
+	WriteTimeout time.Duration
+
+	buffer int
+
+	mu   sync.Mutex
+	subs map[*Subscriber]struct{}
+}
+
+// Subscriber is one connected client's queue
+type Subscriber struct {
+	events  chan Event
+	skipped atomic.Int64
+}
+
+// NewBroker creates a broker whose clients buffer up to buffer events.
+// buffer <= 0 uses DefaultClientBuffer.
+func NewBroker(buffer int) *Broker {
+	if buffer <= 0 {
+		buffer = DefaultClientBuffer
+	}
+	return &Broker{buffer: buffer, subs: make(map[*Subscriber]struct{})}
+}
+
+// Subscribe registers a new client. Callers must Unsubscribe when done.
+func (b *Broker) Subscribe() *Subscriber {
+	s := &Subscriber{events: make(chan Event, b.buffer)}
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+// TrySubscribe registers a new client unless MaxSubscribers are already
+// connected. Callers must Unsubscribe when done.
+func (b *Broker) TrySubscribe() (*Subscriber, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.MaxSubscribers > 0 && len(b.subs) >= b.MaxSubscribers {
+		return nil, false
+	}
+	s := &Subscriber{events: make(chan Event, b.buffer)}
+	b.subs[s] = struct{}{}
+	return s, true
+}
+
+// Unsubscribe removes a client
+func (b *Broker) Unsubscribe(s *Subscriber) {
+	b.mu.Lock()
+	delete(b.subs, s)
+	b.mu.Unlock()
+}
+
+// Subscribers returns the number of connected clients
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Publish queues e for every subscriber without ever blocking. A subscriber
+// whose buffer is full has its oldest event dropped to make room.
+func (b *Broker) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subs {
+		for {
+			select {
+			case s.events <- e:
+			default:
+				// Full: drop the oldest and retry. The reader may have drained
+				// it first, in which case nothing is lost.
+				select {
+				case <-s.events:
+					s.skipped.Add(1)
+				default:
+				}
+				continue
+			}
+			break
+		}
+	}
+}
+
+// Events delivers queued events in order
+func (s *Subscriber) Events() <-chan Event {
+	return s.events
+}
+
+// TakeSkipped returns how many events were dropped since the last call
+func (s *Subscriber) TakeSkipped() int64 {
+	return s.skipped.Swap(0)
+}
@@ -0,0 +1,161 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPublishNeverBlocksOnSlowReader(t *testing.T) {
+	b := NewBroker(2)
+	sub := b.Subscribe()
+	defer b.Unsubscribe(sub)
+
+	// The subscriber reads nothing while 100 updates are published
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			b.Publish(Event{Name: "incident", Data: i})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a slow subscriber")
+	}
+
+	if n := sub.TakeSkipped(); n != 98 {
+		t.Errorf("Expected 98 skipped updates, got %d", n)
+	}
+	// Only the newest updates survive
+	if e := <-sub.Events(); e.Data != 98 {
+		t.Errorf("Expected oldest surviving update 98, got %v", e.Data)
+	}
+	if e := <-sub.Events(); e.Data != 99 {
+		t.Errorf("Expected newest update 99, got %v", e.Data)
+	}
+}
+
+func TestHandlerSendsSkipNotice(t *testing.T) {
+	b := NewBroker(1)
+	server := httptest.NewServer(b.Handler())
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %q", ct)
+	}
+
+	for b.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Publish faster than the handler can drain a one-slot buffer; repeat
+	// until at least one update is dropped
+	reader := bufio.NewReader(resp.Body)
+	lines := make(chan string)
+	go func() {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			lines <- line
+		}
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for i := 0; ; i++ {
+		b.Publish(Event{Name: "incident", Data: i})
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("stream closed before a skip notice")
+			}
+			if strings.HasPrefix(line, "event: skipped") {
+				data := <-lines
+				if !strings.Contains(data, "updates") {
+					t.Errorf("Expected skip notice data, got %q", data)
+				}
+				return
+			}
+		case <-deadline:
+			t.Fatal("never received a skip notice")
+		default:
+		}
+	}
+}
+
+func TestHandlerLimitsSubscribers(t *testing.T) {
+	b := NewBroker(1)
+	b.MaxSubscribers = 2
+	server := httptest.NewServer(b.Handler())
+	defer server.Close()
+
+	connect := func(ctx context.Context) *http.Response {
+		req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		return resp
+	}
+	waitFor := func(n int) {
+		deadline := time.Now().Add(time.Second)
+		for b.Subscribers() != n {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %d subscribers, got %d", n, b.Subscribers())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	var cancels []context.CancelFunc
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cancels = append(cancels, cancel)
+		resp := connect(ctx)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected client %d to be accepted, got %d", i+1, resp.StatusCode)
+		}
+	}
+	waitFor(2)
+
+	resp := connect(context.Background())
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 past the cap, got %d", resp.StatusCode)
+	}
+	if b.Subscribers() != 2 {
+		t.Errorf("Expected a rejected client not to count, got %d subscribers", b.Subscribers())
+	}
+
+	// Disconnecting frees a slot for the next client
+	cancels[0]()
+	waitFor(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resp = connect(ctx)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected a freed slot to accept a client, got %d", resp.StatusCode)
+	}
+}
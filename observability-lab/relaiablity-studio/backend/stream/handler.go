@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Handler serves the broker's events to a client as text/event-stream.
+// Clients beyond MaxSubscribers get 503; a slot frees when a client disconnects.
+func (b *Broker) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		sub, ok := b.TrySubscribe()
+		if !ok {
+			w.Header().Set("Retry-After", "30")
+			http.Error(w, "Too many stream clients", http.StatusServiceUnavailable)
+			return
+		}
+		defer b.Unsubscribe(sub)
+
+		// The server's write timeout would cut the stream off however healthy
+		// it is: it lasts until the client disconnects, with each event
+		// written within WriteTimeout so a stuck client is dropped. Writers
+		// that can't set deadlines keep the server's.
+		rc := http.NewResponseController(w)
+		rc.SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case e := <-sub.Events():
+				if b.WriteTimeout > 0 {
+					rc.SetWriteDeadline(time.Now().Add(b.WriteTimeout))
+				}
+				if n := sub.TakeSkipped(); n > 0 {
+					writeEvent(w, Event{Name: "skipped", Data: map[string]any{
+						"skipped": n,
+						"message": fmt.Sprintf("skipped %d updates", n),
+					}})
+				}
+				if err := writeEvent(w, e); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+func writeEvent(w io.Writer, e Event) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Name, data)
+	return err
+}
@@ -0,0 +1,24 @@
+package utils
+
+import "math"
+
+// DefaultPrecision is the number of decimals rates and budgets are rounded to
+// in responses
+const DefaultPrecision = 3
+
+var precision = DefaultPrecision
+
+// SetPrecision sets the decimals Round keeps. A negative value keeps full
+// float64 precision.
+func SetPrecision(decimals int) {
+	precision = decimals
+}
+
+// Round rounds v to the configured number of decimals
+func Round(v float64) float64 {
+	if precision < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return v
+	}
+	scale := math.Pow(10, float64(precision))
+	return math.Round(v*scale) / scale
+}
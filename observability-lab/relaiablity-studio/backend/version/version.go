@@ -0,0 +1,12 @@
+// Package version exposes build information injected at compile time, e.g.
+//
+//	go build -ldflags "-X github.com/sarikasharma2428-web/reliability-studio/version.Version=v1.2.0 \
+//	  -X github.com/sarikasharma2428-web/reliability-studio/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/sarikasharma2428-web/reliability-studio/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+var (
+	Version   = "dev"
+	Commit    = "dev"
+	BuildTime = "dev"
+)